@@ -0,0 +1,79 @@
+package blockchain
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"slices"
+)
+
+// Accumulator commits to every block hash on the chain, from genesis up to
+// Height, as the root of a Merkle tree whose leaves are the block hashes in
+// height order. A light client holding only Root can check that a block is
+// part of the chain with a BlockInclusionProof.
+type Accumulator struct {
+	Root   []byte `json:"root"`
+	Height int    `json:"height"`
+}
+
+// blockHashes returns the hash of every block from genesis to the tip, in
+// height order, and the tip's height
+func (bc *Blockchain) blockHashes() ([][]byte, int, error) {
+	var hashes [][]byte
+	tipHeight := -1
+	hash := bc.lastHash()
+	for len(hash) > 0 {
+		block, err := bc.GetBlockByHash(hash)
+		if err != nil {
+			return nil, 0, err
+		}
+		if tipHeight < 0 {
+			tipHeight = block.Height
+		}
+		hashes = append(hashes, block.Hash)
+		hash = block.PrevHash
+	}
+	slices.Reverse(hashes)
+	return hashes, tipHeight, nil
+}
+
+// accumulatorLeaves hashes each block hash into a Merkle leaf, as VerifyProof expects
+func accumulatorLeaves(hashes [][]byte) [][]byte {
+	leaves := make([][]byte, len(hashes))
+	for i, hash := range hashes {
+		leaf := sha256.Sum256(hash)
+		leaves[i] = leaf[:]
+	}
+	return leaves
+}
+
+// AccumulatorRoot returns the block-hash accumulator of the current chain
+func (bc *Blockchain) AccumulatorRoot() (Accumulator, error) {
+	hashes, height, err := bc.blockHashes()
+	if err != nil {
+		return Accumulator{}, fmt.Errorf("failed to read chain: %v", err)
+	}
+	levels := MerkleLevels(accumulatorLeaves(hashes))
+	return Accumulator{Root: levels[len(levels)-1][0], Height: height}, nil
+}
+
+// BlockInclusionProof proves that the block at height is part of the chain,
+// against the accumulator it returns alongside. Check the proof with
+// VerifyBlockInclusion.
+func (bc *Blockchain) BlockInclusionProof(height int) (MerkleProof, Accumulator, error) {
+	hashes, tipHeight, err := bc.blockHashes()
+	if err != nil {
+		return MerkleProof{}, Accumulator{}, fmt.Errorf("failed to read chain: %v", err)
+	}
+	if height < 0 || height >= len(hashes) {
+		return MerkleProof{}, Accumulator{}, fmt.Errorf("no block at height %d", height)
+	}
+	leaves := accumulatorLeaves(hashes)
+	levels := MerkleLevels(leaves)
+	accumulator := Accumulator{Root: levels[len(levels)-1][0], Height: tipHeight}
+	return GenerateProof(leaves, height), accumulator, nil
+}
+
+// VerifyBlockInclusion checks that proof places blockHash under an accumulator root
+func VerifyBlockInclusion(blockHash []byte, proof MerkleProof, root []byte) bool {
+	return VerifyProof(blockHash, proof, root)
+}
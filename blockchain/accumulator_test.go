@@ -0,0 +1,42 @@
+package blockchain
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBlockInclusionProofs(t *testing.T) {
+	chain, _ := newTestChain(t, 4)
+
+	accumulator, err := chain.AccumulatorRoot()
+	if err != nil {
+		t.Fatalf("AccumulatorRoot: %v", err)
+	}
+	if accumulator.Height != 4 {
+		t.Fatalf("expected the accumulator to cover height 4, got %d", accumulator.Height)
+	}
+
+	for height := 0; height <= 4; height++ {
+		proof, proofAccumulator, err := chain.BlockInclusionProof(height)
+		if err != nil {
+			t.Fatalf("BlockInclusionProof(%d): %v", height, err)
+		}
+		if !bytes.Equal(proofAccumulator.Root, accumulator.Root) {
+			t.Fatalf("height %d: proof is against a different root", height)
+		}
+		block, err := chain.GetBlockByHeight(height)
+		if err != nil {
+			t.Fatalf("GetBlockByHeight(%d): %v", height, err)
+		}
+		if !VerifyBlockInclusion(block.Hash, proof, accumulator.Root) {
+			t.Fatalf("height %d: inclusion proof does not verify", height)
+		}
+		if height > 0 && VerifyBlockInclusion(block.PrevHash, proof, accumulator.Root) {
+			t.Fatalf("height %d: proof verified for the wrong block", height)
+		}
+	}
+
+	if _, _, err := chain.BlockInclusionProof(5); err == nil {
+		t.Fatal("expected an error for a height above the tip")
+	}
+}
@@ -8,10 +8,14 @@ import (
 	"encoding/binary"
 	"encoding/gob"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
-	"math/big"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/amanechibana/veritas-chain/identity"
 )
@@ -22,41 +26,151 @@ type Block struct {
 	Hash              []byte   `json:"hash"`
 	PrevHash          []byte   `json:"prev_hash"`
 	Height            int      `json:"height"`
-	CertificateHashes []string `json:"certificate_hashes"` // Hashed certificate IDs
-	Signature         []byte   `json:"signature"`          // Digital signature of the block
-	MerkleRoot        []byte   `json:"merkle_root"`        // Merkle tree of the block
-	UniversityAddress []byte   `json:"university_address"` // University address that created this block
+	CertificateHashes []string `json:"certificate_hashes"`  // Hashed certificate IDs
+	Signature         []byte   `json:"signature"`           // Digital signature of the block
+	MerkleRoot        []byte   `json:"merkle_root"`         // Merkle tree of the block
+	UniversityAddress []byte   `json:"university_address"`  // University address that created this block
+	IssuedAt          []int64  `json:"issued_at,omitempty"` // Per-certificate issuance timestamps, parallel to CertificateHashes
+	Version           int      `json:"version"`             // Block format version, see BlockVersion
+
+	// PublicKey is the signer's key as fixed-width X||Y coordinates. It is not
+	// hashed: UniversityAddress, which is, already commits to it. Blocks created
+	// before keys were recorded leave it empty.
+	PublicKey []byte `json:"public_key,omitempty"`
+
+	// HashAlgorithm names the algorithm CertificateHashes were made with, see
+	// certificateHashSizes; only SHA-256 is accepted so far. Empty means
+	// SHA-256, as for every block created before the algorithm was recorded.
+	// Version 2+ blocks commit to it through CertificatesDigest.
+	HashAlgorithm string `json:"hash_algorithm,omitempty"`
+
+	// SortedCertificates records that CertificateHashes, and so the Merkle
+	// leaves and IssuedAt, are in ascending hash order rather than submission
+	// order. Version 2+ blocks commit to it through CertificatesDigest.
+	SortedCertificates bool `json:"sorted_certificates,omitempty"`
+
+	// Labels are operator annotations, such as a batch name, stored with the
+	// block but neither hashed nor signed: changing them never affects the
+	// block's hash or validity. See SetBlockLabels.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// BlockVersion is the format version of newly created blocks. Version 0 blocks
+// predate domain-separated signing digests and are verified with the legacy digest.
+// Version 2 blocks commit to their certificates through a fixed-size digest, so
+// their hash and signature can be checked from the BlockHeader alone.
+const BlockVersion = 2
+
+// signingDomainTag is prepended to the signing digest input of version 1+ blocks so a
+// block signature can never be reused as a signature over any other hash in the system.
+var signingDomainTag = []byte("veritas-block-v1")
+
+// contentIDDomainTag keeps a block's ContentID distinct from its signing digest
+var contentIDDomainTag = []byte("veritas-block-content-v1")
+
+// HashAlgorithmSHA256 is the certificate hash algorithm a block may record in HashAlgorithm
+const HashAlgorithmSHA256 = "sha256"
+
+// certificateHashSizes maps each certificate hash algorithm to its digest size
+// in bytes. Certificate lookups, proofs and revocations hash IDs with SHA-256,
+// so no other algorithm is accepted until they support it.
+var certificateHashSizes = map[string]int{
+	HashAlgorithmSHA256: sha256.Size,
+}
+
+// timeNow is the clock used to timestamp and validate blocks; tests replace it
+var timeNow = time.Now
+
+// Certificate is a certificate ID submitted for inclusion in a block together
+// with the time it was issued. Bulk imports of historical certificates set
+// IssuedAt to the original issue date; zero means "issued at block time".
+type Certificate struct {
+	ID       string
+	IssuedAt int64
 }
 
 // NewBlock creates a new block with certificate hashes
 func NewBlock(certificateIDs []string, prevHash []byte, height int, signer identity.Signer) *Block {
+	certs := make([]Certificate, len(certificateIDs))
+	for i, id := range certificateIDs {
+		certs[i] = Certificate{ID: id}
+	}
+
+	block, err := NewBlockWithCertificates(certs, prevHash, height, signer)
+	if err != nil {
+		log.Panic(err)
+	}
+	return block
+}
+
+// NewBlockWithCertificates creates a new block recording an issuance timestamp
+// for each certificate. Certificates issued after the block timestamp are rejected.
+func NewBlockWithCertificates(certs []Certificate, prevHash []byte, height int, signer identity.Signer) (*Block, error) {
+	return newBlockWithCertificates(certs, prevHash, height, signer, false)
+}
+
+// NewSortedBlockWithCertificates is NewBlockWithCertificates with the
+// certificates put in ascending hash order, so the Merkle root depends only on
+// which certificates the block holds. The block is flagged SortedCertificates.
+func NewSortedBlockWithCertificates(certs []Certificate, prevHash []byte, height int, signer identity.Signer) (*Block, error) {
+	return newBlockWithCertificates(certs, prevHash, height, signer, true)
+}
+
+func newBlockWithCertificates(certs []Certificate, prevHash []byte, height int, signer identity.Signer, sorted bool) (*Block, error) {
+	timestamp := timeNow().Unix()
+	if sorted {
+		certs = sortCertificatesByHash(certs)
+	}
+
+	certificateIDs := make([]string, len(certs))
+	issuedAt := make([]int64, len(certs))
+	for i, cert := range certs {
+		if err := ValidateCertificateID(cert.ID); err != nil {
+			return nil, err
+		}
+		if cert.IssuedAt < 0 {
+			return nil, fmt.Errorf("certificate %q has negative issue date %d", cert.ID, cert.IssuedAt)
+		}
+		if cert.IssuedAt > timestamp {
+			return nil, fmt.Errorf("certificate %q issued at %d, after block timestamp %d", cert.ID, cert.IssuedAt, timestamp)
+		}
+		certificateIDs[i] = cert.ID
+		issuedAt[i] = cert.IssuedAt
+		if issuedAt[i] == 0 {
+			issuedAt[i] = timestamp
+		}
+	}
+	if len(issuedAt) == 0 {
+		issuedAt = nil
+	}
 
 	block := &Block{
-		Timestamp:         time.Now().Unix(),
-		Hash:              []byte{},
-		PrevHash:          prevHash,
-		Height:            height,
-		CertificateHashes: hashCertificateIDs(certificateIDs),
-		MerkleRoot:        BuildMerkleTree(certificateIDs).Root.Data,
-		UniversityAddress: signer.Address(),
+		Version:            BlockVersion,
+		Timestamp:          timestamp,
+		Hash:               []byte{},
+		PrevHash:           prevHash,
+		Height:             height,
+		CertificateHashes:  hashCertificateIDs(certificateIDs),
+		MerkleRoot:         BuildMerkleTree(certificateIDs).Root.Data,
+		UniversityAddress:  signer.Address(),
+		IssuedAt:           issuedAt,
+		PublicKey:          identity.EncodePublicKey(signer.PublicKey()),
+		SortedCertificates: sorted,
 	}
 
 	// Sign the block with the provided signer
-	err := block.SignWithSigner(signer)
-	if err != nil {
-		log.Panic(err)
+	if err := block.SignWithSigner(signer); err != nil {
+		return nil, err
 	}
 
 	pow := NewProof(block, signer.PublicKey())
-	err = pow.Run()
-
-	if err != nil {
-		log.Panic(err)
+	if err := pow.Run(); err != nil {
+		return nil, err
 	}
 
 	block.Hash = block.CalculateHash()
 
-	return block
+	return block, nil
 }
 
 // Genesis creates the first block in the blockchain
@@ -64,6 +178,23 @@ func Genesis(signer identity.Signer) *Block {
 	return NewBlock([]string{}, []byte{}, 0, signer)
 }
 
+// GenesisWithPayload creates a genesis block recording payload, which is
+// hashed into its Merkle root like the certificates of any other block
+func GenesisWithPayload(payload []string, signer identity.Signer) (*Block, error) {
+	if len(payload) == 0 {
+		return Genesis(signer), nil
+	}
+	certs := make([]Certificate, len(payload))
+	for i, entry := range payload {
+		certs[i] = Certificate{ID: entry}
+	}
+	block, err := NewBlockWithCertificates(certs, []byte{}, 0, signer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid genesis payload: %v", err)
+	}
+	return block, nil
+}
+
 func (b *Block) Serialize() []byte {
 	var buffer bytes.Buffer
 	encoder := gob.NewEncoder(&buffer)
@@ -95,51 +226,256 @@ func hashCertificateIDs(certificateIDs []string) []string {
 	return hashes
 }
 
+// sortCertificatesByHash returns a copy of certs in ascending order of their certificate hashes
+func sortCertificatesByHash(certs []Certificate) []Certificate {
+	sorted := make([]Certificate, len(certs))
+	copy(sorted, certs)
+	hashes := make(map[string]string, len(sorted))
+	for _, cert := range sorted {
+		hashes[cert.ID] = hashCertificateIDs([]string{cert.ID})[0]
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return hashes[sorted[i].ID] < hashes[sorted[j].ID]
+	})
+	return sorted
+}
+
+// certificateIDSeparators are characters used to delimit certificate ID lists
+// in the CLI; an ID containing one would be silently split into several.
+const certificateIDSeparators = ",\r\n"
+
+// ValidateCertificateID rejects empty certificate IDs and IDs containing separator characters
+func ValidateCertificateID(id string) error {
+	if id == "" {
+		return fmt.Errorf("invalid certificate ID %q: must not be empty", id)
+	}
+	if i := strings.IndexAny(id, certificateIDSeparators); i >= 0 {
+		return fmt.Errorf("invalid certificate ID %q: contains separator character %q", id, id[i])
+	}
+	return nil
+}
+
+// ValidateCertificateIDs validates each ID, returning an error naming the first offending one
+func ValidateCertificateIDs(ids []string) error {
+	for _, id := range ids {
+		if err := ValidateCertificateID(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DefaultCertificateIDMaxLength is the longest certificate ID the node accepts
+// from clients by default. StrictCertificateIDCharset is an opt-in charset of
+// letters, digits and dashes; by default any character ValidateCertificateID
+// allows is accepted.
+const (
+	DefaultCertificateIDMaxLength = 128
+	StrictCertificateIDCharset    = "A-Za-z0-9-"
+)
+
+// CertificateIDPolicy restricts the certificate IDs clients may submit, on top
+// of ValidateCertificateID, so that IDs cannot bloat blocks or embed control
+// characters. A nil policy applies ValidateCertificateID alone.
+type CertificateIDPolicy struct {
+	// MaxLength is the longest ID allowed, in bytes; zero means no limit
+	MaxLength int
+	// Charset is the body of a regular expression character class, such as
+	// "A-Za-z0-9-", that every character of an ID must match; empty allows any
+	Charset string
+
+	allowed *regexp.Regexp
+}
+
+// NewCertificateIDPolicy returns a policy allowing IDs of at most maxLength
+// bytes made of characters in charset; see CertificateIDPolicy
+func NewCertificateIDPolicy(maxLength int, charset string) (*CertificateIDPolicy, error) {
+	if maxLength < 0 {
+		return nil, fmt.Errorf("invalid certificate ID max length %d: must not be negative", maxLength)
+	}
+	policy := &CertificateIDPolicy{MaxLength: maxLength, Charset: charset}
+	if charset != "" {
+		allowed, err := regexp.Compile("^[" + charset + "]$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate ID charset %q: %v", charset, err)
+		}
+		policy.allowed = allowed
+	}
+	return policy, nil
+}
+
+// Validate checks id against ValidateCertificateID and the policy
+func (p *CertificateIDPolicy) Validate(id string) error {
+	if err := ValidateCertificateID(id); err != nil {
+		return err
+	}
+	if p == nil {
+		return nil
+	}
+	if p.MaxLength > 0 && len(id) > p.MaxLength {
+		return fmt.Errorf("invalid certificate ID %q: %d bytes long, the maximum is %d", abbreviateID(id), len(id), p.MaxLength)
+	}
+	if p.allowed != nil {
+		for i, r := range id {
+			if r == utf8.RuneError || !p.allowed.MatchString(string(r)) {
+				return fmt.Errorf("invalid certificate ID %q: character %q at byte %d is not in the allowed set [%s]", abbreviateID(id), r, i, p.Charset)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateAll validates each ID, returning an error naming the first offending one
+func (p *CertificateIDPolicy) ValidateAll(ids []string) error {
+	for _, id := range ids {
+		if err := p.Validate(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// abbreviateID shortens an over-long ID for error messages
+func abbreviateID(id string) string {
+	const shown = 32
+	if len(id) <= shown {
+		return id
+	}
+	return id[:shown] + "..."
+}
+
 func BuildMerkleTree(certificateIDs []string) *MerkleTree {
 	return NewMerkleTree(certificateIDs)
 }
 
 // CalculateHash calculates the hash of the block (including signature)
 func (b *Block) CalculateHash() []byte {
-	data := bytes.Join(
-		[][]byte{
-			b.PrevHash,
-			b.HashCertificates(),
-			b.MerkleRoot,
-			ToHex(int64(b.Timestamp)),
-			ToHex(int64(b.Height)),
-			b.Signature,
-		},
-		[]byte{},
-	)
+	return blockHash(b.Version, b.hashedFields(), b.Signature)
+}
+
+// CalculateHashForSigning calculates the hash of the block for signing (excluding signature).
+// Version 1+ blocks prefix the signed data with signingDomainTag and the block version;
+// version 0 blocks use the legacy untagged digest so they still verify.
+func (b *Block) CalculateHashForSigning() []byte {
+	return signingHash(b.Version, b.hashedFields())
+}
+
+// ContentID identifies the block's content independently of its signature. ECDSA
+// signatures are randomized, so signing identical content twice gives two blocks
+// with different Hashes but the same ContentID; use it to deduplicate blocks and
+// Hash to link the signed chain.
+func (b *Block) ContentID() []byte {
+	fields := append([][]byte{contentIDDomainTag, ToHex(int64(b.Version))}, b.hashedFields()...)
+	hash := sha256.Sum256(bytes.Join(fields, []byte{}))
+	return hash[:]
+}
+
+// blockHash hashes the versioned fields followed by the signature
+func blockHash(version int, fields [][]byte, signature []byte) []byte {
+	if version >= 1 {
+		fields = append([][]byte{ToHex(int64(version))}, fields...)
+	}
+	data := bytes.Join(append(fields, signature), []byte{})
 
 	hash := sha256.Sum256(data)
 	return hash[:]
 }
 
-// CalculateHashForSigning calculates the hash of the block for signing (excluding signature)
-func (b *Block) CalculateHashForSigning() []byte {
-	data := bytes.Join(
-		[][]byte{
-			b.PrevHash,
-			b.HashCertificates(),
-			b.MerkleRoot,
-			ToHex(int64(b.Timestamp)),
-			ToHex(int64(b.Height)),
-		},
-		[]byte{},
-	)
+// signingHash hashes the fields covered by the signature, domain-separated for version 1+
+func signingHash(version int, fields [][]byte) []byte {
+	if version >= 1 {
+		fields = append([][]byte{signingDomainTag, ToHex(int64(version))}, fields...)
+	}
+	data := bytes.Join(fields, []byte{})
 
 	hash := sha256.Sum256(data)
 	return hash[:]
 }
 
+// hashedFields returns the block fields covered by both the block hash and the signature.
+// Version 2+ blocks replace the certificate list with CertificatesDigest.
+func (b *Block) hashedFields() [][]byte {
+	if b.Version >= 2 {
+		return headerFields(b.PrevHash, b.CertificatesDigest(), b.MerkleRoot, b.Timestamp, b.Height)
+	}
+	return [][]byte{
+		b.PrevHash,
+		b.HashCertificates(),
+		b.MerkleRoot,
+		ToHex(int64(b.Timestamp)),
+		ToHex(int64(b.Height)),
+		b.HashIssuedAt(),
+	}
+}
+
+// CertificatesDigest commits to the block's certificate hashes and issuance timestamps
+// in 32 bytes. Version 2+ blocks sign this digest instead of the full certificate list.
+// A recorded HashAlgorithm and the SortedCertificates flag are included only when set,
+// so blocks without them keep their digest.
+func (b *Block) CertificatesDigest() []byte {
+	data := append(b.HashCertificates(), b.HashIssuedAt()...)
+	if b.HashAlgorithm != "" {
+		data = append(data, b.HashAlgorithm...)
+	}
+	if b.SortedCertificates {
+		data = append(data, sortedCertificatesTag...)
+	}
+	digest := sha256.Sum256(data)
+	return digest[:]
+}
+
+// sortedCertificatesTag is added to the certificates digest of blocks flagged SortedCertificates
+var sortedCertificatesTag = []byte("sorted")
+
+// CertificateHashAlgorithm returns the algorithm the block's certificate hashes
+// were made with: HashAlgorithm, or SHA-256 when the block records none
+func (b *Block) CertificateHashAlgorithm() string {
+	if b.HashAlgorithm == "" {
+		return HashAlgorithmSHA256
+	}
+	return b.HashAlgorithm
+}
+
+// certificateHashSize returns the digest size in bytes of the block's certificate hash algorithm
+func (b *Block) certificateHashSize() (int, error) {
+	if b.HashAlgorithm != "" && b.Version < 2 {
+		// Only version 2+ blocks commit to HashAlgorithm
+		return 0, fmt.Errorf("version %d blocks cannot record a hash algorithm, got %q", b.Version, b.HashAlgorithm)
+	}
+	algorithm := b.CertificateHashAlgorithm()
+	size, ok := certificateHashSizes[algorithm]
+	if !ok {
+		return 0, fmt.Errorf("unsupported certificate hash algorithm %q", algorithm)
+	}
+	return size, nil
+}
+
+// HashCertificates returns the concatenated certificate hashes, built in a single allocation.
+// It is not cached: Block fields are exported and a stale cache would hide tampering.
 func (b *Block) HashCertificates() []byte {
-	var certHashes [][]byte
+	size := 0
+	for _, certHash := range b.CertificateHashes {
+		size += len(certHash)
+	}
+	if size == 0 {
+		return []byte{}
+	}
+
+	buf := make([]byte, 0, size)
 	for _, certHash := range b.CertificateHashes {
-		certHashes = append(certHashes, []byte(certHash))
+		buf = append(buf, certHash...)
 	}
-	return bytes.Join(certHashes, []byte{})
+	return buf
+}
+
+// HashIssuedAt returns the concatenated issuance timestamps. Blocks created
+// before issuance timestamps were recorded return nil, leaving their hash unchanged.
+func (b *Block) HashIssuedAt() []byte {
+	var buf []byte
+	for _, ts := range b.IssuedAt {
+		buf = append(buf, ToHex(ts)...)
+	}
+	return buf
 }
 
 func ToHex(num int64) []byte {
@@ -164,6 +500,24 @@ func (b *Block) VerifyCertificate(certificateID string) bool {
 	return false
 }
 
+// CertificateIssuedAt returns the issuance timestamp of a certificate in this block.
+// Blocks without recorded issuance timestamps report the block timestamp.
+func (b *Block) CertificateIssuedAt(certificateID string) (int64, bool) {
+	targetHash := sha256.Sum256([]byte(certificateID))
+	targetHashStr := hex.EncodeToString(targetHash[:])
+
+	for i, hash := range b.CertificateHashes {
+		if hash != targetHashStr {
+			continue
+		}
+		if i < len(b.IssuedAt) {
+			return b.IssuedAt[i], true
+		}
+		return b.Timestamp, true
+	}
+	return 0, false
+}
+
 // Sign signs the block with the provided private key
 func (block *Block) Sign(privateKey ecdsa.PrivateKey) error {
 	// 1. Create a hash of the block data (excluding signature)
@@ -176,8 +530,12 @@ func (block *Block) Sign(privateKey ecdsa.PrivateKey) error {
 		return err
 	}
 
-	// 3. Combine r and s into a single signature
-	signature := append(r.Bytes(), s.Bytes()...)
+	// 3. Combine r and s into a single signature, each padded to the curve's
+	// byte size as Verify expects
+	size := (privateKey.Curve.Params().BitSize + 7) / 8
+	signature := make([]byte, 2*size)
+	r.FillBytes(signature[:size])
+	s.FillBytes(signature[size:])
 
 	// 4. Store the signature
 	block.Signature = signature
@@ -196,7 +554,11 @@ func (block *Block) SignWithSigner(signer identity.Signer) error {
 	return nil
 }
 
-// Verify verifies the block's signature using the provided public key
+// Verify verifies the block's signature using the provided public key, on
+// whichever curve it uses; see identity.VerifySignature for the accepted
+// signature widths. Validate is stricter: the public key recorded in a block
+// is decoded as P-256 (see checkSignerKey), so a block signed with a P-384 key
+// verifies here but cannot pass Validate.
 func (b *Block) Verify(publicKey ecdsa.PublicKey) bool {
 	// 1. Check if signature exists
 	if len(b.Signature) == 0 {
@@ -206,22 +568,27 @@ func (b *Block) Verify(publicKey ecdsa.PublicKey) bool {
 	// 2. Create the same hash that was signed
 	blockHash := b.CalculateHashForSigning()
 
-	// 3. Split the signature back into r and s components
-	sigLen := len(b.Signature)
-	if sigLen%2 != 0 {
-		return false // Signature should have even length (r + s)
-	}
-
-	halfLen := sigLen / 2
-	rBytes := b.Signature[:halfLen]
-	sBytes := b.Signature[halfLen:]
-
-	// 4. Convert bytes back to big.Int
-	r := new(big.Int).SetBytes(rBytes)
-	s := new(big.Int).SetBytes(sBytes)
+	// 3. Verify the r||s signature over it
+	return identity.VerifySignature(publicKey, blockHash, b.Signature)
+}
 
-	// 5. Verify the signature
-	return ecdsa.Verify(&publicKey, blockHash, r, s)
+// checkSignerKey verifies that publicKey belongs to address and produced
+// signature over digest. publicKey is decoded with identity.DecodePublicKey,
+// which only accepts P-256 keys, so blocks signed on other curves fail here.
+func checkSignerKey(publicKey, address, digest, signature []byte) error {
+	pub, err := identity.DecodePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	// Blocks signed before addresses used fixed-width key encoding carry the legacy address
+	derived := identity.AddressFromPublicKey(pub)
+	if !bytes.Equal(derived, address) && !bytes.Equal(identity.LegacyAddressFromPublicKey(pub), address) {
+		return fmt.Errorf("university address %s does not match signing key (address %s)", address, derived)
+	}
+	if !identity.VerifySignature(pub, digest, signature) {
+		return fmt.Errorf("invalid block signature for %s", address)
+	}
+	return nil
 }
 
 // GetCertificateCount returns the number of certificates in this block
@@ -231,6 +598,12 @@ func (b *Block) GetCertificateCount() int {
 
 // Validate checks if a block is valid
 func (b *Block) Validate() error {
+	return b.validate(nil)
+}
+
+// validate is Validate, skipping the signature check for a block sigs holds
+// and adding the block once its signature verifies
+func (b *Block) validate(sigs *signatureCache) error {
 	// Check if hash is correct
 	calculatedHash := b.CalculateHash()
 	if !bytes.Equal(b.Hash, calculatedHash) {
@@ -243,15 +616,19 @@ func (b *Block) Validate() error {
 	}
 
 	// Check if timestamp is reasonable (not in the future)
-	currentTime := time.Now().Unix()
+	currentTime := timeNow().Unix()
 	if b.Timestamp > currentTime+3600 { // Allow 1 hour in the future for clock skew
 		return fmt.Errorf("block timestamp is too far in the future: %d", b.Timestamp)
 	}
 
-	// Check if certificate hashes are valid hex strings
+	// Check if certificate hashes are valid hex digests of the block's hash algorithm
+	hashSize, err := b.certificateHashSize()
+	if err != nil {
+		return err
+	}
 	for i, certHash := range b.CertificateHashes {
-		if len(certHash) != 64 { // SHA-256 hex string should be 64 characters
-			return fmt.Errorf("invalid certificate hash at index %d: expected 64 hex chars, got %d", i, len(certHash))
+		if len(certHash) != 2*hashSize {
+			return fmt.Errorf("invalid certificate hash at index %d: expected %d hex chars for %s, got %d", i, 2*hashSize, b.CertificateHashAlgorithm(), len(certHash))
 		}
 		// Try to decode to verify it's valid hex
 		if _, err := hex.DecodeString(certHash); err != nil {
@@ -259,13 +636,59 @@ func (b *Block) Validate() error {
 		}
 	}
 
+	// Check that a block flagged as sorted really lists its certificates in hash order
+	if b.SortedCertificates {
+		if b.Version < 2 {
+			return fmt.Errorf("version %d blocks cannot be flagged as sorted", b.Version)
+		}
+		if !sort.StringsAreSorted(b.CertificateHashes) {
+			return fmt.Errorf("block is flagged as sorted but its certificate hashes are not in ascending order")
+		}
+	}
+
+	// Check that the recorded signing key belongs to the university and signed
+	// the block. The key is not hashed, so a version 2+ block without one
+	// could otherwise have had it stripped to skip this check.
+	if len(b.PublicKey) == 0 && b.Version >= 2 {
+		return fmt.Errorf("version %d block records no public key to verify its signature", b.Version)
+	}
+	if len(b.PublicKey) > 0 && !sigs.has(b) {
+		if err := checkSignerKey(b.PublicKey, b.UniversityAddress, b.CalculateHashForSigning(), b.Signature); err != nil {
+			return err
+		}
+		sigs.add(b)
+	}
+
+	// Check that issuance timestamps, when recorded, do not postdate the block
+	if len(b.IssuedAt) > 0 {
+		if len(b.IssuedAt) != len(b.CertificateHashes) {
+			return fmt.Errorf("issuance timestamp count %d does not match certificate count %d", len(b.IssuedAt), len(b.CertificateHashes))
+		}
+		for i, issuedAt := range b.IssuedAt {
+			if issuedAt > b.Timestamp {
+				return fmt.Errorf("certificate at index %d issued at %d, after block timestamp %d", i, issuedAt, b.Timestamp)
+			}
+		}
+	}
+
 	return nil
 }
 
-// GenerateCertificateProof builds a Merkle proof for a given certID using this block's leaves
-func (b *Block) GenerateCertificateProof(certID string) (MerkleProof, bool) {
-	if len(b.CertificateHashes) == 0 || len(b.MerkleRoot) == 0 {
-		return MerkleProof{}, false
+// Errors returned by GenerateCertificateProof
+var (
+	ErrNoCertificates        = errors.New("block has no certificates")
+	ErrCertificateNotInBlock = errors.New("certificate not in block")
+)
+
+// GenerateCertificateProof builds a Merkle proof for a given certID using this block's leaves.
+// It returns ErrNoCertificates for a block without certificates (whose root is
+// EmptyMerkleRoot) and ErrCertificateNotInBlock when certID is not among them.
+func (b *Block) GenerateCertificateProof(certID string) (MerkleProof, error) {
+	if len(b.CertificateHashes) == 0 {
+		return MerkleProof{}, ErrNoCertificates
+	}
+	if len(b.MerkleRoot) == 0 {
+		return MerkleProof{}, fmt.Errorf("block %d has certificates but no Merkle root", b.Height)
 	}
 	// Build leaves from stored hex hashes (stable order)
 	leaves := make([][]byte, 0, len(b.CertificateHashes))
@@ -277,7 +700,7 @@ func (b *Block) GenerateCertificateProof(certID string) (MerkleProof, bool) {
 	for i, h := range b.CertificateHashes {
 		hb, err := hex.DecodeString(h)
 		if err != nil {
-			return MerkleProof{}, false
+			return MerkleProof{}, fmt.Errorf("invalid certificate hash at index %d: %v", i, err)
 		}
 		leaves = append(leaves, hb)
 		if h == targetHex {
@@ -285,11 +708,30 @@ func (b *Block) GenerateCertificateProof(certID string) (MerkleProof, bool) {
 		}
 	}
 	if idx == -1 {
-		return MerkleProof{}, false
+		return MerkleProof{}, fmt.Errorf("%w: %q", ErrCertificateNotInBlock, certID)
 	}
 
+	// NewMerkleTree pairs a lone certificate with itself, where GenerateProof
+	// would treat it as the root
+	if len(leaves) == 1 {
+		return MerkleProof{Siblings: [][]byte{leaves[0]}, Directions: []bool{true}}, nil
+	}
 	proof := GenerateProof(leaves, idx)
-	return proof, true
+	return proof, nil
+}
+
+// MerkleLevels reconstructs the block's Merkle tree from its certificate
+// hashes, returning each level from the leaves up to the root
+func (b *Block) MerkleLevels() ([][][]byte, error) {
+	leaves := make([][]byte, len(b.CertificateHashes))
+	for i, h := range b.CertificateHashes {
+		hb, err := hex.DecodeString(h)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate hash at index %d: %v", i, err)
+		}
+		leaves[i] = hb
+	}
+	return MerkleLevels(leaves), nil
 }
 
 // VerifyCertificateWithProof verifies a certID against this block's MerkleRoot using a provided proof
@@ -0,0 +1,552 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+func TestHistoricalIssueDateAccepted(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	issued := time.Now().AddDate(-5, 0, 0).Unix()
+
+	block, err := NewBlockWithCertificates([]Certificate{
+		{ID: "CERT-OLD", IssuedAt: issued},
+		{ID: "CERT-NEW"},
+	}, []byte{}, 0, signer)
+	if err != nil {
+		t.Fatalf("historical certificate rejected: %v", err)
+	}
+	if err := block.Validate(); err != nil {
+		t.Fatalf("block with historical certificate failed validation: %v", err)
+	}
+
+	if got, ok := block.CertificateIssuedAt("CERT-OLD"); !ok || got != issued {
+		t.Fatalf("expected CERT-OLD issued at %d, got %d (found=%v)", issued, got, ok)
+	}
+	if got, ok := block.CertificateIssuedAt("CERT-NEW"); !ok || got != block.Timestamp {
+		t.Fatalf("expected CERT-NEW issued at block time %d, got %d (found=%v)", block.Timestamp, got, ok)
+	}
+}
+
+func TestFutureIssueDateRejected(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	issued := time.Now().Add(24 * time.Hour).Unix()
+
+	_, err := NewBlockWithCertificates([]Certificate{{ID: "CERT-FUTURE", IssuedAt: issued}}, []byte{}, 0, signer)
+	if err == nil {
+		t.Fatal("expected certificate issued in the future to be rejected")
+	}
+}
+
+func TestValidateCertificateID(t *testing.T) {
+	tests := []struct {
+		id      string
+		wantErr bool
+	}{
+		{"CERT-001", false},
+		{"CERT,001", true},
+		{"CERT\n001", true},
+		{"CERT\r001", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		err := ValidateCertificateID(tt.id)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateCertificateID(%q) error = %v, wantErr %v", tt.id, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCertificateIDPolicy(t *testing.T) {
+	policy, err := NewCertificateIDPolicy(16, StrictCertificateIDCharset)
+	if err != nil {
+		t.Fatalf("NewCertificateIDPolicy: %v", err)
+	}
+	tests := []struct {
+		id      string
+		wantErr string
+	}{
+		{"CERT-001", ""},
+		{"CERT-0123456789A", ""},
+		{"CERT-0123456789AB", "17 bytes long, the maximum is 16"},
+		{"CERT_001", `character '_' at byte 4`},
+		{"CERT\x00001", `character '\x00' at byte 4`},
+		{"CERT-é01", `character 'é' at byte 5`},
+		{"CERT,001", "separator"},
+	}
+	for _, tt := range tests {
+		err := policy.Validate(tt.id)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("Validate(%q): unexpected error %v", tt.id, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Validate(%q) = %v, want an error containing %q", tt.id, err, tt.wantErr)
+		}
+	}
+
+	// Errors quote a long ID abbreviated
+	err = policy.Validate(strings.Repeat("A", 10000))
+	if err == nil || len(err.Error()) > 200 {
+		t.Errorf("expected a short error for an over-long ID, got %d bytes", len(err.Error()))
+	}
+
+	// A nil policy only applies ValidateCertificateID
+	var none *CertificateIDPolicy
+	if err := none.ValidateAll([]string{"CERT_001", strings.Repeat("A", 1000)}); err != nil {
+		t.Errorf("nil policy rejected an ID: %v", err)
+	}
+
+	if _, err := NewCertificateIDPolicy(0, "A-"); err != nil {
+		t.Errorf("trailing dash should be accepted in a charset: %v", err)
+	}
+	if _, err := NewCertificateIDPolicy(0, "z-a"); err == nil {
+		t.Errorf("expected an invalid charset to be rejected")
+	}
+}
+
+// rawSigningData joins the signed fields the way pre-versioning blocks did
+func rawSigningData(b *Block) []byte {
+	return bytes.Join([][]byte{
+		b.PrevHash,
+		b.HashCertificates(),
+		b.MerkleRoot,
+		ToHex(b.Timestamp),
+		ToHex(int64(b.Height)),
+		b.HashIssuedAt(),
+	}, []byte{})
+}
+
+func TestSigningDigestIsDomainSeparated(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	block := NewBlock([]string{"CERT-001"}, []byte{}, 0, signer)
+
+	if block.Version != BlockVersion {
+		t.Fatalf("expected new block version %d, got %d", BlockVersion, block.Version)
+	}
+	fields := bytes.Join(block.hashedFields(), []byte{})
+	tagged := sha256.Sum256(bytes.Join([][]byte{signingDomainTag, ToHex(int64(block.Version)), fields}, []byte{}))
+	if !bytes.Equal(block.CalculateHashForSigning(), tagged[:]) {
+		t.Fatal("new block signing digest does not carry the domain tag")
+	}
+	legacy := sha256.Sum256(fields)
+	if bytes.Equal(tagged[:], legacy[:]) {
+		t.Fatal("tagged digest equals legacy digest")
+	}
+	if !block.Verify(signer.PublicKey()) {
+		t.Fatal("new block failed to verify")
+	}
+
+	// Downgrading the version switches to the legacy digest, which was never signed
+	block.Version = 0
+	if block.Verify(signer.PublicKey()) {
+		t.Fatal("block verified after its version was downgraded")
+	}
+	if err := block.Validate(); err == nil {
+		t.Fatal("block validated after its version was downgraded")
+	}
+}
+
+func TestContentIDIgnoresSignature(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	block := NewBlock([]string{"CERT-001"}, []byte{}, 0, signer)
+
+	resigned := *block
+	if err := resigned.SignWithSigner(signer); err != nil {
+		t.Fatalf("SignWithSigner: %v", err)
+	}
+	resigned.Hash = resigned.CalculateHash()
+	if err := resigned.Validate(); err != nil {
+		t.Fatalf("re-signed block is invalid: %v", err)
+	}
+
+	if bytes.Equal(block.Hash, resigned.Hash) {
+		t.Fatal("expected two signings to give different block hashes")
+	}
+	if !bytes.Equal(block.ContentID(), resigned.ContentID()) {
+		t.Fatal("expected two signings of the same content to share a ContentID")
+	}
+	if bytes.Equal(block.ContentID(), block.CalculateHashForSigning()) {
+		t.Fatal("ContentID equals the signing digest")
+	}
+
+	resigned.Timestamp++
+	if bytes.Equal(block.ContentID(), resigned.ContentID()) {
+		t.Fatal("expected different content to give a different ContentID")
+	}
+}
+
+func TestLegacyBlockStillVerifies(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	ids := []string{"CERT-001", "CERT-002"}
+
+	// A block as created before versioning: no version, untagged digest
+	block := &Block{
+		Timestamp:         time.Now().Unix(),
+		PrevHash:          []byte{},
+		CertificateHashes: hashCertificateIDs(ids),
+		MerkleRoot:        BuildMerkleTree(ids).Root.Data,
+		UniversityAddress: signer.Address(),
+	}
+	legacy := sha256.Sum256(rawSigningData(block))
+	sig, err := signer.Sign(legacy[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	block.Signature = sig
+	block.Hash = block.CalculateHash()
+
+	if !block.Verify(signer.PublicKey()) {
+		t.Fatal("legacy block failed to verify")
+	}
+	if err := block.Validate(); err != nil {
+		t.Fatalf("legacy block failed validation: %v", err)
+	}
+}
+
+func TestValidateRejectsAddressNotMatchingKey(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	other := identity.NewIdentitySigner(identity.MakeIdentity())
+
+	block := NewBlock([]string{"CERT-001"}, []byte{}, 0, signer)
+	if len(block.PublicKey) != 64 {
+		t.Fatalf("expected a 64-byte public key, got %d bytes", len(block.PublicKey))
+	}
+	if err := block.Validate(); err != nil {
+		t.Fatalf("valid block rejected: %v", err)
+	}
+
+	// Claim another university's address while signing with our own key
+	forged := NewBlock([]string{"CERT-001"}, []byte{}, 0, signer)
+	forged.UniversityAddress = other.Address()
+	if err := forged.SignWithSigner(signer); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	forged.Hash = forged.CalculateHash()
+	if err := forged.Validate(); err == nil || !strings.Contains(err.Error(), "does not match signing key") {
+		t.Fatalf("expected address/key mismatch, got %v", err)
+	}
+	if err := forged.Header().Validate(); err == nil {
+		t.Fatal("header with mismatched address/key validated")
+	}
+
+	// Record the other university's key: the address matches but the signature does not
+	forged.UniversityAddress = other.Address()
+	forged.PublicKey = identity.EncodePublicKey(other.PublicKey())
+	if err := forged.Validate(); err == nil || !strings.Contains(err.Error(), "invalid block signature") {
+		t.Fatalf("expected signature failure, got %v", err)
+	}
+}
+
+// hashCertificatesJoin is the original HashCertificates, kept as a reference
+func hashCertificatesJoin(b *Block) []byte {
+	var certHashes [][]byte
+	for _, certHash := range b.CertificateHashes {
+		certHashes = append(certHashes, []byte(certHash))
+	}
+	return bytes.Join(certHashes, []byte{})
+}
+
+func TestHashCertificatesMatchesJoin(t *testing.T) {
+	for _, hashes := range [][]string{
+		nil,
+		{},
+		{""},
+		{"", ""},
+		hashCertificateIDs([]string{"CERT-001"}),
+		hashCertificateIDs([]string{"CERT-001", "CERT-002", "CERT-003"}),
+		{"short", "", "a-much-longer-entry-than-the-others"},
+	} {
+		block := &Block{CertificateHashes: hashes}
+		if got, want := block.HashCertificates(), hashCertificatesJoin(block); !bytes.Equal(got, want) {
+			t.Errorf("%q: expected %q, got %q", hashes, want, got)
+		}
+	}
+}
+
+func BenchmarkHashCertificates(b *testing.B) {
+	ids := make([]string, 1000)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("CERT-%04d", i)
+	}
+	block := &Block{CertificateHashes: hashCertificateIDs(ids)}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		block.HashCertificates()
+	}
+}
+
+func TestProofForEmptyBlock(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	block := NewBlock([]string{}, []byte{}, 0, signer)
+
+	if !bytes.Equal(block.MerkleRoot, EmptyMerkleRoot()) {
+		t.Fatalf("empty block has Merkle root %x, expected %x", block.MerkleRoot, EmptyMerkleRoot())
+	}
+	if _, err := block.GenerateCertificateProof("CERT-001"); !errors.Is(err, ErrNoCertificates) {
+		t.Fatalf("expected ErrNoCertificates, got %v", err)
+	}
+}
+
+func TestProofForCertificateNotInBlock(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	block := NewBlock([]string{"CERT-001", "CERT-002", "CERT-003"}, []byte{}, 0, signer)
+
+	_, err := block.GenerateCertificateProof("CERT-999")
+	if !errors.Is(err, ErrCertificateNotInBlock) {
+		t.Fatalf("expected ErrCertificateNotInBlock, got %v", err)
+	}
+	if errors.Is(err, ErrNoCertificates) {
+		t.Fatal("a missing certificate must not be reported as an empty block")
+	}
+
+	proof, err := block.GenerateCertificateProof("CERT-002")
+	if err != nil {
+		t.Fatalf("generate proof: %v", err)
+	}
+	if !block.VerifyCertificateWithProof("CERT-002", proof) {
+		t.Fatal("proof for CERT-002 does not verify")
+	}
+}
+
+func TestVerifyP384Signature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate P-384 key: %v", err)
+	}
+	block := NewBlock([]string{"CERT-001"}, []byte{}, 0, identity.NewIdentitySigner(identity.MakeIdentity()))
+	if err := block.Sign(*key); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if len(block.Signature) != 96 {
+		t.Fatalf("expected a 96-byte P-384 signature, got %d bytes", len(block.Signature))
+	}
+	if !block.Verify(key.PublicKey) {
+		t.Fatal("P-384 signature did not verify")
+	}
+
+	p256 := identity.NewIdentitySigner(identity.MakeIdentity())
+	if block.Verify(p256.PublicKey()) {
+		t.Fatal("P-384 signature verified with a P-256 key")
+	}
+
+	signature := block.Signature
+	if _, _, err := identity.ParseCurveSignature(elliptic.P384(), signature[:64]); err == nil || !strings.Contains(err.Error(), "expected 96 bytes") {
+		t.Errorf("P-256 sized: expected a length error, got %v", err)
+	}
+	for name, sig := range map[string][]byte{
+		"P-256 sized": signature[:64],
+		"truncated":   signature[:95],
+		"padded":      append(append([]byte{}, signature...), 0),
+		"empty":       {},
+	} {
+		block.Signature = sig
+		if block.Verify(key.PublicKey) {
+			t.Errorf("%s: mis-sized signature verified", name)
+		}
+	}
+}
+
+func TestVerifyProofRejectsMismatchedLengths(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	block := NewBlock([]string{"CERT-001", "CERT-002", "CERT-003"}, []byte{}, 0, signer)
+	proof, err := block.GenerateCertificateProof("CERT-002")
+	if err != nil {
+		t.Fatalf("generate proof: %v", err)
+	}
+
+	for name, malformed := range map[string]MerkleProof{
+		"missing direction": {Siblings: proof.Siblings, Directions: proof.Directions[:1]},
+		"no directions":     {Siblings: proof.Siblings},
+		"extra direction":   {Siblings: proof.Siblings, Directions: append(append([]bool{}, proof.Directions...), true)},
+	} {
+		if block.VerifyCertificateWithProof("CERT-002", malformed) {
+			t.Fatalf("%s: malformed proof verified", name)
+		}
+	}
+}
+
+// legacyAddressSigner signs like its embedded signer but reports the address
+// derived from the unpadded public key encoding
+type legacyAddressSigner struct {
+	identity.Signer
+}
+
+func (s legacyAddressSigner) Address() []byte {
+	return identity.LegacyAddressFromPublicKey(s.PublicKey())
+}
+
+func TestBlockWithLegacyAddressValidates(t *testing.T) {
+	// The public key of this private scalar has a Y coordinate with a leading zero byte
+	signer, err := identity.NewP256SignerFromHexD("2b")
+	if err != nil {
+		t.Fatalf("signer: %v", err)
+	}
+	legacy := legacyAddressSigner{signer}
+	if bytes.Equal(legacy.Address(), signer.Address()) {
+		t.Fatal("test key should have a legacy address that differs")
+	}
+
+	block := NewBlock([]string{"CERT-001"}, []byte{}, 0, legacy)
+	if len(block.PublicKey) != 64 {
+		t.Fatalf("expected a 64-byte public key, got %d", len(block.PublicKey))
+	}
+	if err := block.Validate(); err != nil {
+		t.Fatalf("block with a legacy address should validate: %v", err)
+	}
+}
+
+func TestValidateCertificateHashAlgorithm(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+
+	// Blocks that record no algorithm carry 64-char SHA-256 hashes
+	block := NewBlock([]string{"CERT-001", "CERT-002"}, []byte{}, 0, signer)
+	if block.CertificateHashAlgorithm() != HashAlgorithmSHA256 {
+		t.Fatalf("expected %s, got %s", HashAlgorithmSHA256, block.CertificateHashAlgorithm())
+	}
+	if err := block.Validate(); err != nil {
+		t.Fatalf("SHA-256 block rejected: %v", err)
+	}
+
+	reseal := func(b *Block) {
+		t.Helper()
+		if err := b.SignWithSigner(signer); err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		b.Hash = b.CalculateHash()
+	}
+
+	// SHA-512 is not accepted: certificates are looked up by their SHA-256 hash
+	sha512Block := NewBlock([]string{"CERT-001", "CERT-002"}, []byte{}, 0, signer)
+	sha512Block.HashAlgorithm = "sha512"
+	for i, id := range []string{"CERT-001", "CERT-002"} {
+		sum := sha512.Sum512([]byte(id))
+		sha512Block.CertificateHashes[i] = hex.EncodeToString(sum[:])
+	}
+	reseal(sha512Block)
+	if err := sha512Block.Validate(); err == nil || !strings.Contains(err.Error(), `unsupported certificate hash algorithm "sha512"`) {
+		t.Fatalf("expected SHA-512 to be unsupported, got %v", err)
+	}
+
+	// SHA-512 hashes in a block that claims SHA-256 are the wrong length
+	mislabeled := *sha512Block
+	mislabeled.HashAlgorithm = ""
+	reseal(&mislabeled)
+	if err := mislabeled.Validate(); err == nil || !strings.Contains(err.Error(), "expected 64 hex chars for sha256") {
+		t.Fatalf("expected a SHA-256 length error, got %v", err)
+	}
+
+	explicit := *block
+	explicit.HashAlgorithm = HashAlgorithmSHA256
+	reseal(&explicit)
+	if err := explicit.Validate(); err != nil {
+		t.Fatalf("block recording sha256 rejected: %v", err)
+	}
+
+	unknown := *block
+	unknown.HashAlgorithm = "md5"
+	reseal(&unknown)
+	if err := unknown.Validate(); err == nil || !strings.Contains(err.Error(), "unsupported certificate hash algorithm") {
+		t.Fatalf("expected an unsupported algorithm error, got %v", err)
+	}
+}
+
+func TestHashAlgorithmIsCommitted(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	block := NewBlock([]string{"CERT-001"}, []byte{}, 0, signer)
+
+	// Changing the recorded algorithm after signing must break the block hash
+	block.HashAlgorithm = HashAlgorithmSHA256
+	if err := block.Validate(); err == nil || !strings.Contains(err.Error(), "invalid block hash") {
+		t.Fatalf("expected a hash mismatch, got %v", err)
+	}
+}
+
+func TestSortedCertificatesGiveOrderIndependentRoot(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	options := testChainOptions
+	options.SortCertificates = true
+
+	batches := [][]string{
+		{"CERT-001", "CERT-002", "CERT-003"},
+		{"CERT-003", "CERT-001", "CERT-002"},
+	}
+	var sortedRoots, unsortedRoots [][]byte
+	for _, batch := range batches {
+		sortedChain := InitBlockchain("", signer, options)
+		block, err := sortedChain.AddBlock(batch, signer)
+		sortedChain.Close()
+		if err != nil {
+			t.Fatalf("add sorted block: %v", err)
+		}
+		if !block.SortedCertificates {
+			t.Fatal("expected the block to be flagged as sorted")
+		}
+		if err := block.Validate(); err != nil {
+			t.Fatalf("sorted block rejected: %v", err)
+		}
+		sortedRoots = append(sortedRoots, block.MerkleRoot)
+
+		unsorted := NewBlock(batch, []byte{}, 0, signer)
+		unsortedRoots = append(unsortedRoots, unsorted.MerkleRoot)
+	}
+
+	if !bytes.Equal(sortedRoots[0], sortedRoots[1]) {
+		t.Fatalf("expected identical roots for reordered batches, got %x and %x", sortedRoots[0], sortedRoots[1])
+	}
+	if bytes.Equal(unsortedRoots[0], unsortedRoots[1]) {
+		t.Fatal("expected reordered batches to give different roots without sorting")
+	}
+}
+
+func TestSortedFlagIsChecked(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	block, err := NewSortedBlockWithCertificates([]Certificate{{ID: "CERT-001"}, {ID: "CERT-002"}, {ID: "CERT-003"}}, []byte{}, 0, signer)
+	if err != nil {
+		t.Fatalf("new block: %v", err)
+	}
+	for i, id := range []string{"CERT-001", "CERT-002", "CERT-003"} {
+		proof, err := block.GenerateCertificateProof(id)
+		if err != nil {
+			t.Fatalf("proof %d: %v", i, err)
+		}
+		if !block.VerifyCertificateWithProof(id, proof) {
+			t.Fatalf("proof for %s failed against the sorted root", id)
+		}
+	}
+
+	// Clearing the flag after signing breaks the block hash
+	cleared := *block
+	cleared.SortedCertificates = false
+	if err := cleared.Validate(); err == nil || !strings.Contains(err.Error(), "invalid block hash") {
+		t.Fatalf("expected a hash mismatch, got %v", err)
+	}
+
+	// A block claiming to be sorted must list its hashes in order
+	unsorted := NewBlock([]string{"CERT-001", "CERT-002", "CERT-003"}, []byte{}, 0, signer)
+	if sort.StringsAreSorted(unsorted.CertificateHashes) {
+		t.Skip("test certificates happen to hash in ascending order")
+	}
+	unsorted.SortedCertificates = true
+	if err := unsorted.SignWithSigner(signer); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	unsorted.Hash = unsorted.CalculateHash()
+	if err := unsorted.Validate(); err == nil || !strings.Contains(err.Error(), "not in ascending order") {
+		t.Fatalf("expected an ordering error, got %v", err)
+	}
+}
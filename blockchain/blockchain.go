@@ -2,13 +2,21 @@ package blockchain
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/amanechibana/veritas-chain/identity"
+	"github.com/amanechibana/veritas-chain/metrics"
 	"github.com/dgraph-io/badger/v4"
 )
 
@@ -16,11 +24,40 @@ import (
 type Blockchain struct {
 	LastHash []byte
 	Database *badger.DB
+
+	// mu serializes writers and guards LastHash so concurrent AddBlock calls
+	// cannot fork the chain.
+	mu sync.RWMutex
+
+	// cache holds recently read blocks; diskReads counts block loads that missed it.
+	cache     *blockCache
+	diskReads atomic.Int64
+
+	options BlockchainOptions
+
+	// sigs holds the hashes of blocks whose signatures validation has verified
+	sigs *signatureCache
+
+	// stopSweeper stops the block cache sweeper started when the cache has a TTL
+	stopSweeper func()
+
+	// filter is the certificate Bloom filter, nil until CertificateExists
+	// first needs it. filterMu is taken after mu.
+	filterMu sync.Mutex
+	filter   *bloomFilter
+
+	// closeOnce makes Close idempotent
+	closeOnce sync.Once
+
+	// removeOnClose is the temporary directory of a staging chain, removed by Close
+	removeOnClose string
 }
 
 type BlockchainIterator struct {
 	CurrentHash []byte
 	Database    *badger.DB
+
+	chain *Blockchain
 }
 
 type BlockchainStats struct {
@@ -28,6 +65,45 @@ type BlockchainStats struct {
 	CertificateCount int
 }
 
+// ChainInfo describes the span of the chain: its height and when its first
+// and latest blocks were created. Timestamps are Unix seconds.
+type ChainInfo struct {
+	Height           int   `json:"height"`
+	BlockCount       int   `json:"block_count"`
+	GenesisTimestamp int64 `json:"genesis_timestamp"`
+	TipTimestamp     int64 `json:"tip_timestamp"`
+	// AgeSeconds is how long ago the genesis block was created
+	AgeSeconds int64 `json:"age_seconds"`
+}
+
+// heightKey returns the index key mapping a block height to its block hash
+func heightKey(height int) []byte {
+	return append([]byte("h-"), ToHex(int64(height))...)
+}
+
+var (
+	validateChainDuration = metrics.NewHistogram("veritas_validate_chain_duration_seconds",
+		"Time taken to validate the full blockchain.", metrics.DefaultBuckets)
+	getStatsDuration = metrics.NewHistogram("veritas_get_stats_duration_seconds",
+		"Time taken to compute blockchain statistics.", metrics.DefaultBuckets)
+)
+
+// newBlockchain wraps an open database whose tip is lastHash
+func newBlockchain(lastHash []byte, db *badger.DB, options BlockchainOptions) *Blockchain {
+	bc := &Blockchain{
+		LastHash: lastHash,
+		Database: db,
+		cache:    newBlockCache(options.blockCacheSize()),
+		sigs:     newSignatureCache(),
+		options:  options,
+	}
+	if options.BlockCacheTTL > 0 {
+		bc.cache.setTTL(options.BlockCacheTTL)
+		bc.stopSweeper = bc.cache.startSweeper(options.BlockCacheTTL)
+	}
+	return bc
+}
+
 // DBExists checks for Badger MANIFEST to determine if DB exists at given path
 func DBExists(dbPath string) bool {
 	manifest := filepath.Join(dbPath, "MANIFEST")
@@ -37,19 +113,15 @@ func DBExists(dbPath string) bool {
 	return true
 }
 
-func ContinueBlockchain(dbPath string) *Blockchain {
-	if !DBExists(dbPath) {
+func ContinueBlockchain(dbPath string, options BlockchainOptions) *Blockchain {
+	if options.InMemory || !DBExists(dbPath) {
 		fmt.Println("No blockchain found")
 		runtime.Goexit()
 	}
 
 	var lastHash []byte
 
-	opts := badger.DefaultOptions(dbPath)
-	opts.Dir = dbPath
-	opts.ValueDir = dbPath
-
-	db, err := badger.Open(opts)
+	db, err := badger.Open(options.badgerOptions(dbPath))
 	if err != nil {
 		log.Panic(err)
 	}
@@ -69,21 +141,20 @@ func ContinueBlockchain(dbPath string) *Blockchain {
 		log.Panic(err)
 	}
 
-	chain := Blockchain{lastHash, db}
-	return &chain
+	return newBlockchain(lastHash, db, options)
 }
 
-func InitBlockchain(dbPath string, signer identity.Signer) *Blockchain {
-	opts := badger.DefaultOptions(dbPath)
-	opts.Dir = dbPath
-	opts.ValueDir = dbPath
+func InitBlockchain(dbPath string, signer identity.Signer, options BlockchainOptions) *Blockchain {
+	opts := options.badgerOptions(dbPath)
 
 	// Check for an existing chain before opening: badger.Open writes the
 	// MANIFEST, after which DBExists always returns true.
-	chainExists := DBExists(dbPath)
+	chainExists := !options.InMemory && DBExists(dbPath)
 
 	// Ensure directory exists
-	_ = os.MkdirAll(dbPath, 0o755)
+	if !options.InMemory {
+		_ = os.MkdirAll(dbPath, 0o755)
+	}
 
 	db, err := badger.Open(opts)
 	if err != nil {
@@ -116,18 +187,24 @@ func InitBlockchain(dbPath string, signer identity.Signer) *Blockchain {
 			}
 		} else {
 			fmt.Println("Loaded existing blockchain")
-			return &Blockchain{lastHash, db}
+			return newBlockchain(lastHash, db, options)
 		}
 	}
 
 	// Create new blockchain with genesis block
 	var lastHash []byte
 	err = db.Update(func(txn *badger.Txn) error {
-		genesis := Genesis(signer)
+		genesis, err := GenesisWithPayload(options.GenesisPayload, signer)
+		if err != nil {
+			return err
+		}
 		encodedBlock := genesis.Serialize()
 		if err := txn.Set(genesis.Hash, encodedBlock); err != nil {
 			return err
 		}
+		if err := txn.Set(heightKey(genesis.Height), genesis.Hash); err != nil {
+			return err
+		}
 		if err := txn.Set([]byte("lh"), genesis.Hash); err != nil {
 			return err
 		}
@@ -140,10 +217,25 @@ func InitBlockchain(dbPath string, signer identity.Signer) *Blockchain {
 	}
 
 	fmt.Println("Created new blockchain with genesis block")
-	return &Blockchain{lastHash, db}
+	return newBlockchain(lastHash, db, options)
 }
 
 func (chain *Blockchain) AddBlock(certificateIDs []string, signer identity.Signer) (*Block, error) {
+	certs := make([]Certificate, len(certificateIDs))
+	for i, id := range certificateIDs {
+		certs[i] = Certificate{ID: id}
+	}
+	return chain.AddCertificates(certs, signer)
+}
+
+// ErrBlockTooSoon is returned by AddBlock when MinBlockInterval has not passed since the tip
+var ErrBlockTooSoon = errors.New("minimum block interval has not elapsed")
+
+// AddCertificates adds a block whose certificates carry their own issuance timestamps
+func (chain *Blockchain) AddCertificates(certs []Certificate, signer identity.Signer) (*Block, error) {
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+
 	var lastHash []byte
 	var prevBlock *Block
 
@@ -173,55 +265,172 @@ func (chain *Blockchain) AddBlock(certificateIDs []string, signer identity.Signe
 		return nil, err
 	}
 
+	if interval := chain.options.MinBlockInterval; interval > 0 {
+		next := time.Unix(prevBlock.Timestamp, 0).Add(interval)
+		if now := timeNow(); now.Before(next) {
+			return nil, fmt.Errorf("%w: next block allowed in %s", ErrBlockTooSoon, next.Sub(now).Round(time.Second))
+		}
+	}
+
 	// Calculate height: previous block height + 1
 	newHeight := prevBlock.Height + 1
-	newBlock := NewBlock(certificateIDs, lastHash, newHeight, signer)
+	if len(chain.options.Validators) > 0 {
+		leader, err := LeaderForHeight(newHeight, chain.options.Validators)
+		if err != nil {
+			return nil, err
+		}
+		if leader != string(signer.Address()) {
+			return nil, fmt.Errorf("%w: %s leads height %d", ErrNotLeader, leader, newHeight)
+		}
+	}
+	newBlock, err := newBlockWithCertificates(certs, lastHash, newHeight, signer, chain.options.SortCertificates)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := chain.appendBlock(newBlock); err != nil {
+		return nil, err
+	}
+	return newBlock, nil
+}
 
-	err = chain.Database.Update(func(txn *badger.Txn) error {
-		if err := txn.Set(newBlock.Hash, newBlock.Serialize()); err != nil {
+// appendBlock stores block as the new tip, indexing it by height. Callers hold chain.mu.
+func (chain *Blockchain) appendBlock(block *Block) error {
+	err := chain.Database.Update(func(txn *badger.Txn) error {
+		if err := txn.Set(block.Hash, block.Serialize()); err != nil {
 			return err
 		}
-		if err := txn.Set([]byte("lh"), newBlock.Hash); err != nil {
+		if err := txn.Set(heightKey(block.Height), block.Hash); err != nil {
 			return err
 		}
-		chain.LastHash = newBlock.Hash
-		return nil
+		return txn.Set([]byte("lh"), block.Hash)
 	})
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return newBlock, nil
+	chain.LastHash = block.Hash
+	chain.cache.add(block)
+	chain.addToCertificateFilter(block)
+	return nil
 }
 
 // ValidateChain checks if the entire blockchain is valid
 func (bc *Blockchain) ValidateChain() error {
+	defer validateChainDuration.ObserveDuration(time.Now())
+	return bc.validateChain(nil, 0)
+}
+
+// ValidateChainWindow is ValidateChain holding at most window blocks in
+// memory at a time, at the cost of reading each block from disk twice. Use it
+// for chains too long to load at once; window must be positive.
+func (bc *Blockchain) ValidateChainWindow(window int) error {
+	if window <= 0 {
+		return fmt.Errorf("invalid validation window %d: must be positive", window)
+	}
+	defer validateChainDuration.ObserveDuration(time.Now())
+	return bc.validateChain(nil, window)
+}
+
+// FullyValidate is ValidateChain that also requires every block, genesis
+// included, to record its signer's public key, to carry a valid signature by
+// that key and to be signed by an address in signers. The genesis block may
+// instead be signed by the configured GenesisSigner. It returns the first
+// failure, naming the block's height.
+func (bc *Blockchain) FullyValidate(signers identity.AuthorizedSigners) error {
+	if len(signers) == 0 {
+		return fmt.Errorf("no authorized signers to validate against")
+	}
+	return bc.validateChain(func(block *Block) error {
+		return bc.CheckBlockSigner(block, signers)
+	}, 0)
+}
+
+// CheckBlockSigner verifies block's signature with its recorded public key
+// and checks that the key's address is one of signers, or for the genesis
+// block the configured GenesisSigner
+func (bc *Blockchain) CheckBlockSigner(block *Block, signers identity.AuthorizedSigners) error {
+	if block.Height == 0 {
+		return checkGenesisSigner(block, signers, bc.options.GenesisSigner, bc.sigs)
+	}
+	return checkAuthorizedSigner(block, signers, bc.sigs)
+}
+
+// checkAuthorizedSigner verifies block's signature with its recorded public key,
+// unless sigs holds the block, and that the key's address is an authorized signer
+func checkAuthorizedSigner(block *Block, signers identity.AuthorizedSigners, sigs *signatureCache) error {
+	if err := checkRecordedSignature(block, sigs); err != nil {
+		return err
+	}
+	if _, ok := signers.IsAuthorized(string(block.UniversityAddress)); !ok {
+		return fmt.Errorf("block %d was signed by %s, which is not an authorized signer", block.Height, block.UniversityAddress)
+	}
+	return nil
+}
+
+// checkGenesisSigner is checkAuthorizedSigner for the genesis block, which
+// may also be signed by genesisSigner when it is set
+func checkGenesisSigner(genesis *Block, signers identity.AuthorizedSigners, genesisSigner string, sigs *signatureCache) error {
+	if err := checkRecordedSignature(genesis, sigs); err != nil {
+		return err
+	}
+	address := string(genesis.UniversityAddress)
+	if genesisSigner != "" && address == genesisSigner {
+		return nil
+	}
+	if _, ok := signers.IsAuthorized(address); !ok {
+		return fmt.Errorf("genesis block was signed by %s, which is not an authorized signer or the configured genesis signer", address)
+	}
+	return nil
+}
+
+// checkRecordedSignature verifies block's signature with its recorded public
+// key, unless sigs holds the block
+func checkRecordedSignature(block *Block, sigs *signatureCache) error {
+	if len(block.PublicKey) == 0 {
+		return fmt.Errorf("block %d records no public key to verify its signature", block.Height)
+	}
+	publicKey, err := identity.DecodePublicKey(block.PublicKey)
+	if err != nil {
+		return fmt.Errorf("block %d: %v", block.Height, err)
+	}
+	if !sigs.has(block) {
+		if !block.Verify(publicKey) {
+			return fmt.Errorf("block %d has an invalid signature", block.Height)
+		}
+		sigs.add(block)
+	}
+	return nil
+}
+
+// validateChain runs ValidateChain's checks, calling check, when non-nil, on
+// each block, oldest first, after its structural checks pass. window bounds
+// how many blocks are held in memory at once; zero or less holds the whole
+// chain. The checks, and so the result, do not depend on window.
+func (bc *Blockchain) validateChain(check func(*Block) error, window int) error {
 	// Check if blockchain is empty
-	if len(bc.LastHash) == 0 {
+	tipHash := bc.lastHash()
+	if len(tipHash) == 0 {
 		return fmt.Errorf("blockchain is empty")
 	}
 
-	// Load all blocks into memory for validation (we need to validate in order)
-	var blocks []*Block
-	currentHash := append([]byte{}, bc.LastHash...)
-
-	// Walk backwards from last block to genesis
+	// Walk backwards from the tip to genesis to find the order to validate in.
+	// Blocks are read straight from the database, bypassing the cache, so
+	// validation always reflects what is on disk. With a window only the
+	// hashes are kept, and blocks are read again a window at a time below.
+	var (
+		hashes [][]byte
+		blocks []*Block
+	)
+	currentHash := append([]byte{}, tipHash...)
 	for {
-		var data []byte
-		err := bc.Database.View(func(txn *badger.Txn) error {
-			item, err := txn.Get(currentHash)
-			if err != nil {
-				return err
-			}
-			return item.Value(func(val []byte) error {
-				data = append([]byte{}, val...)
-				return nil
-			})
-		})
+		block, err := bc.readBlockFromDisk(currentHash)
 		if err != nil {
-			return fmt.Errorf("failed to load block: %v", err)
+			return err
+		}
+		hashes = append(hashes, currentHash)
+		if window <= 0 {
+			blocks = append(blocks, block)
 		}
-		block := Deserialize(data)
-		blocks = append(blocks, block)
 		if len(block.PrevHash) == 0 { // reached genesis
 			break
 		}
@@ -229,69 +438,240 @@ func (bc *Blockchain) ValidateChain() error {
 	}
 
 	// Reverse to get oldest->newest order
-	for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
-		blocks[i], blocks[j] = blocks[j], blocks[i]
+	slices.Reverse(hashes)
+	slices.Reverse(blocks)
+	if window <= 0 {
+		window = len(hashes)
 	}
 
-	// Validate genesis block
-	genesis := blocks[0]
+	var prev *Block
+	for start := 0; start < len(hashes); start += window {
+		chunk := blocks
+		if chunk == nil {
+			end := min(start+window, len(hashes))
+			chunk = make([]*Block, 0, end-start)
+			for _, hash := range hashes[start:end] {
+				block, err := bc.readBlockFromDisk(hash)
+				if err != nil {
+					return err
+				}
+				chunk = append(chunk, block)
+			}
+		}
+
+		for j, block := range chunk {
+			i := start + j
+			if i == 0 {
+				if err := validateGenesis(block, bc.sigs); err != nil {
+					return err
+				}
+			} else if err := validateSuccessor(block, prev, i, bc.sigs); err != nil {
+				return err
+			}
+			if check != nil {
+				if err := check(block); err != nil {
+					return err
+				}
+			}
+			prev = block
+		}
+	}
+
+	// Check if LastHash matches the last block
+	if !bytes.Equal(tipHash, prev.Hash) {
+		return fmt.Errorf("LastHash mismatch: expected %x, got %x", prev.Hash, tipHash)
+	}
+
+	return nil
+}
+
+// ValidateFromCheckpoint is ValidateChain for a client that already trusts
+// cp: it confirms that the block at cp's height, reached by following
+// PrevHash from the tip, is stored intact under cp's hash, then validates only
+// the blocks above it. Blocks at or below the checkpoint are not checked.
+func (bc *Blockchain) ValidateFromCheckpoint(cp Checkpoint) error {
+	tipHash := bc.lastHash()
+	if len(tipHash) == 0 {
+		return fmt.Errorf("blockchain is empty")
+	}
+	if cp.Height < 0 {
+		return fmt.Errorf("invalid checkpoint height %d", cp.Height)
+	}
+
+	// Walk back from the tip to the checkpoint's height, reading from disk as
+	// validateChain does; above holds the blocks to validate, newest first
+	var above []*Block
+	block, err := bc.readBlockFromDisk(tipHash)
+	if err != nil {
+		return err
+	}
+	if block.Height < cp.Height {
+		return fmt.Errorf("checkpoint at height %d is above the chain tip at height %d", cp.Height, block.Height)
+	}
+	for block.Height > cp.Height {
+		above = append(above, block)
+		if len(block.PrevHash) == 0 {
+			return fmt.Errorf("block %d has no previous block", block.Height)
+		}
+		if block, err = bc.readBlockFromDisk(block.PrevHash); err != nil {
+			return err
+		}
+	}
+	if block.Height != cp.Height {
+		return fmt.Errorf("chain has no block at checkpoint height %d: found height %d", cp.Height, block.Height)
+	}
+	if !bytes.Equal(block.Hash, cp.Hash) {
+		return fmt.Errorf("checkpoint mismatch at height %d: expected %x, chain has %x", cp.Height, cp.Hash, block.Hash)
+	}
+	if calculated := block.CalculateHash(); !bytes.Equal(calculated, cp.Hash) {
+		return fmt.Errorf("checkpoint block %d is corrupted: its contents hash to %x", cp.Height, calculated)
+	}
+
+	prev := block
+	for i := len(above) - 1; i >= 0; i-- {
+		if err := validateSuccessor(above[i], prev, prev.Height+1, bc.sigs); err != nil {
+			return err
+		}
+		prev = above[i]
+	}
+	return nil
+}
+
+// ValidateRange applies ValidateChain's per-block and linkage checks to the
+// blocks from fromHeight to toHeight inclusive, found via the height index. The
+// block before fromHeight is loaded to check that the window links to it, but
+// is not itself validated.
+func (bc *Blockchain) ValidateRange(fromHeight, toHeight int) error {
+	if fromHeight < 0 || toHeight < fromHeight {
+		return fmt.Errorf("invalid validation range %d-%d", fromHeight, toHeight)
+	}
+	tip, err := bc.Tip()
+	if err != nil {
+		return err
+	}
+	if toHeight > tip.Height {
+		return fmt.Errorf("validation range %d-%d extends past the chain tip at height %d", fromHeight, toHeight, tip.Height)
+	}
+
+	var prev *Block
+	if fromHeight == 0 {
+		if prev, err = bc.readHeightFromDisk(0); err != nil {
+			return err
+		}
+		if err := validateGenesis(prev, bc.sigs); err != nil {
+			return err
+		}
+		fromHeight = 1
+	} else if prev, err = bc.readHeightFromDisk(fromHeight - 1); err != nil {
+		return err
+	}
+
+	for height := fromHeight; height <= toHeight; height++ {
+		block, err := bc.readHeightFromDisk(height)
+		if err != nil {
+			return err
+		}
+		if err := validateSuccessor(block, prev, height, bc.sigs); err != nil {
+			return err
+		}
+		prev = block
+	}
+	return nil
+}
+
+// validateGenesis checks the first block of a chain. sigs, which may be nil,
+// caches verified signatures.
+func validateGenesis(genesis *Block, sigs *signatureCache) error {
 	if genesis.Height != 0 {
 		return fmt.Errorf("first block must be genesis block with height 0, got %d", genesis.Height)
 	}
 	if len(genesis.PrevHash) != 0 {
 		return fmt.Errorf("genesis block should have empty PrevHash")
 	}
-	if err := genesis.Validate(); err != nil {
+	if err := genesis.validate(sigs); err != nil {
 		return fmt.Errorf("genesis block validation failed: %v", err)
 	}
+	return nil
+}
 
-	// Validate all other blocks
-	for i := 1; i < len(blocks); i++ {
-		block := blocks[i]
-		prevBlock := blocks[i-1]
+// validateSuccessor checks block, expected at height, and its link to prevBlock
+func validateSuccessor(block, prevBlock *Block, height int, sigs *signatureCache) error {
+	// Validate individual block
+	if err := block.validate(sigs); err != nil {
+		return fmt.Errorf("block %d validation failed: %v", height, err)
+	}
 
-		// Validate individual block
-		if err := block.Validate(); err != nil {
-			return fmt.Errorf("block %d validation failed: %v", i, err)
-		}
+	// Check height sequence
+	if block.Height != height {
+		return fmt.Errorf("block %d has incorrect height: expected %d, got %d", height, height, block.Height)
+	}
 
-		// Check height sequence
-		if block.Height != i {
-			return fmt.Errorf("block %d has incorrect height: expected %d, got %d", i, i, block.Height)
-		}
+	// Check previous hash linking
+	if !bytes.Equal(block.PrevHash, prevBlock.Hash) {
+		return fmt.Errorf("block %d has incorrect PrevHash: expected %x, got %x",
+			height, prevBlock.Hash, block.PrevHash)
+	}
 
-		// Check previous hash linking
-		if !bytes.Equal(block.PrevHash, prevBlock.Hash) {
-			return fmt.Errorf("block %d has incorrect PrevHash: expected %x, got %x",
-				i, prevBlock.Hash, block.PrevHash)
-		}
+	// Check timestamp ordering (blocks should be in chronological order)
+	if block.Timestamp < prevBlock.Timestamp {
+		return fmt.Errorf("block %d timestamp (%d) is before previous block timestamp (%d)",
+			height, block.Timestamp, prevBlock.Timestamp)
+	}
+	return nil
+}
 
-		// Check timestamp ordering (blocks should be in chronological order)
-		if block.Timestamp < prevBlock.Timestamp {
-			return fmt.Errorf("block %d timestamp (%d) is before previous block timestamp (%d)",
-				i, block.Timestamp, prevBlock.Timestamp)
+// readBlockFromDisk loads the block stored under hash, bypassing the cache
+func (bc *Blockchain) readBlockFromDisk(hash []byte) (*Block, error) {
+	var data []byte
+	err := bc.Database.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(hash)
+		if err != nil {
+			return err
 		}
+		data, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load block: %v", err)
 	}
+	return Deserialize(data), nil
+}
 
-	// Check if LastHash matches the last block
-	lastBlock := blocks[len(blocks)-1]
-	if !bytes.Equal(bc.LastHash, lastBlock.Hash) {
-		return fmt.Errorf("LastHash mismatch: expected %x, got %x", lastBlock.Hash, bc.LastHash)
+// readHeightFromDisk loads the block the height index points at, bypassing the cache
+func (bc *Blockchain) readHeightFromDisk(height int) (*Block, error) {
+	var hash []byte
+	err := bc.Database.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(heightKey(height))
+		if err != nil {
+			return err
+		}
+		hash, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("no block indexed at height %d: %v", height, err)
 	}
-
-	return nil
+	return bc.readBlockFromDisk(hash)
 }
 
+// GetStats counts the chain's blocks, genesis included, and the distinct
+// certificates they record, see countNewCertificates
 func (chain *Blockchain) GetStats() BlockchainStats {
+	defer getStatsDuration.ObserveDuration(time.Now())
+
 	var blockCount int
 	var certificateCount int
+	if len(chain.lastHash()) == 0 {
+		return BlockchainStats{}
+	}
 
 	// Count blocks and certificates by iterating through the chain
+	seen := make(map[string]bool)
 	iter := chain.Iterator()
 	for {
 		block := iter.Next()
 		blockCount++
-		certificateCount += len(block.CertificateHashes)
+		certificateCount += countNewCertificates(block, seen)
 
 		// Stop when we reach the genesis block (PrevHash is empty)
 		if len(block.PrevHash) == 0 {
@@ -305,16 +685,516 @@ func (chain *Blockchain) GetStats() BlockchainStats {
 	}
 }
 
+// countNewCertificates records block's certificates in seen and returns how
+// many of them were not already there, so a certificate listed twice in one
+// block or recorded again in a later block counts once. A genesis block's
+// entries are its GenesisPayload rather than certificates and are not counted.
+func countNewCertificates(block *Block, seen map[string]bool) int {
+	if len(block.PrevHash) == 0 {
+		return 0
+	}
+	count := 0
+	for _, certHash := range block.CertificateHashes {
+		if !seen[certHash] {
+			seen[certHash] = true
+			count++
+		}
+	}
+	return count
+}
+
+// GetStatsByUniversity returns block and certificate counts keyed by the
+// UniversityAddress of the blocks' creators. Certificates are counted as in
+// GetStats, once per university that recorded them.
+func (chain *Blockchain) GetStatsByUniversity() (map[string]BlockchainStats, error) {
+	stats := make(map[string]BlockchainStats)
+	seen := make(map[string]map[string]bool)
+	for hash := chain.lastHash(); len(hash) > 0; {
+		block, err := chain.GetBlockByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		address := string(block.UniversityAddress)
+		s := stats[address]
+		s.BlockCount++
+		if seen[address] == nil {
+			seen[address] = make(map[string]bool)
+		}
+		s.CertificateCount += countNewCertificates(block, seen[address])
+		stats[address] = s
+		hash = block.PrevHash
+	}
+	return stats, nil
+}
+
+// GetBlockByHash loads the block stored under the given hash, serving recently
+// used blocks from the in-memory cache. Returned blocks are shared and must not be modified.
+func (bc *Blockchain) GetBlockByHash(hash []byte) (*Block, error) {
+	if block, ok := bc.cache.get(hash); ok {
+		return block, nil
+	}
+
+	var block *Block
+	bc.diskReads.Add(1)
+	err := bc.Database.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(hash)
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			block = Deserialize(val)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load block %x: %v", hash, err)
+	}
+	bc.cache.add(block)
+	return block, nil
+}
+
+// SetBlockCacheSize changes how many recently used blocks are kept in memory; zero disables caching
+func (bc *Blockchain) SetBlockCacheSize(size int) {
+	bc.cache.resize(size)
+}
+
+// GetBlockByHeight loads the block at the given height using the height index.
+// A height missing from the index, as on chains older than the index or after
+// a partial rebuild, is found by walking back from the tip and backfilled.
+func (bc *Blockchain) GetBlockByHeight(height int) (*Block, error) {
+	if height < 0 {
+		return nil, fmt.Errorf("invalid block height: %d", height)
+	}
+	var hash []byte
+	err := bc.Database.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(heightKey(height))
+		if err != nil {
+			return err
+		}
+		hash, err = item.ValueCopy(nil)
+		return err
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return bc.scanForHeight(height)
+	} else if err != nil {
+		return nil, fmt.Errorf("no block indexed at height %d: %v", height, err)
+	}
+	return bc.GetBlockByHash(hash)
+}
+
+// scanForHeight finds the block at height by walking back from the stored tip
+// and backfills its height index entry. It reads the tip from the database
+// rather than bc.LastHash so callers holding bc.mu can use it.
+func (bc *Blockchain) scanForHeight(height int) (*Block, error) {
+	var hash []byte
+	err := bc.Database.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte("lh"))
+		if err != nil {
+			return err
+		}
+		hash, err = item.ValueCopy(nil)
+		return err
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, fmt.Errorf("no block at height %d: the chain is empty", height)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read chain tip: %v", err)
+	}
+
+	for len(hash) > 0 {
+		block, err := bc.GetBlockByHash(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan chain for height %d: %v", height, err)
+		}
+		if block.Height < height {
+			break
+		}
+		if block.Height == height {
+			log.Printf("height %d missing from the height index; found block %x by scanning the chain", height, block.Hash)
+			err := bc.Database.Update(func(txn *badger.Txn) error {
+				return txn.Set(heightKey(height), block.Hash)
+			})
+			if err != nil {
+				log.Printf("failed to backfill height index at %d: %v", height, err)
+			}
+			return block, nil
+		}
+		hash = block.PrevHash
+	}
+	return nil, fmt.Errorf("no block at height %d", height)
+}
+
+// HeadersInRange returns the headers of blocks from height `from` to `to` inclusive,
+// in ascending height order. `to` is clamped to the current tip.
+func (bc *Blockchain) HeadersInRange(from, to int) ([]*BlockHeader, error) {
+	if from < 0 || to < from {
+		return nil, fmt.Errorf("invalid header range %d-%d", from, to)
+	}
+	blocks, err := bc.BlocksInRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	headers := make([]*BlockHeader, 0, len(blocks))
+	for _, block := range blocks {
+		headers = append(headers, block.Header())
+	}
+	return headers, nil
+}
+
+// BlocksInRange returns the blocks from height `from` to `to` inclusive, in
+// ascending height order. `to` is clamped to the current tip.
+func (bc *Blockchain) BlocksInRange(from, to int) ([]*Block, error) {
+	if from < 0 || to < from {
+		return nil, fmt.Errorf("invalid block range %d-%d", from, to)
+	}
+	tip, err := bc.Tip()
+	if err != nil {
+		return nil, err
+	}
+	if to > tip.Height {
+		to = tip.Height
+	}
+
+	blocks := []*Block{}
+	for height := from; height <= to; height++ {
+		block, err := bc.GetBlockByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// Tip returns the most recently added block
+func (bc *Blockchain) Tip() (*Block, error) {
+	return bc.GetBlockByHash(bc.lastHash())
+}
+
+// Info returns the chain's height and the timestamps of its genesis block and
+// tip. It reads those two blocks, the genesis block via the height index,
+// rather than walking the chain.
+func (bc *Blockchain) Info() (ChainInfo, error) {
+	if len(bc.lastHash()) == 0 {
+		return ChainInfo{}, fmt.Errorf("blockchain is empty")
+	}
+	tip, err := bc.Tip()
+	if err != nil {
+		return ChainInfo{}, err
+	}
+	genesis, err := bc.GetBlockByHeight(0)
+	if err != nil {
+		return ChainInfo{}, err
+	}
+	return ChainInfo{
+		Height:           tip.Height,
+		BlockCount:       tip.Height + 1,
+		GenesisTimestamp: genesis.Timestamp,
+		TipTimestamp:     tip.Timestamp,
+		AgeSeconds:       timeNow().Unix() - genesis.Timestamp,
+	}, nil
+}
+
+// Height returns the height of the chain tip, or -1 for a chain with no
+// blocks, reading only the tip rather than walking the chain
+func (bc *Blockchain) Height() (int, error) {
+	if len(bc.lastHash()) == 0 {
+		return -1, nil
+	}
+	tip, err := bc.Tip()
+	if err != nil {
+		return 0, err
+	}
+	return tip.Height, nil
+}
+
+// OrphanedTipError reports that the lh pointer references a block record that is missing,
+// e.g. after a partial write or manual corruption.
+type OrphanedTipError struct {
+	LastHash []byte
+	// Candidate is the highest intact block found via the height index, or nil if there is none
+	Candidate *Block
+}
+
+func (e *OrphanedTipError) Error() string {
+	if e.Candidate == nil {
+		return fmt.Sprintf("chain tip %x is missing and no intact block was found in the height index", e.LastHash)
+	}
+	return fmt.Sprintf("chain tip %x is missing; the chain can be repaired by rewinding to block %x at height %d",
+		e.LastHash, e.Candidate.Hash, e.Candidate.Height)
+}
+
+// CheckTip verifies that the block referenced by lh exists. If it does not, it
+// returns an *OrphanedTipError naming the highest intact block that RepairTip would rewind to.
+func (bc *Blockchain) CheckTip() error {
+	tipHash := bc.lastHash()
+	if len(tipHash) == 0 {
+		return nil
+	}
+
+	err := bc.Database.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(tipHash)
+		return err
+	})
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, badger.ErrKeyNotFound) {
+		return fmt.Errorf("failed to check chain tip: %v", err)
+	}
+
+	candidate, err := bc.highestIndexedBlock()
+	if err != nil {
+		return err
+	}
+	return &OrphanedTipError{LastHash: tipHash, Candidate: candidate}
+}
+
+// RepairTip rewinds an orphaned lh pointer to the highest intact indexed block,
+// dropping height index entries above it. It is a no-op when the tip is intact.
+func (bc *Blockchain) RepairTip() error {
+	err := bc.CheckTip()
+	var orphaned *OrphanedTipError
+	if !errors.As(err, &orphaned) {
+		return err
+	}
+	if orphaned.Candidate == nil {
+		return orphaned
+	}
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	tip := orphaned.Candidate
+	err = bc.Database.Update(func(txn *badger.Txn) error {
+		for height := tip.Height + 1; ; height++ {
+			if _, err := txn.Get(heightKey(height)); errors.Is(err, badger.ErrKeyNotFound) {
+				break
+			} else if err != nil {
+				return err
+			}
+			if err := txn.Delete(heightKey(height)); err != nil {
+				return err
+			}
+		}
+		return txn.Set([]byte("lh"), tip.Hash)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to repair chain tip: %v", err)
+	}
+	bc.LastHash = tip.Hash
+	bc.sigs.clear()
+	return nil
+}
+
+// highestIndexedBlock returns the highest block in the height index whose record exists, or nil
+func (bc *Blockchain) highestIndexedBlock() (*Block, error) {
+	var best *Block
+	err := bc.Database.View(func(txn *badger.Txn) error {
+		prefix := []byte("h-")
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			hash, err := it.Item().ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			item, err := txn.Get(hash)
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			err = item.Value(func(val []byte) error {
+				block := Deserialize(val)
+				if best == nil || block.Height > best.Height {
+					best = block
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan height index: %v", err)
+	}
+	return best, nil
+}
+
+// BlocksSince returns the blocks with Timestamp >= since, newest first. Block
+// timestamps never decrease along the chain, so the walk stops at the first older block.
+func (bc *Blockchain) BlocksSince(since int64) ([]*Block, error) {
+	var blocks []*Block
+	hash := bc.lastHash()
+	for len(hash) > 0 {
+		block, err := bc.GetBlockByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		if block.Timestamp < since {
+			break
+		}
+		blocks = append(blocks, block)
+		hash = block.PrevHash
+	}
+	return blocks, nil
+}
+
+// BlocksBetween returns the blocks with from <= Timestamp <= to, newest first.
+// Like BlocksSince, the walk stops at the first block older than from.
+func (bc *Blockchain) BlocksBetween(from, to int64) ([]*Block, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid time window: from %d is after to %d", from, to)
+	}
+	var blocks []*Block
+	hash := bc.lastHash()
+	for len(hash) > 0 {
+		block, err := bc.GetBlockByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		if block.Timestamp < from {
+			break
+		}
+		if block.Timestamp <= to {
+			blocks = append(blocks, block)
+		}
+		hash = block.PrevHash
+	}
+	return blocks, nil
+}
+
+// FindCertificate returns the newest block recording certificateID, or nil if
+// the certificate is not on the chain
+func (bc *Blockchain) FindCertificate(certificateID string) (*Block, error) {
+	hash := bc.lastHash()
+	for len(hash) > 0 {
+		block, err := bc.GetBlockByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		if block.VerifyCertificate(certificateID) {
+			return block, nil
+		}
+		hash = block.PrevHash
+	}
+	return nil, nil
+}
+
+// FindAllCertificateOccurrences returns every block recording certificateID,
+// newest first, or none if it is not on the chain. More than one block means
+// the certificate was issued more than once.
+func (bc *Blockchain) FindAllCertificateOccurrences(certificateID string) ([]*Block, error) {
+	var blocks []*Block
+	hash := bc.lastHash()
+	for len(hash) > 0 {
+		block, err := bc.GetBlockByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		if block.VerifyCertificate(certificateID) {
+			blocks = append(blocks, block)
+		}
+		hash = block.PrevHash
+	}
+	return blocks, nil
+}
+
+// IssuedCertificate is a certificate hash together with the height of the block that recorded it
+type IssuedCertificate struct {
+	CertificateHash string `json:"certificate_hash"`
+	BlockHeight     int    `json:"block_height"`
+}
+
+// IssuedBy returns every certificate recorded in blocks created by the given
+// university address, oldest first.
+func (bc *Blockchain) IssuedBy(address string) ([]IssuedCertificate, error) {
+	var blocks []*Block
+	hash := bc.lastHash()
+	for len(hash) > 0 {
+		block, err := bc.GetBlockByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		if string(block.UniversityAddress) == address {
+			blocks = append(blocks, block)
+		}
+		hash = block.PrevHash
+	}
+
+	issued := []IssuedCertificate{}
+	for i := len(blocks) - 1; i >= 0; i-- {
+		for _, certHash := range blocks[i].CertificateHashes {
+			issued = append(issued, IssuedCertificate{CertificateHash: certHash, BlockHeight: blocks[i].Height})
+		}
+	}
+	return issued, nil
+}
+
+// BlocksSignedBy returns every block created by the holder of publicKey, oldest
+// first. Blocks are matched on UniversityAddress under both the current and the
+// legacy address encoding of the key, as block validation accepts either.
+func (bc *Blockchain) BlocksSignedBy(publicKey ecdsa.PublicKey) ([]*Block, error) {
+	address := string(identity.AddressFromPublicKey(publicKey))
+	legacy := string(identity.LegacyAddressFromPublicKey(publicKey))
+
+	var blocks []*Block
+	hash := bc.lastHash()
+	for len(hash) > 0 {
+		block, err := bc.GetBlockByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		if signer := string(block.UniversityAddress); signer == address || signer == legacy {
+			blocks = append(blocks, block)
+		}
+		hash = block.PrevHash
+	}
+	slices.Reverse(blocks)
+	return blocks, nil
+}
+
+// ChainID identifies the network a chain belongs to. It is the hex-encoded
+// hash of the genesis block, so chains with different genesis blocks never share an ID.
+func (bc *Blockchain) ChainID() (string, error) {
+	genesis, err := bc.GetBlockByHeight(0)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(genesis.Hash), nil
+}
+
+// lastHash returns a snapshot of the tip hash, safe against concurrent writers
+func (bc *Blockchain) lastHash() []byte {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	return bc.LastHash
+}
+
 // Iterator creates a new blockchain iterator
 func (bc *Blockchain) Iterator() *BlockchainIterator {
 	return &BlockchainIterator{
-		CurrentHash: bc.LastHash,
+		CurrentHash: bc.lastHash(),
 		Database:    bc.Database,
+		chain:       bc,
 	}
 }
 
 // Next returns the next block in the chain (newest to oldest)
 func (iter *BlockchainIterator) Next() *Block {
+	if iter.chain != nil {
+		block, err := iter.chain.GetBlockByHash(iter.CurrentHash)
+		if err != nil {
+			log.Panic(err)
+		}
+		iter.CurrentHash = block.PrevHash
+		return block
+	}
+
 	var block *Block
 	err := iter.Database.View(func(txn *badger.Txn) error {
 		item, err := txn.Get(iter.CurrentHash)
@@ -335,10 +1215,26 @@ func (iter *BlockchainIterator) Next() *Block {
 	return block
 }
 
-// Close closes the underlying database
+// Close stops the block cache sweeper and closes the underlying database. It
+// is safe to call more than once, including concurrently; calls after the
+// first return nil.
 func (bc *Blockchain) Close() error {
-	if bc.Database != nil {
-		return bc.Database.Close()
-	}
-	return nil
+	var err error
+	bc.closeOnce.Do(func() {
+		if bc.stopSweeper != nil {
+			bc.stopSweeper()
+		}
+		if bc.Database != nil {
+			if err := bc.persistCertificateFilter(); err != nil {
+				log.Printf("%v", err)
+			}
+			err = bc.Database.Close()
+		}
+		if bc.removeOnClose != "" {
+			if removeErr := os.RemoveAll(bc.removeOnClose); err == nil {
+				err = removeErr
+			}
+		}
+	})
+	return err
 }
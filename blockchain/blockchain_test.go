@@ -0,0 +1,775 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amanechibana/veritas-chain/identity"
+	"github.com/dgraph-io/badger/v4"
+)
+
+// testChainOptions keeps test chains in memory and silences Badger
+var testChainOptions = BlockchainOptions{InMemory: true, Logger: NopLogger()}
+
+// newTestChain creates a chain in a temp dir with the given number of blocks after genesis
+func newTestChain(t testing.TB, blocks int) (*Blockchain, identity.Signer) {
+	t.Helper()
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	chain := InitBlockchain("", signer, testChainOptions)
+	t.Cleanup(func() { chain.Close() })
+
+	for i := 0; i < blocks; i++ {
+		if _, err := chain.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+	return chain, signer
+}
+
+// setClock makes blocks created during the test use *now as their timestamp
+func setClock(t testing.TB, now *time.Time) {
+	t.Helper()
+	timeNow = func() time.Time { return *now }
+	t.Cleanup(func() { timeNow = time.Now })
+}
+
+func TestChainIDDiffersByGenesis(t *testing.T) {
+	chainA, _ := newTestChain(t, 1)
+	chainB, _ := newTestChain(t, 1)
+
+	idA, err := chainA.ChainID()
+	if err != nil {
+		t.Fatalf("chain ID: %v", err)
+	}
+	idB, err := chainB.ChainID()
+	if err != nil {
+		t.Fatalf("chain ID: %v", err)
+	}
+	if idA == idB {
+		t.Fatalf("chains with different genesis blocks share chain ID %s", idA)
+	}
+
+	// The ID is stable as the chain grows
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	if _, err := chainA.AddBlock([]string{"CERT-X"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	if again, _ := chainA.ChainID(); again != idA {
+		t.Fatalf("chain ID changed after adding a block: %s -> %s", idA, again)
+	}
+}
+
+func TestCloseIsIdempotent(t *testing.T) {
+	chain, _ := newTestChain(t, 1)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = chain.Close()
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("close %d: %v", i, err)
+		}
+	}
+	if err := chain.Close(); err != nil {
+		t.Fatalf("close after close: %v", err)
+	}
+}
+
+func TestAddBlockRejectsSeparatorInCertificateID(t *testing.T) {
+	chain, signer := newTestChain(t, 0)
+
+	_, err := chain.AddBlock([]string{"CERT-001", "CERT,002"}, signer)
+	if err == nil || !strings.Contains(err.Error(), `"CERT,002"`) {
+		t.Fatalf("expected error naming CERT,002, got %v", err)
+	}
+	if tip, _ := chain.Tip(); tip.Height != 0 {
+		t.Fatalf("rejected block was added at height %d", tip.Height)
+	}
+}
+
+func TestValidateChainRecordsDuration(t *testing.T) {
+	chain, _ := newTestChain(t, 1)
+
+	before := validateChainDuration.Count()
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if after := validateChainDuration.Count(); after != before+1 {
+		t.Fatalf("expected histogram count %d, got %d", before+1, after)
+	}
+}
+
+func TestBlocksSince(t *testing.T) {
+	clock := time.Unix(1700000000, 0)
+	setClock(t, &clock)
+
+	chain, signer := newTestChain(t, 0)
+	for i := 1; i <= 3; i++ {
+		clock = clock.Add(100 * time.Second)
+		if _, err := chain.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+
+	// Blocks 2 and 3 are at 1700000200 and 1700000300
+	blocks, err := chain.BlocksSince(1700000150)
+	if err != nil {
+		t.Fatalf("blocks since: %v", err)
+	}
+	if len(blocks) != 2 || blocks[0].Height != 3 || blocks[1].Height != 2 {
+		t.Fatalf("expected heights [3 2], got %d blocks", len(blocks))
+	}
+
+	blocks, err = chain.BlocksSince(1700000301)
+	if err != nil {
+		t.Fatalf("blocks since: %v", err)
+	}
+	if len(blocks) != 0 {
+		t.Fatalf("expected no blocks after the tip, got %d", len(blocks))
+	}
+}
+
+func TestBlocksBetween(t *testing.T) {
+	clock := time.Unix(1700000000, 0)
+	setClock(t, &clock)
+
+	chain, signer := newTestChain(t, 0)
+	for i := 1; i <= 3; i++ {
+		clock = clock.Add(100 * time.Second)
+		if _, err := chain.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+
+	// Blocks 1, 2 and 3 are at 1700000100, 1700000200 and 1700000300
+	blocks, err := chain.BlocksBetween(1700000100, 1700000250)
+	if err != nil {
+		t.Fatalf("blocks between: %v", err)
+	}
+	if len(blocks) != 2 || blocks[0].Height != 2 || blocks[1].Height != 1 {
+		t.Fatalf("expected heights [2 1], got %d blocks", len(blocks))
+	}
+
+	blocks, err = chain.BlocksBetween(1700000210, 1700000290)
+	if err != nil {
+		t.Fatalf("blocks between: %v", err)
+	}
+	if len(blocks) != 0 {
+		t.Fatalf("expected no blocks between 2 and 3, got %d", len(blocks))
+	}
+
+	if _, err := chain.BlocksBetween(1700000300, 1700000100); err == nil {
+		t.Fatal("expected from after to to be rejected")
+	}
+}
+
+func TestMinBlockInterval(t *testing.T) {
+	clock := time.Unix(1700000000, 0)
+	setClock(t, &clock)
+
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	options := testChainOptions
+	options.MinBlockInterval = time.Minute
+	chain := InitBlockchain("", signer, options)
+	t.Cleanup(func() { chain.Close() })
+
+	clock = clock.Add(30 * time.Second)
+	if _, err := chain.AddBlock([]string{"CERT-001"}, signer); !errors.Is(err, ErrBlockTooSoon) {
+		t.Fatalf("expected ErrBlockTooSoon 30s after genesis, got %v", err)
+	}
+	if tip, err := chain.Tip(); err != nil || tip.Height != 0 {
+		t.Fatalf("expected the rejected block not to be stored, tip %v, err %v", tip, err)
+	}
+
+	clock = clock.Add(30 * time.Second)
+	block, err := chain.AddBlock([]string{"CERT-001"}, signer)
+	if err != nil {
+		t.Fatalf("expected a block exactly one interval later to be accepted: %v", err)
+	}
+	if block.Height != 1 {
+		t.Fatalf("expected height 1, got %d", block.Height)
+	}
+}
+
+// deleteKey removes a raw key from the chain database
+func deleteKey(t testing.TB, chain *Blockchain, key []byte) {
+	t.Helper()
+	err := chain.Database.Update(func(txn *badger.Txn) error {
+		return txn.Delete(key)
+	})
+	if err != nil {
+		t.Fatalf("delete key %x: %v", key, err)
+	}
+}
+
+func TestOrphanedTipDetectedAndRepaired(t *testing.T) {
+	chain, signer := newTestChain(t, 3)
+	if err := chain.CheckTip(); err != nil {
+		t.Fatalf("intact chain reported orphaned tip: %v", err)
+	}
+
+	orphan := chain.LastHash
+	deleteKey(t, chain, orphan)
+
+	var orphaned *OrphanedTipError
+	if err := chain.CheckTip(); !errors.As(err, &orphaned) {
+		t.Fatalf("expected OrphanedTipError, got %v", err)
+	}
+	if orphaned.Candidate == nil || orphaned.Candidate.Height != 2 {
+		t.Fatalf("expected repair candidate at height 2, got %+v", orphaned.Candidate)
+	}
+
+	if err := chain.RepairTip(); err != nil {
+		t.Fatalf("repair: %v", err)
+	}
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("repaired chain invalid: %v", err)
+	}
+	block, err := chain.AddBlock([]string{"CERT-AFTER-REPAIR"}, signer)
+	if err != nil {
+		t.Fatalf("add block after repair: %v", err)
+	}
+	if block.Height != 3 {
+		t.Fatalf("expected new block at height 3, got %d", block.Height)
+	}
+}
+
+func TestOrphanedTipWithoutIndexIsReported(t *testing.T) {
+	chain, _ := newTestChain(t, 1)
+
+	deleteKey(t, chain, chain.LastHash)
+	deleteKey(t, chain, heightKey(0))
+	deleteKey(t, chain, heightKey(1))
+
+	var orphaned *OrphanedTipError
+	if err := chain.CheckTip(); !errors.As(err, &orphaned) || orphaned.Candidate != nil {
+		t.Fatalf("expected OrphanedTipError without candidate, got %v", err)
+	}
+	if err := chain.RepairTip(); err == nil {
+		t.Fatal("expected repair to fail without an intact indexed block")
+	}
+}
+
+func TestFindAllCertificateOccurrences(t *testing.T) {
+	chain, signer := newTestChain(t, 1)
+	first, err := chain.AddBlock([]string{"CERT-DUP", "CERT-X"}, signer)
+	if err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	if _, err := chain.AddBlock([]string{"CERT-Y"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	second, err := chain.AddBlock([]string{"CERT-DUP"}, signer)
+	if err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+
+	blocks, err := chain.FindAllCertificateOccurrences("CERT-DUP")
+	if err != nil {
+		t.Fatalf("find all: %v", err)
+	}
+	if len(blocks) != 2 || !bytes.Equal(blocks[0].Hash, second.Hash) || !bytes.Equal(blocks[1].Hash, first.Hash) {
+		t.Fatalf("expected blocks %d and %d, newest first, got %d blocks", second.Height, first.Height, len(blocks))
+	}
+	if blocks, err := chain.FindAllCertificateOccurrences("CERT-X"); err != nil || len(blocks) != 1 {
+		t.Fatalf("expected one block for CERT-X, got %d, %v", len(blocks), err)
+	}
+	if blocks, err := chain.FindAllCertificateOccurrences("CERT-MISSING"); err != nil || len(blocks) != 0 {
+		t.Fatalf("expected no blocks for a missing certificate, got %d, %v", len(blocks), err)
+	}
+}
+
+func TestGetBlockByHeightFallsBackToScan(t *testing.T) {
+	chain, _ := newTestChain(t, 4)
+	want, err := chain.GetBlockByHeight(2)
+	if err != nil {
+		t.Fatalf("get block: %v", err)
+	}
+	deleteKey(t, chain, heightKey(2))
+
+	got, err := chain.GetBlockByHeight(2)
+	if err != nil {
+		t.Fatalf("get block without index entry: %v", err)
+	}
+	if !bytes.Equal(got.Hash, want.Hash) {
+		t.Fatalf("scan found block %x, want %x", got.Hash, want.Hash)
+	}
+
+	var indexed []byte
+	err = chain.Database.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(heightKey(2))
+		if err != nil {
+			return err
+		}
+		indexed, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil || !bytes.Equal(indexed, want.Hash) {
+		t.Fatalf("expected index entry backfilled with %x, got %x (%v)", want.Hash, indexed, err)
+	}
+
+	if _, err := chain.GetBlockByHeight(5); err == nil {
+		t.Fatal("expected a height above the tip to fail")
+	}
+}
+
+func TestInMemoryChainDoesNotTouchDisk(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "unused")
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+
+	chain := InitBlockchain(dbPath, signer, BlockchainOptions{InMemory: true, SyncWrites: true, Logger: NopLogger()})
+	defer chain.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err := chain.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if stats := chain.GetStats(); stats.BlockCount != 4 || stats.CertificateCount != 3 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Fatalf("in-memory chain created %s on disk", dbPath)
+	}
+}
+
+func TestCompactPreservesChain(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "chain")
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	options := BlockchainOptions{Logger: NopLogger()}
+
+	chain := InitBlockchain(dbPath, signer, options)
+	for i := 0; i < 5; i++ {
+		if _, err := chain.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+	tip := chain.LastHash
+
+	if _, err := Compact(dbPath, options); err == nil {
+		t.Fatal("expected compact to refuse a database that is open")
+	}
+	if err := chain.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	result, err := Compact(dbPath, options)
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if result.SizeBefore == 0 || result.SizeAfter == 0 {
+		t.Fatalf("unexpected sizes %+v", result)
+	}
+	if _, err := os.Stat(dbPath + ".old"); !os.IsNotExist(err) {
+		t.Fatalf("old database left behind at %s.old", dbPath)
+	}
+
+	chain = ContinueBlockchain(dbPath, options)
+	defer chain.Close()
+	if !bytes.Equal(chain.LastHash, tip) {
+		t.Fatalf("tip changed from %x to %x", tip, chain.LastHash)
+	}
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("validate after compact: %v", err)
+	}
+	if stats := chain.GetStats(); stats.BlockCount != 6 {
+		t.Fatalf("unexpected stats after compact %+v", stats)
+	}
+}
+
+func TestCompactRefusesAfterInterruptedSwap(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "chain")
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	options := BlockchainOptions{Logger: NopLogger()}
+	chain := InitBlockchain(dbPath, signer, options)
+	if err := chain.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	// A compaction that died between its renames leaves the chain at .old only
+	if err := os.Rename(dbPath, dbPath+".old"); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if err := CheckInterruptedCompaction(dbPath); err == nil || !strings.Contains(err.Error(), "move it back") {
+		t.Fatalf("expected the interrupted compaction to be reported, got %v", err)
+	}
+	if _, err := Compact(dbPath, options); err == nil {
+		t.Fatal("expected compact to refuse while the chain is set aside")
+	}
+	if !DBExists(dbPath + ".old") {
+		t.Fatal("compact removed the only copy of the chain")
+	}
+
+	// With a database back in place the leftover copy is still reported
+	if err := os.Rename(dbPath+".old", dbPath); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if err := os.Mkdir(dbPath+".old", 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if _, err := Compact(dbPath, options); err == nil || !strings.Contains(err.Error(), "interrupted compaction") {
+		t.Fatalf("expected compact to refuse with a leftover .old, got %v", err)
+	}
+}
+
+func TestGenesisPayloadInMerkleRoot(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	payload := []string{"signers:0f1e2d3c4b5a", "network:testnet"}
+	options := testChainOptions
+	options.GenesisPayload = payload
+
+	chain := InitBlockchain("", signer, options)
+	defer chain.Close()
+
+	genesis, err := chain.GetBlockByHeight(0)
+	if err != nil {
+		t.Fatalf("get genesis: %v", err)
+	}
+	if want := BuildMerkleTree(payload).Root.Data; !bytes.Equal(genesis.MerkleRoot, want) {
+		t.Fatalf("genesis Merkle root %x, expected %x", genesis.MerkleRoot, want)
+	}
+	if bytes.Equal(genesis.MerkleRoot, Genesis(signer).MerkleRoot) {
+		t.Fatal("payload did not change the genesis Merkle root")
+	}
+	if !genesis.VerifyCertificate("network:testnet") {
+		t.Fatal("genesis does not record its payload")
+	}
+
+	if _, err := chain.AddBlock([]string{"CERT-001"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	if _, err := GenesisWithPayload([]string{"bad\nentry"}, signer); err == nil {
+		t.Fatal("expected an invalid payload entry to be rejected")
+	}
+}
+
+func TestChainInfo(t *testing.T) {
+	clock := time.Unix(1700000000, 0)
+	setClock(t, &clock)
+
+	chain, signer := newTestChain(t, 0)
+	for i := 1; i <= 3; i++ {
+		clock = clock.Add(100 * time.Second)
+		if _, err := chain.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+	clock = clock.Add(time.Hour)
+
+	info, err := chain.Info()
+	if err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	genesis, err := chain.GetBlockByHeight(0)
+	if err != nil {
+		t.Fatalf("genesis: %v", err)
+	}
+	tip, err := chain.Tip()
+	if err != nil {
+		t.Fatalf("tip: %v", err)
+	}
+	if info.GenesisTimestamp != genesis.Timestamp || info.TipTimestamp != tip.Timestamp {
+		t.Fatalf("expected timestamps %d and %d, got %+v", genesis.Timestamp, tip.Timestamp, info)
+	}
+	want := ChainInfo{
+		Height:           3,
+		BlockCount:       4,
+		GenesisTimestamp: 1700000000,
+		TipTimestamp:     1700000300,
+		AgeSeconds:       300 + 3600,
+	}
+	if info != want {
+		t.Fatalf("expected %+v, got %+v", want, info)
+	}
+
+	if _, err := newEmptyChain(t).Info(); err == nil {
+		t.Fatalf("expected an error for an empty chain")
+	}
+}
+
+func TestGetStatsCountsDistinctCertificates(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	options := testChainOptions
+	options.GenesisPayload = []string{"network:testnet"}
+	chain := InitBlockchain("", signer, options)
+	defer chain.Close()
+
+	// An odd number of certificates, whose Merkle tree pads the last leaf
+	if _, err := chain.AddBlock([]string{"CERT-001", "CERT-002", "CERT-003"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	// A certificate listed twice in one block
+	if _, err := chain.AddBlock([]string{"CERT-004", "CERT-004"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	// A certificate recorded again in a later block
+	if _, err := chain.AddBlock([]string{"CERT-002"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+
+	stats := chain.GetStats()
+	if stats.BlockCount != 4 || stats.CertificateCount != 4 {
+		t.Fatalf("expected 4 blocks and 4 certificates, excluding the genesis payload and duplicates, got %+v", stats)
+	}
+	byUniversity, err := chain.GetStatsByUniversity()
+	if err != nil {
+		t.Fatalf("GetStatsByUniversity: %v", err)
+	}
+	if got := byUniversity[string(signer.Address())]; got != stats {
+		t.Fatalf("per-university stats %+v disagree with %+v", got, stats)
+	}
+}
+
+func TestValidateRange(t *testing.T) {
+	chain, _ := newTestChain(t, 6)
+
+	for _, r := range [][2]int{{0, 6}, {0, 0}, {3, 6}, {6, 6}} {
+		if err := chain.ValidateRange(r[0], r[1]); err != nil {
+			t.Fatalf("validate %d-%d: %v", r[0], r[1], err)
+		}
+	}
+	for _, r := range [][2]int{{-1, 2}, {4, 3}, {5, 7}} {
+		if err := chain.ValidateRange(r[0], r[1]); err == nil {
+			t.Fatalf("expected range %d-%d to be rejected", r[0], r[1])
+		}
+	}
+
+	// Tamper with block 2 on disk without re-signing it
+	tamperBlock(t, chain, 2)
+
+	if err := chain.ValidateRange(1, 3); err == nil || !strings.Contains(err.Error(), "block 2") {
+		t.Fatalf("expected the tampered block inside the window to be caught, got %v", err)
+	}
+	if err := chain.ValidateRange(3, 6); err != nil {
+		t.Fatalf("window after the tampered block should validate: %v", err)
+	}
+}
+
+// tamperBlock overwrites the stored block at height with a copy whose
+// certificates are changed, without re-signing or re-hashing it
+func tamperBlock(t *testing.T, chain *Blockchain, height int) {
+	t.Helper()
+	block, err := chain.GetBlockByHeight(height)
+	if err != nil {
+		t.Fatalf("get block: %v", err)
+	}
+	tampered := *block
+	tampered.CertificateHashes = []string{strings.Repeat("0", 64)}
+	err = chain.Database.Update(func(txn *badger.Txn) error {
+		return txn.Set(block.Hash, tampered.Serialize())
+	})
+	if err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+}
+
+func TestValidateFromCheckpoint(t *testing.T) {
+	chain, _ := newTestChain(t, 8)
+	atHeight := func(height int) Checkpoint {
+		block, err := chain.GetBlockByHeight(height)
+		if err != nil {
+			t.Fatalf("get block: %v", err)
+		}
+		return Checkpoint{Height: height, Hash: block.Hash}
+	}
+
+	for _, height := range []int{0, 4, 8} {
+		if err := chain.ValidateFromCheckpoint(atHeight(height)); err != nil {
+			t.Fatalf("validate from checkpoint at %d: %v", height, err)
+		}
+	}
+
+	// Blocks below the checkpoint are trusted, not checked
+	tamperBlock(t, chain, 2)
+	if err := chain.ValidateChain(); err == nil {
+		t.Fatalf("expected full validation to catch the tampered block")
+	}
+	if err := chain.ValidateFromCheckpoint(atHeight(4)); err != nil {
+		t.Fatalf("validate from checkpoint above the tampered block: %v", err)
+	}
+
+	// Blocks above it are
+	tamperBlock(t, chain, 6)
+	if err := chain.ValidateFromCheckpoint(atHeight(4)); err == nil || !strings.Contains(err.Error(), "block 6") {
+		t.Fatalf("expected the tampered block above the checkpoint to be caught, got %v", err)
+	}
+}
+
+func TestValidateFromCheckpointRejectsMismatch(t *testing.T) {
+	chain, _ := newTestChain(t, 5)
+	block, err := chain.GetBlockByHeight(3)
+	if err != nil {
+		t.Fatalf("get block: %v", err)
+	}
+	other, err := chain.GetBlockByHeight(2)
+	if err != nil {
+		t.Fatalf("get block: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		checkpoint Checkpoint
+		want       string
+	}{
+		{"hash of another block", Checkpoint{Height: 3, Hash: other.Hash}, "checkpoint mismatch at height 3"},
+		{"above the tip", Checkpoint{Height: 6, Hash: block.Hash}, "above the chain tip"},
+		{"negative height", Checkpoint{Height: -1, Hash: block.Hash}, "invalid checkpoint height"},
+	}
+	for _, tt := range tests {
+		if err := chain.ValidateFromCheckpoint(tt.checkpoint); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	// A checkpoint matching a stored block whose contents were altered is rejected
+	tamperBlock(t, chain, 3)
+	if err := chain.ValidateFromCheckpoint(Checkpoint{Height: 3, Hash: block.Hash}); err == nil || !strings.Contains(err.Error(), "corrupted") {
+		t.Fatalf("expected a corrupted checkpoint block to be rejected, got %v", err)
+	}
+}
+
+func TestValidateChainWindowMatchesFullValidation(t *testing.T) {
+	chain, _ := newTestChain(t, 9)
+	windows := []int{1, 2, 3, 4, 10, 100}
+
+	for _, window := range windows {
+		if err := chain.ValidateChainWindow(window); err != nil {
+			t.Fatalf("window %d: valid chain failed validation: %v", window, err)
+		}
+	}
+	if err := chain.ValidateChainWindow(0); err == nil {
+		t.Fatal("expected a zero window to be rejected")
+	}
+
+	// Tamper with block 5 on disk without re-signing it
+	block, err := chain.GetBlockByHeight(5)
+	if err != nil {
+		t.Fatalf("get block: %v", err)
+	}
+	tampered := *block
+	tampered.CertificateHashes = []string{strings.Repeat("0", 64)}
+	err = chain.Database.Update(func(txn *badger.Txn) error {
+		return txn.Set(block.Hash, tampered.Serialize())
+	})
+	if err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+
+	want := chain.ValidateChain()
+	if want == nil {
+		t.Fatal("expected the tampered chain to fail validation")
+	}
+	for _, window := range windows {
+		if err := chain.ValidateChainWindow(window); err == nil || err.Error() != want.Error() {
+			t.Fatalf("window %d: expected %q, got %v", window, want, err)
+		}
+	}
+}
+
+func TestFullyValidate(t *testing.T) {
+	chain, signer := newTestChain(t, 2)
+	signers := identity.AuthorizedSigners{"uni-a": string(signer.Address())}
+	if err := chain.FullyValidate(signers); err != nil {
+		t.Fatalf("valid chain rejected: %v", err)
+	}
+
+	other := identity.NewIdentitySigner(identity.MakeIdentity())
+	if _, err := chain.AddBlock([]string{"CERT-OTHER"}, other); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("structural validation should still pass: %v", err)
+	}
+	err := chain.FullyValidate(signers)
+	if err == nil || !strings.Contains(err.Error(), "block 3") || !strings.Contains(err.Error(), "not an authorized signer") {
+		t.Fatalf("expected block 3 to be rejected as unauthorized, got %v", err)
+	}
+
+	signers["uni-b"] = string(other.Address())
+	if err := chain.FullyValidate(signers); err != nil {
+		t.Fatalf("chain with both signers authorized rejected: %v", err)
+	}
+	if err := chain.FullyValidate(nil); err == nil {
+		t.Fatal("expected validation without a registry to fail")
+	}
+}
+
+func TestFullyValidateTamperedSignature(t *testing.T) {
+	chain, signer := newTestChain(t, 3)
+	signers := identity.AuthorizedSigners{"uni-a": string(signer.Address())}
+
+	block, err := chain.GetBlockByHeight(2)
+	if err != nil {
+		t.Fatalf("get block: %v", err)
+	}
+	tampered := *block
+	tampered.Signature = append([]byte{}, block.Signature...)
+	tampered.Signature[len(tampered.Signature)-1] ^= 0xff
+	err = chain.Database.Update(func(txn *badger.Txn) error {
+		return txn.Set(block.Hash, tampered.Serialize())
+	})
+	if err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+	if err := chain.FullyValidate(signers); err == nil || !strings.Contains(err.Error(), "block 2") {
+		t.Fatalf("expected the tampered block 2 to be caught, got %v", err)
+	}
+
+	// Re-hashing the block hides the tampering from the hash check but not the signature check
+	tampered.Hash = tampered.CalculateHash()
+	if err := checkAuthorizedSigner(&tampered, signers, nil); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Fatalf("expected an invalid signature, got %v", err)
+	}
+}
+
+func TestFullyValidateUnauthorizedGenesis(t *testing.T) {
+	bootstrap := identity.NewIdentitySigner(identity.MakeIdentity())
+	chain := InitBlockchain("", bootstrap, testChainOptions)
+	t.Cleanup(func() { chain.Close() })
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	if _, err := chain.AddBlock([]string{"CERT-001"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	signers := identity.AuthorizedSigners{"uni-a": string(signer.Address())}
+
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("structural validation should still pass: %v", err)
+	}
+	err := chain.FullyValidate(signers)
+	if err == nil || !strings.Contains(err.Error(), "genesis block was signed by "+string(bootstrap.Address())) {
+		t.Fatalf("expected the unauthorized genesis to be rejected, got %v", err)
+	}
+
+	chain.options.GenesisSigner = string(bootstrap.Address())
+	if err := chain.FullyValidate(signers); err != nil {
+		t.Fatalf("genesis by the configured genesis signer rejected: %v", err)
+	}
+	// The genesis signer is not authorized for later blocks
+	if _, err := chain.AddBlock([]string{"CERT-002"}, bootstrap); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	if err := chain.FullyValidate(signers); err == nil || !strings.Contains(err.Error(), "block 2") {
+		t.Fatalf("expected block 2 by the genesis signer to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// certificateFilterKey stores the certificate Bloom filter, with the tip it covers
+var certificateFilterKey = []byte("certificate-filter")
+
+// DefaultCertificateFilterFPRate is the false-positive rate the certificate
+// filter is sized for when BlockchainOptions leaves it unset
+const DefaultCertificateFilterFPRate = 0.01
+
+// minCertificateFilterCapacity keeps the filter of a young chain from being
+// rebuilt every few blocks
+const minCertificateFilterCapacity = 1024
+
+// bloomFilter is a Bloom filter over certificate hashes. It answers "definitely
+// not recorded" or "possibly recorded". Fields are exported for gob.
+type bloomFilter struct {
+	Bits   []uint64
+	Hashes int
+	// Capacity is how many hashes the filter was sized for; past it the false
+	// positive rate climbs above FPRate
+	Capacity int
+	Count    int
+	FPRate   float64
+	// Tip is the hash of the newest block whose certificates were added
+	Tip []byte
+}
+
+// newBloomFilter sizes a filter for capacity hashes at false-positive rate fpRate
+func newBloomFilter(capacity int, fpRate float64) *bloomFilter {
+	capacity = max(capacity, 1)
+	bits := math.Ceil(-float64(capacity) * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	words := max(int(math.Ceil(bits/64)), 1)
+	hashes := max(int(math.Round(float64(words*64)/float64(capacity)*math.Ln2)), 1)
+	return &bloomFilter{
+		Bits:     make([]uint64, words),
+		Hashes:   hashes,
+		Capacity: capacity,
+		FPRate:   fpRate,
+	}
+}
+
+// positions calls fn with each bit position of certHash, derived by double
+// hashing from a SHA-256 of the hash
+func (f *bloomFilter) positions(certHash string, fn func(bit uint64)) {
+	sum := sha256.Sum256([]byte(certHash))
+	h1 := binary.BigEndian.Uint64(sum[0:8])
+	h2 := binary.BigEndian.Uint64(sum[8:16]) | 1
+	m := uint64(len(f.Bits)) * 64
+	for i := 0; i < f.Hashes; i++ {
+		fn((h1 + uint64(i)*h2) % m)
+	}
+}
+
+func (f *bloomFilter) add(certHash string) {
+	f.positions(certHash, func(bit uint64) {
+		f.Bits[bit/64] |= 1 << (bit % 64)
+	})
+	f.Count++
+}
+
+// mayContain reports false only if certHash was never added
+func (f *bloomFilter) mayContain(certHash string) bool {
+	found := true
+	f.positions(certHash, func(bit uint64) {
+		if f.Bits[bit/64]&(1<<(bit%64)) == 0 {
+			found = false
+		}
+	})
+	return found
+}
+
+// certificateFilterFPRate returns the configured false-positive rate
+func (o BlockchainOptions) certificateFilterFPRate() float64 {
+	if o.CertificateFilterFPRate <= 0 || o.CertificateFilterFPRate >= 1 {
+		return DefaultCertificateFilterFPRate
+	}
+	return o.CertificateFilterFPRate
+}
+
+// CertificateExists reports whether certificateID is recorded on the chain.
+// The certificate filter answers most lookups for absent certificates without
+// reading any block; a possible hit is confirmed with FindCertificate.
+func (bc *Blockchain) CertificateExists(certificateID string) (bool, error) {
+	hash := sha256.Sum256([]byte(certificateID))
+	maybe, err := bc.certificateMayExist(hex.EncodeToString(hash[:]))
+	if err != nil {
+		return false, err
+	}
+	if !maybe {
+		return false, nil
+	}
+	block, err := bc.FindCertificate(certificateID)
+	if err != nil {
+		return false, err
+	}
+	return block != nil, nil
+}
+
+// certificateMayExist consults the certificate filter, loading or building it first if needed
+func (bc *Blockchain) certificateMayExist(certHash string) (bool, error) {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	bc.filterMu.Lock()
+	defer bc.filterMu.Unlock()
+
+	if bc.filter == nil {
+		filter, err := bc.loadCertificateFilter()
+		if err != nil {
+			return false, err
+		}
+		if filter == nil {
+			if filter, err = bc.buildCertificateFilter(); err != nil {
+				return false, err
+			}
+			if err := bc.saveCertificateFilter(filter); err != nil {
+				return false, err
+			}
+		}
+		bc.filter = filter
+	}
+	return bc.filter.mayContain(certHash), nil
+}
+
+// RebuildCertificateFilter rebuilds the certificate filter from the blocks on
+// the chain and persists it. CertificateExists builds the filter on first use,
+// so this is only needed to refresh it ahead of time.
+func (bc *Blockchain) RebuildCertificateFilter() error {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	return bc.rebuildCertificateFilter()
+}
+
+// rebuildCertificateFilter is RebuildCertificateFilter for callers holding bc.mu
+func (bc *Blockchain) rebuildCertificateFilter() error {
+	bc.filterMu.Lock()
+	defer bc.filterMu.Unlock()
+
+	filter, err := bc.buildCertificateFilter()
+	if err != nil {
+		return err
+	}
+	if err := bc.saveCertificateFilter(filter); err != nil {
+		return err
+	}
+	bc.filter = filter
+	return nil
+}
+
+// buildCertificateFilter walks the chain from the tip and adds every
+// certificate hash to a new filter. Callers hold bc.mu and bc.filterMu.
+func (bc *Blockchain) buildCertificateFilter() (*bloomFilter, error) {
+	var certHashes []string
+	for hash := bc.LastHash; len(hash) > 0; {
+		block, err := bc.GetBlockByHash(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk chain for certificate filter: %v", err)
+		}
+		certHashes = append(certHashes, block.CertificateHashes...)
+		hash = block.PrevHash
+	}
+
+	capacity := max(2*len(certHashes), minCertificateFilterCapacity)
+	filter := newBloomFilter(capacity, bc.options.certificateFilterFPRate())
+	for _, certHash := range certHashes {
+		filter.add(certHash)
+	}
+	filter.Tip = bc.LastHash
+	return filter, nil
+}
+
+// loadCertificateFilter returns the persisted filter, or nil when there is
+// none or it does not cover the current tip at the configured rate. Callers
+// hold bc.mu.
+func (bc *Blockchain) loadCertificateFilter() (*bloomFilter, error) {
+	var filter *bloomFilter
+	err := bc.Database.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(certificateFilterKey)
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			var stored bloomFilter
+			if err := gob.NewDecoder(bytes.NewReader(val)).Decode(&stored); err != nil {
+				return err
+			}
+			filter = &stored
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate filter: %v", err)
+	}
+	if filter == nil || !bytes.Equal(filter.Tip, bc.LastHash) || filter.FPRate != bc.options.certificateFilterFPRate() {
+		return nil, nil
+	}
+	return filter, nil
+}
+
+func (bc *Blockchain) saveCertificateFilter(filter *bloomFilter) error {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(filter); err != nil {
+		return fmt.Errorf("failed to encode certificate filter: %v", err)
+	}
+	err := bc.Database.Update(func(txn *badger.Txn) error {
+		return txn.Set(certificateFilterKey, buffer.Bytes())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save certificate filter: %v", err)
+	}
+	return nil
+}
+
+// addToCertificateFilter records block's certificates in the filter, if it
+// is loaded. A filter past its capacity is dropped to be rebuilt at a larger
+// size on the next lookup. Callers hold bc.mu.
+func (bc *Blockchain) addToCertificateFilter(block *Block) {
+	bc.filterMu.Lock()
+	defer bc.filterMu.Unlock()
+	if bc.filter == nil {
+		return
+	}
+	for _, certHash := range block.CertificateHashes {
+		bc.filter.add(certHash)
+	}
+	bc.filter.Tip = block.Hash
+	if bc.filter.Count > bc.filter.Capacity {
+		bc.filter = nil
+	}
+}
+
+// persistCertificateFilter saves the loaded filter so the next open does not rebuild it
+func (bc *Blockchain) persistCertificateFilter() error {
+	bc.filterMu.Lock()
+	defer bc.filterMu.Unlock()
+	if bc.filter == nil {
+		return nil
+	}
+	return bc.saveCertificateFilter(bc.filter)
+}
@@ -0,0 +1,177 @@
+package blockchain
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+// newCertificateChain creates a chain of blocks blocks holding perBlock certificates each
+func newCertificateChain(t testing.TB, options BlockchainOptions, blocks, perBlock int) *Blockchain {
+	t.Helper()
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	chain := InitBlockchain("", signer, options)
+	t.Cleanup(func() { chain.Close() })
+	for i := 0; i < blocks; i++ {
+		ids := make([]string, perBlock)
+		for j := range ids {
+			ids[j] = fmt.Sprintf("CERT-%04d-%03d", i, j)
+		}
+		if _, err := chain.AddBlock(ids, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+	return chain
+}
+
+func TestCertificateExistsHasNoFalseNegatives(t *testing.T) {
+	chain := newCertificateChain(t, testChainOptions, 50, 20)
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+
+	check := func(stage string, blocks int) {
+		t.Helper()
+		for i := 0; i < blocks; i++ {
+			for j := 0; j < 20; j++ {
+				id := fmt.Sprintf("CERT-%04d-%03d", i, j)
+				exists, err := chain.CertificateExists(id)
+				if err != nil {
+					t.Fatalf("%s: CertificateExists(%s): %v", stage, id, err)
+				}
+				if !exists {
+					t.Fatalf("%s: CertificateExists(%s) = false for a recorded certificate", stage, id)
+				}
+			}
+		}
+		for i := 0; i < 200; i++ {
+			id := fmt.Sprintf("ABSENT-%04d", i)
+			exists, err := chain.CertificateExists(id)
+			if err != nil {
+				t.Fatalf("%s: CertificateExists(%s): %v", stage, id, err)
+			}
+			if exists {
+				t.Fatalf("%s: CertificateExists(%s) = true for an absent certificate", stage, id)
+			}
+		}
+	}
+	check("built", 50)
+
+	// Blocks added after the filter was built are added to it
+	for i := 50; i < 60; i++ {
+		ids := make([]string, 20)
+		for j := range ids {
+			ids[j] = fmt.Sprintf("CERT-%04d-%03d", i, j)
+		}
+		if _, err := chain.AddBlock(ids, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+	check("extended", 60)
+
+	if err := chain.RebuildCertificateFilter(); err != nil {
+		t.Fatalf("RebuildCertificateFilter: %v", err)
+	}
+	check("rebuilt", 60)
+}
+
+func TestCertificateFilterGrowsPastCapacity(t *testing.T) {
+	chain := newCertificateChain(t, testChainOptions, 1, 1)
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	if _, err := chain.CertificateExists("ABSENT"); err != nil {
+		t.Fatalf("CertificateExists: %v", err)
+	}
+
+	ids := make([]string, minCertificateFilterCapacity+1)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("BULK-%05d", i)
+	}
+	if _, err := chain.AddBlock(ids, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	for _, id := range ids {
+		if exists, err := chain.CertificateExists(id); err != nil || !exists {
+			t.Fatalf("CertificateExists(%s) = %v, %v, want true", id, exists, err)
+		}
+	}
+	if chain.filter.Capacity < len(ids) {
+		t.Errorf("filter capacity %d after rebuild, want at least %d", chain.filter.Capacity, len(ids))
+	}
+}
+
+func TestBloomFilterFalsePositiveRate(t *testing.T) {
+	for _, fpRate := range []float64{0.1, 0.01, 0.001} {
+		filter := newBloomFilter(5000, fpRate)
+		for i := 0; i < 5000; i++ {
+			filter.add(fmt.Sprintf("present-%d", i))
+		}
+		falsePositives := 0
+		const probes = 50000
+		for i := 0; i < probes; i++ {
+			if filter.mayContain(fmt.Sprintf("absent-%d", i)) {
+				falsePositives++
+			}
+		}
+		if rate := float64(falsePositives) / probes; rate > 2*fpRate {
+			t.Errorf("false positive rate %.4f, configured %.4f", rate, fpRate)
+		}
+	}
+}
+
+func TestCertificateFilterPersistsAcrossReopen(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "chain")
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	options := BlockchainOptions{Logger: NopLogger(), CertificateFilterFPRate: 1e-9}
+
+	chain := InitBlockchain(dbPath, signer, options)
+	for i := 0; i < 5; i++ {
+		if _, err := chain.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+	if _, err := chain.CertificateExists("ABSENT"); err != nil {
+		t.Fatalf("CertificateExists: %v", err)
+	}
+	// Added after the filter was saved, so only Close persists it
+	if _, err := chain.AddBlock([]string{"CERT-005"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	if err := chain.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	chain = ContinueBlockchain(dbPath, options)
+	defer chain.Close()
+	readsBefore := chain.diskReads.Load()
+	if exists, err := chain.CertificateExists("ABSENT"); err != nil || exists {
+		t.Fatalf("CertificateExists(ABSENT) = %v, %v, want false", exists, err)
+	}
+	if reads := chain.diskReads.Load() - readsBefore; reads != 0 {
+		t.Errorf("negative lookup read %d blocks, want the persisted filter to answer it", reads)
+	}
+	if exists, err := chain.CertificateExists("CERT-005"); err != nil || !exists {
+		t.Fatalf("CertificateExists(CERT-005) = %v, %v, want true", exists, err)
+	}
+}
+
+func BenchmarkCertificateExistsAbsent(b *testing.B) {
+	chain := newCertificateChain(b, testChainOptions, 200, 10)
+	if _, err := chain.CertificateExists("warm-up"); err != nil {
+		b.Fatalf("CertificateExists: %v", err)
+	}
+
+	b.Run("filter", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := chain.CertificateExists(fmt.Sprintf("ABSENT-%d", i)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := chain.FindCertificate(fmt.Sprintf("ABSENT-%d", i)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
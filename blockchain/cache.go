@@ -0,0 +1,166 @@
+package blockchain
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DefaultBlockCacheSize is the number of recently used blocks a Blockchain keeps deserialized in memory
+const DefaultBlockCacheSize = 256
+
+// blockCache is a fixed-size LRU cache of deserialized blocks keyed by block hash.
+// Blocks are content-addressed, so an entry never goes stale while its block is on the chain.
+// With a non-zero ttl, entries not used for ttl are also dropped, on access and by sweep.
+type blockCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	now   func() time.Time
+	order *list.List // front is most recently used
+	items map[string]*list.Element
+}
+
+// cacheEntry is a cached block and when it was last used
+type cacheEntry struct {
+	block    *Block
+	lastUsed time.Time
+}
+
+func newBlockCache(size int) *blockCache {
+	return &blockCache{
+		size:  size,
+		now:   time.Now,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *blockCache) get(hash []byte) (*Block, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[string(hash)]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	now := c.now()
+	if c.expired(entry, now) {
+		c.remove(elem)
+		return nil, false
+	}
+	entry.lastUsed = now
+	c.order.MoveToFront(elem)
+	return entry.block, true
+}
+
+func (c *blockCache) add(block *Block) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.size <= 0 {
+		return
+	}
+	key := string(block.Hash)
+	if elem, ok := c.items[key]; ok {
+		elem.Value = &cacheEntry{block: block, lastUsed: c.now()}
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.order.PushFront(&cacheEntry{block: block, lastUsed: c.now()})
+	c.evict()
+}
+
+func (c *blockCache) resize(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.size = size
+	c.evict()
+}
+
+// setTTL changes how long an unused entry is kept; zero keeps entries until evicted by size
+func (c *blockCache) setTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ttl = ttl
+}
+
+// clear drops every entry
+func (c *blockCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+// sweep drops every entry whose ttl has expired and returns how many were dropped
+func (c *blockCache) sweep() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The back of the list is the least recently used, so expired entries are
+	// all at the back
+	now := c.now()
+	dropped := 0
+	for oldest := c.order.Back(); oldest != nil && c.expired(oldest.Value.(*cacheEntry), now); oldest = c.order.Back() {
+		c.remove(oldest)
+		dropped++
+	}
+	return dropped
+}
+
+// startSweeper sweeps the cache every interval in the background. The returned
+// function stops the sweeper and waits for it to exit; it may be called more than once.
+func (c *blockCache) startSweeper(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.sweep()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-exited
+	}
+}
+
+// len returns the number of cached blocks
+func (c *blockCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// expired reports whether entry has gone unused for longer than the ttl. Callers hold c.mu.
+func (c *blockCache) expired(entry *cacheEntry, now time.Time) bool {
+	return c.ttl > 0 && now.Sub(entry.lastUsed) > c.ttl
+}
+
+// evict drops least recently used entries until the cache fits its size. Callers hold c.mu.
+func (c *blockCache) evict() {
+	for c.order.Len() > c.size && c.order.Len() > 0 {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove drops elem from the cache. Callers hold c.mu.
+func (c *blockCache) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, string(elem.Value.(*cacheEntry).block.Hash))
+}
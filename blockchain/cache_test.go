@@ -0,0 +1,166 @@
+package blockchain
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/amanechibana/veritas-chain/identity"
+	"github.com/dgraph-io/badger/v4"
+)
+
+// readBlockFromDisk deserializes a block directly from Badger, bypassing the cache
+func readBlockFromDisk(t testing.TB, chain *Blockchain, hash []byte) *Block {
+	t.Helper()
+	var block *Block
+	err := chain.Database.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(hash)
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			block = Deserialize(val)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("read block %x: %v", hash, err)
+	}
+	return block
+}
+
+func TestCachedBlocksMatchDisk(t *testing.T) {
+	chain, _ := newTestChain(t, 5)
+
+	// Warm the cache, then confirm further reads hit it and match disk
+	iter := chain.Iterator()
+	for {
+		if len(iter.Next().PrevHash) == 0 {
+			break
+		}
+	}
+	readsBefore := chain.diskReads.Load()
+
+	for height := 0; height <= 5; height++ {
+		cached, err := chain.GetBlockByHeight(height)
+		if err != nil {
+			t.Fatalf("get block %d: %v", height, err)
+		}
+		onDisk := readBlockFromDisk(t, chain, cached.Hash)
+		if !reflect.DeepEqual(cached, onDisk) {
+			t.Fatalf("cached block at height %d differs from disk", height)
+		}
+	}
+	if reads := chain.diskReads.Load() - readsBefore; reads != 0 {
+		t.Fatalf("expected warm cache to serve all blocks, got %d disk reads", reads)
+	}
+}
+
+func TestBlockCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newBlockCache(2)
+	a, b, c := &Block{Hash: []byte("a")}, &Block{Hash: []byte("b")}, &Block{Hash: []byte("c")}
+
+	cache.add(a)
+	cache.add(b)
+	cache.get(a.Hash) // a is now more recent than b
+	cache.add(c)
+
+	if _, ok := cache.get(b.Hash); ok {
+		t.Fatal("expected least recently used block to be evicted")
+	}
+	for _, blk := range []*Block{a, c} {
+		if _, ok := cache.get(blk.Hash); !ok {
+			t.Fatalf("expected block %s to be cached", blk.Hash)
+		}
+	}
+}
+
+func TestBlockCacheExpiresUnusedBlocks(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	cache := newBlockCache(3)
+	cache.now = func() time.Time { return now }
+	cache.setTTL(time.Minute)
+
+	blocks := make([]*Block, 5)
+	for i := range blocks {
+		blocks[i] = &Block{Hash: []byte(fmt.Sprintf("block-%d", i))}
+		cache.add(blocks[i])
+	}
+	if n := cache.len(); n != 3 {
+		t.Fatalf("expected the cache to stay at its bound of 3, got %d", n)
+	}
+	if _, ok := cache.get(blocks[0].Hash); ok {
+		t.Fatal("expected the oldest block to be evicted past the bound")
+	}
+
+	now = now.Add(30 * time.Second)
+	if _, ok := cache.get(blocks[4].Hash); !ok {
+		t.Fatal("expected block 4 to be cached")
+	}
+	now = now.Add(45 * time.Second)
+	if dropped := cache.sweep(); dropped != 2 {
+		t.Fatalf("expected the sweep to drop 2 expired blocks, dropped %d", dropped)
+	}
+	if _, ok := cache.get(blocks[4].Hash); !ok {
+		t.Fatal("expected the recently used block to survive the sweep")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := cache.get(blocks[4].Hash); ok {
+		t.Fatal("expected an expired block to miss on access")
+	}
+	if n := cache.len(); n != 0 {
+		t.Fatalf("expected an empty cache, got %d entries", n)
+	}
+}
+
+func TestBlockCacheSweeperStopsOnClose(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	options := testChainOptions
+	options.BlockCacheSize = 2
+	options.BlockCacheTTL = 10 * time.Millisecond
+	chain := InitBlockchain("", signer, options)
+	for i := 0; i < 4; i++ {
+		if _, err := chain.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+	if n := chain.cache.len(); n > 2 {
+		t.Fatalf("expected at most 2 cached blocks, got %d", n)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for chain.cache.len() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("sweeper did not drop expired blocks")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Close waits for the sweeper to exit
+	if err := chain.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+}
+
+func BenchmarkIterateChain(b *testing.B) {
+	for _, size := range []int{0, DefaultBlockCacheSize} {
+		b.Run(fmt.Sprintf("cache=%d", size), func(b *testing.B) {
+			chain, _ := newTestChain(b, 50)
+			chain.SetBlockCacheSize(size)
+			readsBefore := chain.diskReads.Load()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				iter := chain.Iterator()
+				for {
+					if len(iter.Next().PrevHash) == 0 {
+						break
+					}
+				}
+			}
+			b.ReportMetric(float64(chain.diskReads.Load()-readsBefore)/float64(b.N), "reads/op")
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// forEachCertificateHash calls fn with every certificate hash recorded on the
+// chain, walking from the tip back to the first block after genesis. A genesis
+// block's entries are its GenesisPayload rather than certificates and are
+// skipped, as in GetStats. A hash recorded in several blocks is passed once
+// per block.
+func (bc *Blockchain) forEachCertificateHash(fn func(certHash string)) error {
+	hash := bc.lastHash()
+	for len(hash) > 0 {
+		block, err := bc.GetBlockByHash(hash)
+		if err != nil {
+			return err
+		}
+		if len(block.PrevHash) == 0 {
+			break
+		}
+		for _, certHash := range block.CertificateHashes {
+			fn(certHash)
+		}
+		hash = block.PrevHash
+	}
+	return nil
+}
+
+// AllCertificateHashes returns every distinct certificate hash recorded on the
+// chain, sorted, the set GetStats counts. It holds the whole set in memory;
+// use CertificateHashesPage to page through large chains.
+func (bc *Blockchain) AllCertificateHashes() ([]string, error) {
+	seen := make(map[string]bool)
+	err := bc.forEachCertificateHash(func(certHash string) {
+		seen[certHash] = true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, 0, len(seen))
+	for certHash := range seen {
+		hashes = append(hashes, certHash)
+	}
+	sort.Strings(hashes)
+	return hashes, nil
+}
+
+// ErrInvalidCertificateCursor is returned for a malformed CertificateHashesPage cursor
+var ErrInvalidCertificateCursor = errors.New("invalid certificate cursor")
+
+// CertificateHashesPage pages through the distinct certificate hashes on the
+// chain, the set GetStats counts, in the order they were first recorded. It
+// returns up to limit hashes starting at cursor, together with the cursor of
+// the next page. An empty cursor starts at the first block after genesis and
+// an empty next cursor marks the last page. The cursor is "height:index", the
+// position of the next hash, so each page seeks straight to its first block
+// with the height index. A hash recorded again is dropped from the page that
+// reaches it, so a page can hold fewer than limit hashes, and finding such
+// repeats reads the blocks below the page.
+func (bc *Blockchain) CertificateHashesPage(cursor string, limit int) ([]string, string, error) {
+	height, index, err := parseCertificateCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	hashes := []string{}
+	if limit <= 0 {
+		return hashes, cursor, nil
+	}
+	tip, err := bc.Height()
+	if err != nil {
+		return nil, "", err
+	}
+	if height == 0 {
+		// The genesis block holds its payload rather than certificates
+		height, index = 1, 0
+	}
+
+	first := height
+	seen := make(map[string]bool)
+	next := ""
+scan:
+	for ; height <= tip; height, index = height+1, 0 {
+		block, err := bc.GetBlockByHeight(height)
+		if err != nil {
+			return nil, "", err
+		}
+		if index > len(block.CertificateHashes) {
+			return nil, "", fmt.Errorf("%w: block %d has %d certificates", ErrInvalidCertificateCursor, height, len(block.CertificateHashes))
+		}
+		if height == first {
+			for _, certHash := range block.CertificateHashes[:index] {
+				seen[certHash] = true
+			}
+		}
+		for ; index < len(block.CertificateHashes); index++ {
+			if len(hashes) == limit {
+				next = fmt.Sprintf("%d:%d", height, index)
+				break scan
+			}
+			if certHash := block.CertificateHashes[index]; !seen[certHash] {
+				seen[certHash] = true
+				hashes = append(hashes, certHash)
+			}
+		}
+		if len(hashes) == limit && height < tip {
+			next = fmt.Sprintf("%d:0", height+1)
+			break
+		}
+	}
+
+	hashes, err = bc.dropRecordedBefore(hashes, first)
+	if err != nil {
+		return nil, "", err
+	}
+	return hashes, next, nil
+}
+
+// dropRecordedBefore removes from hashes those already recorded by a block
+// after genesis and below height, keeping the order of the rest
+func (bc *Blockchain) dropRecordedBefore(hashes []string, height int) ([]string, error) {
+	pending := make(map[string]bool, len(hashes))
+	for _, certHash := range hashes {
+		pending[certHash] = true
+	}
+	earlier := make(map[string]bool)
+	for h := 1; h < height && len(earlier) < len(pending); h++ {
+		block, err := bc.GetBlockByHeight(h)
+		if err != nil {
+			return nil, err
+		}
+		for _, certHash := range block.CertificateHashes {
+			if pending[certHash] {
+				earlier[certHash] = true
+			}
+		}
+	}
+	if len(earlier) == 0 {
+		return hashes, nil
+	}
+
+	kept := hashes[:0]
+	for _, certHash := range hashes {
+		if !earlier[certHash] {
+			kept = append(kept, certHash)
+		}
+	}
+	return kept, nil
+}
+
+// parseCertificateCursor splits a "height:index" cursor, treating "" as the start
+func parseCertificateCursor(cursor string) (height, index int, err error) {
+	if cursor == "" {
+		return 0, 0, nil
+	}
+	h, i, ok := strings.Cut(cursor, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("%w %q", ErrInvalidCertificateCursor, cursor)
+	}
+	height, herr := strconv.Atoi(h)
+	index, ierr := strconv.Atoi(i)
+	if herr != nil || ierr != nil || height < 0 || index < 0 {
+		return 0, 0, fmt.Errorf("%w %q", ErrInvalidCertificateCursor, cursor)
+	}
+	return height, index, nil
+}
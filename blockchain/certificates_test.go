@@ -0,0 +1,71 @@
+package blockchain
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+func TestAllCertificateHashes(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	options := testChainOptions
+	options.GenesisPayload = []string{"network:testnet"}
+	chain := InitBlockchain("", signer, options)
+	defer chain.Close()
+
+	batches := [][]string{
+		{"CERT-003", "CERT-001"},
+		{"CERT-002"},
+		// CERT-001 is recorded again and must only be listed once
+		{"CERT-001", "CERT-004", "CERT-005"},
+	}
+	for _, batch := range batches {
+		if _, err := chain.AddBlock(batch, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+
+	want := hashCertificateIDs([]string{"CERT-001", "CERT-002", "CERT-003", "CERT-004", "CERT-005"})
+	sort.Strings(want)
+
+	hashes, err := chain.AllCertificateHashes()
+	if err != nil {
+		t.Fatalf("all certificate hashes: %v", err)
+	}
+	if !reflect.DeepEqual(hashes, want) {
+		t.Fatalf("expected %v, got %v", want, hashes)
+	}
+
+	// Paging two at a time lists each hash once, at its first recording,
+	// and skips the genesis payload
+	var paged []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 6 {
+			t.Fatal("pagination did not terminate")
+		}
+		page, next, err := chain.CertificateHashesPage(cursor, 2)
+		if err != nil {
+			t.Fatalf("page at %q: %v", cursor, err)
+		}
+		paged = append(paged, page...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	firstRecorded := hashCertificateIDs([]string{"CERT-003", "CERT-001", "CERT-002", "CERT-004", "CERT-005"})
+	if !reflect.DeepEqual(paged, firstRecorded) {
+		t.Fatalf("expected paged hashes %v, got %v", firstRecorded, paged)
+	}
+	if stats := chain.GetStats(); stats.CertificateCount != len(paged) {
+		t.Fatalf("GetStats counts %d certificates, pages list %d", stats.CertificateCount, len(paged))
+	}
+
+	if _, _, err := chain.CertificateHashesPage("1:9", 2); !errors.Is(err, ErrInvalidCertificateCursor) {
+		t.Fatalf("expected ErrInvalidCertificateCursor, got %v", err)
+	}
+}
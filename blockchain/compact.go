@@ -0,0 +1,167 @@
+package blockchain
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// compactMaxPendingWrites bounds how many batched writes Load keeps in flight while compacting
+const compactMaxPendingWrites = 256
+
+// CompactResult reports the on-disk size of a database before and after compaction
+type CompactResult struct {
+	SizeBefore int64
+	SizeAfter  int64
+}
+
+// Compact rewrites the database at dbPath into a fresh directory containing
+// only live keys and swaps it into place. It must run offline: Badger's
+// directory lock makes it fail while a node has the database open.
+//
+// The swap renames dbPath aside, renames the compacted copy into place and
+// then removes the old copy. If the process dies between the two renames the
+// original database is left intact at dbPath+".old", and Compact refuses to
+// run again until it has been dealt with; see CheckInterruptedCompaction.
+func Compact(dbPath string, options BlockchainOptions) (CompactResult, error) {
+	var result CompactResult
+	if options.InMemory {
+		return result, fmt.Errorf("cannot compact an in-memory database")
+	}
+	if err := CheckInterruptedCompaction(dbPath); err != nil {
+		return result, err
+	}
+	if !DBExists(dbPath) {
+		return result, fmt.Errorf("no blockchain found at %s", dbPath)
+	}
+
+	before, err := dirSize(dbPath)
+	if err != nil {
+		return result, err
+	}
+	result.SizeBefore = before
+
+	src, err := badger.Open(options.badgerOptions(dbPath))
+	if err != nil {
+		return result, fmt.Errorf("failed to open %s (is a node serving it?): %v", dbPath, err)
+	}
+
+	tmpPath := dbPath + ".compact"
+	oldPath := dbPath + ".old"
+	if err := os.RemoveAll(tmpPath); err != nil {
+		src.Close()
+		return result, err
+	}
+	if err := copyLiveKeys(src, tmpPath, options); err != nil {
+		src.Close()
+		os.RemoveAll(tmpPath)
+		return result, err
+	}
+	if err := src.Close(); err != nil {
+		os.RemoveAll(tmpPath)
+		return result, fmt.Errorf("failed to close %s: %v", dbPath, err)
+	}
+
+	if err := os.Rename(dbPath, oldPath); err != nil {
+		return result, fmt.Errorf("failed to move %s aside: %v", dbPath, err)
+	}
+	if err := os.Rename(tmpPath, dbPath); err != nil {
+		return result, fmt.Errorf("failed to move compacted database into place (original kept at %s): %v", oldPath, err)
+	}
+	if err := os.RemoveAll(oldPath); err != nil {
+		return result, fmt.Errorf("compacted, but failed to remove %s: %v", oldPath, err)
+	}
+
+	after, err := dirSize(dbPath)
+	if err != nil {
+		return result, err
+	}
+	result.SizeAfter = after
+	return result, nil
+}
+
+// CheckInterruptedCompaction returns an error when dbPath+".old", which Compact
+// moves the original database to while it swaps in the compacted copy, is
+// still present. With nothing at dbPath it holds the only copy of the chain
+// and must be moved back; with a database at dbPath the compaction finished
+// its swap but not its cleanup, and the old copy should be removed once the
+// chain at dbPath is known to be good.
+func CheckInterruptedCompaction(dbPath string) error {
+	oldPath := dbPath + ".old"
+	if _, err := os.Stat(oldPath); os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !DBExists(dbPath) {
+		return fmt.Errorf("%s is missing but %s holds the chain from an interrupted compaction; move it back to %s", dbPath, oldPath, dbPath)
+	}
+	return fmt.Errorf("%s was left by an interrupted compaction; remove it once the chain at %s is verified", oldPath, dbPath)
+}
+
+// copyLiveKeys streams the latest version of every live key in src into a new database at dstPath
+func copyLiveKeys(src *badger.DB, dstPath string, options BlockchainOptions) error {
+	if err := os.MkdirAll(dstPath, 0o755); err != nil {
+		return err
+	}
+	dst, err := badger.Open(options.badgerOptions(dstPath))
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", dstPath, err)
+	}
+
+	r, w := io.Pipe()
+	backupErr := make(chan error, 1)
+	go func() {
+		_, err := src.Backup(w, 0)
+		w.CloseWithError(err)
+		backupErr <- err
+	}()
+
+	loadErr := dst.Load(r, compactMaxPendingWrites)
+	r.CloseWithError(loadErr)
+	if err := <-backupErr; err != nil {
+		dst.Close()
+		return fmt.Errorf("failed to read live keys: %v", err)
+	}
+	if loadErr != nil {
+		dst.Close()
+		return fmt.Errorf("failed to write compacted database: %v", loadErr)
+	}
+	return dst.Close()
+}
+
+// DiskUsage returns the total size in bytes of the files of the database at
+// dbPath. Badger preallocates its value log while the database is open, so
+// measure a closed database for a figure that matches what Compact reports.
+func DiskUsage(dbPath string) (int64, error) {
+	if !DBExists(dbPath) {
+		return 0, fmt.Errorf("no blockchain found at %s", dbPath)
+	}
+	return dirSize(dbPath)
+}
+
+// dirSize returns the total size of the regular files under path
+func dirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure %s: %v", path, err)
+	}
+	return size, nil
+}
@@ -0,0 +1,45 @@
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// KeyInfo describes one raw key of the chain database
+type KeyInfo struct {
+	Key       []byte
+	ValueSize int64
+}
+
+// ListKeys returns every key in the database at dbPath starting with prefix,
+// in key order, for debugging. The database is opened read-only, so nothing
+// can be written, and like Compact it fails while a node has it open.
+func ListKeys(dbPath string, prefix []byte, options BlockchainOptions) ([]KeyInfo, error) {
+	if !DBExists(dbPath) {
+		return nil, fmt.Errorf("no blockchain found at %s", dbPath)
+	}
+	db, err := badger.Open(options.badgerOptions(dbPath).WithReadOnly(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s read-only (is a node serving it?): %v", dbPath, err)
+	}
+	defer db.Close()
+
+	var keys []KeyInfo
+	err = db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		opts.Prefix = prefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			keys = append(keys, KeyInfo{Key: item.KeyCopy(nil), ValueSize: item.ValueSize()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys: %v", err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,107 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// equivocationPrefix prefixes the keys evidence of equivocations is stored under
+var equivocationPrefix = []byte("e-")
+
+// Equivocation is evidence that two different valid blocks were produced for
+// the same height. When both are signed by the same university it shows that
+// university signed conflicting blocks.
+type Equivocation struct {
+	Height      int         `json:"height"`
+	Existing    BlockHeader `json:"existing"`
+	Conflicting BlockHeader `json:"conflicting"`
+	DetectedAt  int64       `json:"detected_at"`
+}
+
+// EquivocationError is returned by ImportBlock for a valid block at a height
+// already occupied by a different block on the chain
+type EquivocationError struct {
+	Equivocation
+}
+
+func (e *EquivocationError) Error() string {
+	return fmt.Sprintf("block %x conflicts with block %x already at height %d (signed by %s and %s)",
+		e.Conflicting.Hash, e.Existing.Hash, e.Height, e.Conflicting.UniversityAddress, e.Existing.UniversityAddress)
+}
+
+// equivocationKey returns the key evidence of a conflicting block is stored under
+func equivocationKey(conflictingHash []byte) []byte {
+	return append(append([]byte{}, equivocationPrefix...), hex.EncodeToString(conflictingHash)...)
+}
+
+// checkOccupiedHeight handles an imported block whose height is already on the
+// chain. The caller holds bc.mu.
+func (bc *Blockchain) checkOccupiedHeight(block *Block, tipHeight int) error {
+	existing, err := bc.GetBlockByHeight(block.Height)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(existing.Hash, block.Hash) {
+		return fmt.Errorf("%w: block %d is already on the chain, whose tip is at %d", ErrStaleHeight, block.Height, tipHeight)
+	}
+
+	evidence := &EquivocationError{Equivocation{
+		Height:      block.Height,
+		Existing:    *existing.Header(),
+		Conflicting: *block.Header(),
+		DetectedAt:  timeNow().Unix(),
+	}}
+	if bc.options.RecordEquivocations {
+		var buffer bytes.Buffer
+		if err := gob.NewEncoder(&buffer).Encode(&evidence.Equivocation); err != nil {
+			log.Panic(err)
+		}
+		err := bc.Database.Update(func(txn *badger.Txn) error {
+			return txn.Set(equivocationKey(block.Hash), buffer.Bytes())
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record equivocation at height %d: %v", block.Height, err)
+		}
+	}
+	return evidence
+}
+
+// Equivocations returns the recorded evidence of conflicting blocks, ordered by
+// the conflicting block's hash
+func (bc *Blockchain) Equivocations() ([]Equivocation, error) {
+	evidence := []Equivocation{}
+	keyLen := len(equivocationKey(make([]byte, 32)))
+	err := bc.Database.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = equivocationPrefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			// Raw block hashes can start with the prefix too
+			if len(it.Item().Key()) != keyLen {
+				continue
+			}
+			err := it.Item().Value(func(val []byte) error {
+				var e Equivocation
+				if err := gob.NewDecoder(bytes.NewReader(val)).Decode(&e); err != nil {
+					return err
+				}
+				evidence = append(evidence, e)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read equivocations: %v", err)
+	}
+	return evidence, nil
+}
@@ -0,0 +1,80 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+// conflictingBlock returns a valid block at height 1 that differs from chain's
+func conflictingBlock(t *testing.T, chain *Blockchain, signer identity.Signer) *Block {
+	t.Helper()
+	genesis, err := chain.GetBlockByHeight(0)
+	if err != nil {
+		t.Fatalf("get genesis: %v", err)
+	}
+	return NewBlock([]string{"CERT-CONFLICT"}, genesis.Hash, 1, signer)
+}
+
+func TestImportRejectsDuplicateHeight(t *testing.T) {
+	chain, signer := newTestChain(t, 2)
+	tip := chain.LastHash
+
+	existing, err := chain.GetBlockByHeight(1)
+	if err != nil {
+		t.Fatalf("get block: %v", err)
+	}
+	if err := chain.ImportBlock(existing); err == nil {
+		t.Fatal("expected re-importing a block already on the chain to fail")
+	}
+
+	conflicting := conflictingBlock(t, chain, signer)
+	err = chain.ImportBlock(conflicting)
+	var equivocation *EquivocationError
+	if !errors.As(err, &equivocation) {
+		t.Fatalf("expected an EquivocationError, got %v", err)
+	}
+	if equivocation.Height != 1 || !bytes.Equal(equivocation.Existing.Hash, existing.Hash) ||
+		!bytes.Equal(equivocation.Conflicting.Hash, conflicting.Hash) {
+		t.Fatalf("unexpected evidence %+v", equivocation.Equivocation)
+	}
+	if !bytes.Equal(chain.LastHash, tip) {
+		t.Fatal("rejected block changed the chain tip")
+	}
+	if block, _ := chain.GetBlockByHeight(1); !bytes.Equal(block.Hash, existing.Hash) {
+		t.Fatal("rejected block replaced the block at height 1")
+	}
+	if evidence, err := chain.Equivocations(); err != nil || len(evidence) != 0 {
+		t.Fatalf("expected no recorded evidence by default, got %d (%v)", len(evidence), err)
+	}
+}
+
+func TestImportRecordsEquivocation(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	options := testChainOptions
+	options.RecordEquivocations = true
+	chain := InitBlockchain("", signer, options)
+	defer chain.Close()
+	if _, err := chain.AddBlock([]string{"CERT-001"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+
+	conflicting := conflictingBlock(t, chain, signer)
+	var equivocation *EquivocationError
+	if err := chain.ImportBlock(conflicting); !errors.As(err, &equivocation) {
+		t.Fatalf("expected an EquivocationError, got %v", err)
+	}
+
+	evidence, err := chain.Equivocations()
+	if err != nil {
+		t.Fatalf("equivocations: %v", err)
+	}
+	if len(evidence) != 1 || evidence[0].Height != 1 || !bytes.Equal(evidence[0].Conflicting.Hash, conflicting.Hash) {
+		t.Fatalf("unexpected recorded evidence %+v", evidence)
+	}
+	if err := evidence[0].Conflicting.Validate(); err != nil {
+		t.Fatalf("recorded conflicting header does not validate: %v", err)
+	}
+}
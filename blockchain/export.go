@@ -0,0 +1,159 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+// Export writes the chain to w as NDJSON, one JSON-encoded block per line from genesis to tip
+func (bc *Blockchain) Export(w io.Writer) error {
+	return bc.ExportContext(context.Background(), w)
+}
+
+// ExportContext is Export, stopping with ctx's error if ctx is cancelled part way through
+func (bc *Blockchain) ExportContext(ctx context.Context, w io.Writer) error {
+	if len(bc.lastHash()) == 0 {
+		return nil
+	}
+	tip, err := bc.Tip()
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	for height := 0; height <= tip.Height; height++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		block, err := bc.GetBlockByHeight(height)
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(block); err != nil {
+			return fmt.Errorf("failed to write block %d: %v", height, err)
+		}
+	}
+	return nil
+}
+
+// VerifyExport checks an NDJSON export, as written by Export, without a
+// database: every block must be valid, start from genesis at height 0 and
+// extend the one before it, as ValidateChain requires of a stored chain. When
+// signers is non-nil, each block must also carry a valid signature by an
+// authorized signer, as in FullyValidate. It returns how many blocks were
+// verified before the first failure.
+func VerifyExport(r io.Reader, signers identity.AuthorizedSigners) (int, error) {
+	decoder := json.NewDecoder(r)
+	var prev *Block
+	verified := 0
+	for {
+		var block Block
+		if err := decoder.Decode(&block); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return verified, fmt.Errorf("block %d: invalid block JSON: %v", verified, err)
+		}
+		if prev == nil {
+			if err := validateGenesis(&block, nil); err != nil {
+				return verified, err
+			}
+		} else if err := validateSuccessor(&block, prev, verified, nil); err != nil {
+			return verified, err
+		}
+		if signers != nil {
+			if err := checkAuthorizedSigner(&block, signers, nil); err != nil {
+				return verified, err
+			}
+		}
+		prev = &block
+		verified++
+	}
+	if verified == 0 {
+		return 0, fmt.Errorf("export contains no blocks")
+	}
+	return verified, nil
+}
+
+// Errors ImportBlock wraps when a block's height is not exactly one above the tip
+var (
+	// ErrHeightGap means blocks between the tip and the imported block are missing
+	ErrHeightGap = errors.New("block height leaves a gap after the tip")
+	// ErrStaleHeight means the imported block is already on the chain
+	ErrStaleHeight = errors.New("block height is not above the tip")
+)
+
+// ImportBlock validates block and appends it to the chain. An empty chain only
+// accepts a genesis block; otherwise the block must extend the current tip at
+// exactly the next height. A block above that is rejected with ErrHeightGap,
+// and a copy of a block already on the chain with ErrStaleHeight. A valid
+// block at a height already occupied by a different block is rejected with an
+// *EquivocationError.
+func (bc *Blockchain) ImportBlock(block *Block) error {
+	if err := block.Validate(); err != nil {
+		return fmt.Errorf("block %d is invalid: %v", block.Height, err)
+	}
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if len(bc.LastHash) == 0 {
+		if block.Height != 0 || len(block.PrevHash) != 0 {
+			return fmt.Errorf("block %d cannot start an empty chain: expected a genesis block", block.Height)
+		}
+		return bc.appendBlock(block)
+	}
+
+	tip, err := bc.GetBlockByHash(bc.LastHash)
+	if err != nil {
+		return err
+	}
+	if block.Height <= tip.Height {
+		return bc.checkOccupiedHeight(block, tip.Height)
+	}
+	if block.Height > tip.Height+1 {
+		return fmt.Errorf("%w: block %d imported onto tip %d, missing heights %d to %d",
+			ErrHeightGap, block.Height, tip.Height, tip.Height+1, block.Height-1)
+	}
+	if !bytes.Equal(block.PrevHash, tip.Hash) {
+		return fmt.Errorf("block %d does not extend the chain tip %x", block.Height, tip.Hash)
+	}
+	if block.Timestamp < tip.Timestamp {
+		return fmt.Errorf("block %d timestamp (%d) is before the tip's (%d)", block.Height, block.Timestamp, tip.Timestamp)
+	}
+	return bc.appendBlock(block)
+}
+
+// IsEmpty reports whether the chain records nothing yet: it has no blocks, or only a genesis block
+func (bc *Blockchain) IsEmpty() (bool, error) {
+	if len(bc.lastHash()) == 0 {
+		return true, nil
+	}
+	tip, err := bc.Tip()
+	if err != nil {
+		return false, err
+	}
+	return tip.Height == 0, nil
+}
+
+// Reset deletes every block and record, leaving an empty chain ready for ImportBlock
+func (bc *Blockchain) Reset() error {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if err := bc.Database.DropAll(); err != nil {
+		return fmt.Errorf("failed to reset chain: %v", err)
+	}
+	bc.LastHash = nil
+	bc.cache.clear()
+	bc.sigs.clear()
+	bc.filterMu.Lock()
+	bc.filter = nil
+	bc.filterMu.Unlock()
+	return nil
+}
@@ -0,0 +1,102 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+func TestImportBlockHeights(t *testing.T) {
+	source, _ := newTestChain(t, 4)
+	local := newEmptyChain(t)
+
+	blockAt := func(height int) *Block {
+		t.Helper()
+		block, err := source.GetBlockByHeight(height)
+		if err != nil {
+			t.Fatalf("GetBlockByHeight(%d): %v", height, err)
+		}
+		return block
+	}
+
+	for height := 0; height <= 2; height++ {
+		if err := local.ImportBlock(blockAt(height)); err != nil {
+			t.Fatalf("expected block %d to extend the chain: %v", height, err)
+		}
+	}
+
+	err := local.ImportBlock(blockAt(4))
+	if !errors.Is(err, ErrHeightGap) {
+		t.Fatalf("expected ErrHeightGap for block 4 onto tip 2, got %v", err)
+	}
+	if errors.Is(err, ErrStaleHeight) {
+		t.Fatal("a gap must not be reported as a stale height")
+	}
+
+	err = local.ImportBlock(blockAt(1))
+	if !errors.Is(err, ErrStaleHeight) {
+		t.Fatalf("expected ErrStaleHeight for block 1 onto tip 2, got %v", err)
+	}
+	if errors.Is(err, ErrHeightGap) {
+		t.Fatal("a stale height must not be reported as a gap")
+	}
+
+	if height, err := local.Height(); err != nil || height != 2 {
+		t.Fatalf("expected rejected imports to leave the tip at 2, got %d %v", height, err)
+	}
+	if err := local.ImportBlock(blockAt(3)); err != nil {
+		t.Fatalf("expected block 3 to extend the chain: %v", err)
+	}
+}
+
+func TestVerifyExport(t *testing.T) {
+	chain, signer := newTestChain(t, 3)
+	var export bytes.Buffer
+	if err := chain.Export(&export); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	lines := strings.SplitAfter(strings.TrimSpace(export.String()), "\n")
+
+	verified, err := VerifyExport(strings.NewReader(export.String()), nil)
+	if err != nil || verified != 4 {
+		t.Fatalf("expected 4 verified blocks, got %d: %v", verified, err)
+	}
+	signers := identity.AuthorizedSigners{"uni-a": string(signer.Address())}
+	if _, err := VerifyExport(strings.NewReader(export.String()), signers); err != nil {
+		t.Fatalf("verify with the signer authorized: %v", err)
+	}
+	other := identity.NewIdentitySigner(identity.MakeIdentity())
+	if _, err := VerifyExport(strings.NewReader(export.String()), identity.AuthorizedSigners{"uni-b": string(other.Address())}); err == nil ||
+		!strings.Contains(err.Error(), "not an authorized signer") {
+		t.Fatalf("expected an unauthorized signer to be reported, got %v", err)
+	}
+
+	// A certificate hash changed in block 2 breaks its hash
+	var tampered Block
+	if err := json.Unmarshal([]byte(lines[2]), &tampered); err != nil {
+		t.Fatalf("decode block 2: %v", err)
+	}
+	tampered.CertificateHashes[0] = strings.Repeat("0", 64)
+	tamperedLine, _ := json.Marshal(&tampered)
+	corrupted := lines[0] + lines[1] + string(tamperedLine) + "\n" + lines[3]
+	verified, err = VerifyExport(strings.NewReader(corrupted), nil)
+	if err == nil || verified != 2 || !strings.Contains(err.Error(), "block 2") {
+		t.Fatalf("expected block 2 to fail after 2 good blocks, got %d: %v", verified, err)
+	}
+
+	// Dropping block 1 breaks the height sequence
+	if verified, err := VerifyExport(strings.NewReader(lines[0]+lines[2]+lines[3]), nil); err == nil || verified != 1 {
+		t.Fatalf("expected a missing block to fail after 1 good block, got %d: %v", verified, err)
+	}
+
+	if _, err := VerifyExport(strings.NewReader(lines[0]+"{not json\n"), nil); err == nil || !strings.Contains(err.Error(), "invalid block JSON") {
+		t.Fatalf("expected malformed JSON to be reported, got %v", err)
+	}
+	if _, err := VerifyExport(strings.NewReader(""), nil); err == nil {
+		t.Fatal("expected an empty export to be rejected")
+	}
+}
@@ -0,0 +1,171 @@
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Checkpoint is a block hash at a known height that a verifier already trusts,
+// such as a chain tip obtained out of band
+type Checkpoint struct {
+	Height int    `json:"height"`
+	Hash   []byte `json:"hash"`
+}
+
+// FullProof bundles everything needed to show that a certificate is on the
+// chain: a Merkle proof of the certificate against its block's Merkle root,
+// the header of that block, and the headers linking it to the chain tip.
+type FullProof struct {
+	CertificateID string      `json:"certificate_id"`
+	MerkleProof   MerkleProof `json:"merkle_proof"`
+	Header        BlockHeader `json:"header"`
+
+	// Path holds the headers of the blocks after Header up to the tip, in
+	// ascending height order. It is empty when Header is the tip.
+	Path []*BlockHeader `json:"path"`
+}
+
+// FullProof builds a FullProof for certificateID from the newest block recording it
+func (bc *Blockchain) FullProof(certificateID string) (FullProof, error) {
+	if err := ValidateCertificateID(certificateID); err != nil {
+		return FullProof{}, err
+	}
+	block, err := bc.FindCertificate(certificateID)
+	if err != nil {
+		return FullProof{}, err
+	}
+	if block == nil {
+		return FullProof{}, fmt.Errorf("certificate %q is not on the chain", certificateID)
+	}
+	merkleProof, err := block.GenerateCertificateProof(certificateID)
+	if err != nil {
+		return FullProof{}, fmt.Errorf("failed to build Merkle proof for certificate %q: %v", certificateID, err)
+	}
+
+	tip, err := bc.Tip()
+	if err != nil {
+		return FullProof{}, err
+	}
+	path := []*BlockHeader{}
+	if tip.Height > block.Height {
+		if path, err = bc.HeadersInRange(block.Height+1, tip.Height); err != nil {
+			return FullProof{}, err
+		}
+	}
+
+	return FullProof{
+		CertificateID: certificateID,
+		MerkleProof:   merkleProof,
+		Header:        *block.Header(),
+		Path:          path,
+	}, nil
+}
+
+// VerifyFullProof checks that proof shows certificateID recorded in a block
+// that is an ancestor of, or is, the trusted checkpoint: the Merkle proof must
+// match the block's Merkle root, every header must be valid and signed, the
+// headers must link without gaps, and the header at the checkpoint's height
+// must have the checkpoint's hash. Only version 2+ blocks can be verified from
+// their headers.
+func VerifyFullProof(certificateID string, proof FullProof, trusted Checkpoint) error {
+	if proof.CertificateID != certificateID {
+		return fmt.Errorf("proof is for certificate %q, not %q", proof.CertificateID, certificateID)
+	}
+	if !VerifyProof([]byte(certificateID), proof.MerkleProof, proof.Header.MerkleRoot) {
+		return fmt.Errorf("certificate %q does not match the Merkle root of block %d", certificateID, proof.Header.Height)
+	}
+
+	headers := append([]*BlockHeader{&proof.Header}, proof.Path...)
+	if err := ValidateHeaders(headers); err != nil {
+		return err
+	}
+
+	if trusted.Height < proof.Header.Height {
+		return fmt.Errorf("checkpoint at height %d predates the certificate's block at height %d", trusted.Height, proof.Header.Height)
+	}
+	index := trusted.Height - proof.Header.Height
+	if index >= len(headers) {
+		return fmt.Errorf("proof ends at height %d and does not reach the checkpoint at height %d",
+			headers[len(headers)-1].Height, trusted.Height)
+	}
+	if !bytes.Equal(headers[index].Hash, trusted.Hash) {
+		return fmt.Errorf("block at height %d has hash %x, checkpoint expects %x", trusted.Height, headers[index].Hash, trusted.Hash)
+	}
+	return nil
+}
+
+// CertificateDocument is a self-contained proof that a certificate is
+// recorded on a chain: the header of the block recording it, a Merkle proof
+// against that block's Merkle root, the block signer's public key, and the
+// chain's ID. It can be checked with VerifyCertificateDocument without access
+// to the chain.
+type CertificateDocument struct {
+	CertificateID string      `json:"certificate_id"`
+	ChainID       string      `json:"chain_id"`
+	Header        BlockHeader `json:"header"`
+	MerkleProof   MerkleProof `json:"merkle_proof"`
+	PublicKey     []byte      `json:"public_key"`
+}
+
+// CertificateDocument builds a CertificateDocument for certificateID from the
+// newest block recording it. The block must be version 2+ and carry its
+// signer's public key.
+func (bc *Blockchain) CertificateDocument(certificateID string) (CertificateDocument, error) {
+	if err := ValidateCertificateID(certificateID); err != nil {
+		return CertificateDocument{}, err
+	}
+	block, err := bc.FindCertificate(certificateID)
+	if err != nil {
+		return CertificateDocument{}, err
+	}
+	if block == nil {
+		return CertificateDocument{}, fmt.Errorf("certificate %q is not on the chain", certificateID)
+	}
+	if block.Version < 2 || len(block.PublicKey) == 0 {
+		return CertificateDocument{}, fmt.Errorf("block %d predates signed headers and cannot be verified offline", block.Height)
+	}
+	merkleProof, err := block.GenerateCertificateProof(certificateID)
+	if err != nil {
+		return CertificateDocument{}, fmt.Errorf("failed to build Merkle proof for certificate %q: %v", certificateID, err)
+	}
+	chainID, err := bc.ChainID()
+	if err != nil {
+		return CertificateDocument{}, err
+	}
+
+	return CertificateDocument{
+		CertificateID: certificateID,
+		ChainID:       chainID,
+		Header:        *block.Header(),
+		MerkleProof:   merkleProof,
+		PublicKey:     block.PublicKey,
+	}, nil
+}
+
+// VerifyCertificateDocument checks that doc shows its certificate recorded in
+// a validly signed block: the header's hash must match its contents, the
+// public key must belong to the header's university address and verify its
+// signature, and the Merkle proof must match the header's Merkle root. It
+// does not check that the block is on any particular chain; compare ChainID
+// and the header against a trusted source for that.
+func VerifyCertificateDocument(doc CertificateDocument) error {
+	if err := ValidateCertificateID(doc.CertificateID); err != nil {
+		return err
+	}
+	if len(doc.PublicKey) == 0 {
+		return fmt.Errorf("document has no signer public key")
+	}
+	if len(doc.Header.PublicKey) > 0 && !bytes.Equal(doc.Header.PublicKey, doc.PublicKey) {
+		return fmt.Errorf("document public key does not match the key in the header of block %d", doc.Header.Height)
+	}
+	if err := doc.Header.Validate(); err != nil {
+		return err
+	}
+	if err := checkSignerKey(doc.PublicKey, doc.Header.UniversityAddress, doc.Header.CalculateHashForSigning(), doc.Header.Signature); err != nil {
+		return fmt.Errorf("block %d: %v", doc.Header.Height, err)
+	}
+	if !VerifyProof([]byte(doc.CertificateID), doc.MerkleProof, doc.Header.MerkleRoot) {
+		return fmt.Errorf("certificate %q does not match the Merkle root of block %d", doc.CertificateID, doc.Header.Height)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package blockchain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+func TestFullProofVerifiesAgainstTip(t *testing.T) {
+	chain, signer := newTestChain(t, 2)
+	if _, err := chain.AddBlock([]string{"CERT-A", "CERT-B", "CERT-C"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	checkpoint, err := chain.Tip()
+	if err != nil {
+		t.Fatalf("tip: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := chain.AddBlock([]string{fmt.Sprintf("CERT-LATER-%d", i)}, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+	tip, err := chain.Tip()
+	if err != nil {
+		t.Fatalf("tip: %v", err)
+	}
+
+	proof, err := chain.FullProof("CERT-B")
+	if err != nil {
+		t.Fatalf("full proof: %v", err)
+	}
+	if proof.Header.Height != 3 || len(proof.Path) != 2 {
+		t.Fatalf("expected block 3 with a 2-header path, got block %d with %d headers", proof.Header.Height, len(proof.Path))
+	}
+
+	trustedTip := Checkpoint{Height: tip.Height, Hash: tip.Hash}
+	if err := VerifyFullProof("CERT-B", proof, trustedTip); err != nil {
+		t.Fatalf("verify against tip: %v", err)
+	}
+	if err := VerifyFullProof("CERT-B", proof, Checkpoint{Height: checkpoint.Height, Hash: checkpoint.Hash}); err != nil {
+		t.Fatalf("verify against checkpoint at the certificate's block: %v", err)
+	}
+
+	if err := VerifyFullProof("CERT-Z", proof, trustedTip); err == nil {
+		t.Fatal("expected a proof for another certificate to fail")
+	}
+
+	forged := proof
+	forged.CertificateID = "CERT-FORGED"
+	if err := VerifyFullProof("CERT-FORGED", forged, trustedTip); err == nil {
+		t.Fatal("expected a forged certificate to fail the Merkle proof")
+	}
+
+	if err := VerifyFullProof("CERT-B", proof, Checkpoint{Height: tip.Height, Hash: checkpoint.Hash}); err == nil {
+		t.Fatal("expected a checkpoint hash mismatch to fail")
+	}
+	if err := VerifyFullProof("CERT-B", proof, Checkpoint{Height: tip.Height + 1, Hash: tip.Hash}); err == nil {
+		t.Fatal("expected a checkpoint beyond the proof to fail")
+	}
+
+	broken := proof
+	broken.Path = []*BlockHeader{proof.Path[1]}
+	if err := VerifyFullProof("CERT-B", broken, trustedTip); err == nil {
+		t.Fatal("expected a gap in the header path to fail")
+	}
+
+	tampered := proof
+	header := *proof.Path[0]
+	header.MerkleRoot = proof.Header.MerkleRoot
+	tampered.Path = []*BlockHeader{&header, proof.Path[1]}
+	if err := VerifyFullProof("CERT-B", tampered, trustedTip); err == nil {
+		t.Fatal("expected a tampered header to fail")
+	}
+}
+
+func TestFullProofUnknownCertificate(t *testing.T) {
+	chain, _ := newTestChain(t, 1)
+	if _, err := chain.FullProof("CERT-MISSING"); err == nil {
+		t.Fatal("expected an error for a certificate not on the chain")
+	}
+}
+
+func TestCertificateDocumentVerifiesOffline(t *testing.T) {
+	chain, signer := newTestChain(t, 1)
+	if _, err := chain.AddBlock([]string{"CERT-A", "CERT-B", "CERT-C"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	chainID, err := chain.ChainID()
+	if err != nil {
+		t.Fatalf("chain ID: %v", err)
+	}
+
+	doc, err := chain.CertificateDocument("CERT-B")
+	if err != nil {
+		t.Fatalf("certificate document: %v", err)
+	}
+	if doc.ChainID != chainID || doc.Header.Height != 2 {
+		t.Fatalf("expected block 2 on chain %s, got block %d on chain %s", chainID, doc.Header.Height, doc.ChainID)
+	}
+	if err := VerifyCertificateDocument(doc); err != nil {
+		t.Fatalf("verify document: %v", err)
+	}
+
+	// A block with a single certificate pairs its leaf with itself
+	single, err := chain.CertificateDocument("CERT-000")
+	if err != nil {
+		t.Fatalf("certificate document for a single-certificate block: %v", err)
+	}
+	if err := VerifyCertificateDocument(single); err != nil {
+		t.Fatalf("verify single-certificate document: %v", err)
+	}
+
+	if _, err := chain.CertificateDocument("CERT-MISSING"); err == nil {
+		t.Fatal("expected a missing certificate to fail")
+	}
+
+	forged := doc
+	forged.CertificateID = "CERT-FORGED"
+	if err := VerifyCertificateDocument(forged); err == nil {
+		t.Fatal("expected a forged certificate to fail the Merkle proof")
+	}
+
+	other := identity.NewIdentitySigner(identity.MakeIdentity())
+	rekeyed := doc
+	rekeyed.Header.PublicKey = nil
+	rekeyed.PublicKey = identity.EncodePublicKey(other.PublicKey())
+	if err := VerifyCertificateDocument(rekeyed); err == nil {
+		t.Fatal("expected another signer's public key to fail")
+	}
+
+	tampered := doc
+	tampered.Header.Timestamp++
+	if err := VerifyCertificateDocument(tampered); err == nil {
+		t.Fatal("expected a tampered header to fail")
+	}
+}
@@ -0,0 +1,144 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/gob"
+	"fmt"
+	"log"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+// BlockHeader carries everything needed to check a block's hash, signature and
+// linkage without its certificate list. The certificates are committed to by
+// CertificatesDigest and, for inclusion proofs, MerkleRoot.
+type BlockHeader struct {
+	Version            int    `json:"version"`
+	Timestamp          int64  `json:"timestamp"`
+	Hash               []byte `json:"hash"`
+	PrevHash           []byte `json:"prev_hash"`
+	Height             int    `json:"height"`
+	CertificatesDigest []byte `json:"certificates_digest"`
+	CertificateCount   int    `json:"certificate_count"`
+	MerkleRoot         []byte `json:"merkle_root"`
+	Signature          []byte `json:"signature"`
+	UniversityAddress  []byte `json:"university_address"`
+	PublicKey          []byte `json:"public_key,omitempty"`
+}
+
+// Header returns the block's header
+func (b *Block) Header() *BlockHeader {
+	return &BlockHeader{
+		Version:            b.Version,
+		Timestamp:          b.Timestamp,
+		Hash:               b.Hash,
+		PrevHash:           b.PrevHash,
+		Height:             b.Height,
+		CertificatesDigest: b.CertificatesDigest(),
+		CertificateCount:   len(b.CertificateHashes),
+		MerkleRoot:         b.MerkleRoot,
+		Signature:          b.Signature,
+		UniversityAddress:  b.UniversityAddress,
+		PublicKey:          b.PublicKey,
+	}
+}
+
+// SerializeHeader gob-encodes the block's header
+func (b *Block) SerializeHeader() []byte {
+	return b.Header().Serialize()
+}
+
+func (h *BlockHeader) Serialize() []byte {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(h); err != nil {
+		log.Panic(err)
+	}
+	return buffer.Bytes()
+}
+
+// DeserializeHeader decodes a header produced by SerializeHeader. Headers usually
+// come from peers, so malformed input is reported rather than panicking.
+func DeserializeHeader(data []byte) (*BlockHeader, error) {
+	var header BlockHeader
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&header); err != nil {
+		return nil, fmt.Errorf("failed to decode block header: %v", err)
+	}
+	return &header, nil
+}
+
+// headerFields lays out the fields hashed and signed by version 2+ blocks
+func headerFields(prevHash, certificatesDigest, merkleRoot []byte, timestamp int64, height int) [][]byte {
+	return [][]byte{
+		prevHash,
+		certificatesDigest,
+		merkleRoot,
+		ToHex(timestamp),
+		ToHex(int64(height)),
+	}
+}
+
+func (h *BlockHeader) hashedFields() [][]byte {
+	return headerFields(h.PrevHash, h.CertificatesDigest, h.MerkleRoot, h.Timestamp, h.Height)
+}
+
+// CalculateHash recomputes the block hash from the header
+func (h *BlockHeader) CalculateHash() []byte {
+	return blockHash(h.Version, h.hashedFields(), h.Signature)
+}
+
+// CalculateHashForSigning recomputes the digest the block's signer signed
+func (h *BlockHeader) CalculateHashForSigning() []byte {
+	return signingHash(h.Version, h.hashedFields())
+}
+
+// Verify verifies the block's signature from the header using the provided public key
+func (h *BlockHeader) Verify(publicKey ecdsa.PublicKey) bool {
+	if len(h.Signature) == 0 || h.Version < 2 {
+		return false
+	}
+	return identity.VerifySignature(publicKey, h.CalculateHashForSigning(), h.Signature)
+}
+
+// Validate checks that the header's hash matches its contents and that its
+// recorded public key signed it. Blocks older than version 2 sign their full
+// certificate list and need the whole block.
+func (h *BlockHeader) Validate() error {
+	if h.Version < 2 {
+		return fmt.Errorf("block %d has version %d; headers can only be validated for version 2+ blocks", h.Height, h.Version)
+	}
+	if h.Height < 0 {
+		return fmt.Errorf("invalid block height: %d", h.Height)
+	}
+	if calculated := h.CalculateHash(); !bytes.Equal(h.Hash, calculated) {
+		return fmt.Errorf("invalid header hash at height %d: expected %x, got %x", h.Height, calculated, h.Hash)
+	}
+	if len(h.PublicKey) == 0 {
+		return fmt.Errorf("header at height %d records no public key to verify its signature", h.Height)
+	}
+	if err := checkSignerKey(h.PublicKey, h.UniversityAddress, h.CalculateHashForSigning(), h.Signature); err != nil {
+		return fmt.Errorf("header at height %d: %v", h.Height, err)
+	}
+	return nil
+}
+
+// ValidateHeaders validates each header and checks that consecutive headers,
+// ordered by ascending height, link to each other
+func ValidateHeaders(headers []*BlockHeader) error {
+	for i, header := range headers {
+		if err := header.Validate(); err != nil {
+			return err
+		}
+		if i == 0 {
+			continue
+		}
+		prev := headers[i-1]
+		if header.Height != prev.Height+1 {
+			return fmt.Errorf("header at height %d follows height %d", header.Height, prev.Height)
+		}
+		if !bytes.Equal(header.PrevHash, prev.Hash) {
+			return fmt.Errorf("header at height %d does not link to the previous header", header.Height)
+		}
+	}
+	return nil
+}
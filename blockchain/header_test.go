@@ -0,0 +1,133 @@
+package blockchain
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+func TestHeaderSerializationRoundTrip(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	block := NewBlock([]string{"CERT-001", "CERT-002"}, []byte("prev"), 4, signer)
+
+	header, err := DeserializeHeader(block.SerializeHeader())
+	if err != nil {
+		t.Fatalf("deserialize: %v", err)
+	}
+	if !reflect.DeepEqual(header, block.Header()) {
+		t.Fatalf("round trip changed the header:\n got %+v\nwant %+v", header, block.Header())
+	}
+	if header.CertificateCount != 2 {
+		t.Fatalf("expected certificate count 2, got %d", header.CertificateCount)
+	}
+	if len(block.SerializeHeader()) >= len(block.Serialize()) {
+		t.Fatal("header is not smaller than the full block")
+	}
+
+	if _, err := DeserializeHeader([]byte("not a header")); err == nil {
+		t.Fatal("expected an error decoding garbage")
+	}
+}
+
+func TestHeaderVerifiesSignature(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	other := identity.NewIdentitySigner(identity.MakeIdentity())
+	block := NewBlock([]string{"CERT-001"}, []byte{}, 0, signer)
+
+	header, err := DeserializeHeader(block.SerializeHeader())
+	if err != nil {
+		t.Fatalf("deserialize: %v", err)
+	}
+	if err := header.Validate(); err != nil {
+		t.Fatalf("header failed validation: %v", err)
+	}
+	if !bytes.Equal(header.CalculateHash(), block.Hash) {
+		t.Fatal("header hash differs from block hash")
+	}
+	if !header.Verify(signer.PublicKey()) {
+		t.Fatal("header signature failed to verify")
+	}
+	if header.Verify(other.PublicKey()) {
+		t.Fatal("header verified under the wrong key")
+	}
+
+	// Swapping the certificate commitment breaks both the hash and the signature
+	tampered := *header
+	tampered.CertificatesDigest = NewBlock([]string{"CERT-FORGED"}, []byte{}, 0, signer).CertificatesDigest()
+	if tampered.Verify(signer.PublicKey()) {
+		t.Fatal("tampered header verified")
+	}
+	if err := tampered.Validate(); err == nil {
+		t.Fatal("tampered header validated")
+	}
+}
+
+func TestValidateHeadersChecksLinkage(t *testing.T) {
+	chain, _ := newTestChain(t, 4)
+
+	headers, err := chain.HeadersInRange(0, 100)
+	if err != nil {
+		t.Fatalf("headers: %v", err)
+	}
+	if len(headers) != 5 {
+		t.Fatalf("expected 5 headers clamped to the tip, got %d", len(headers))
+	}
+	if err := ValidateHeaders(headers); err != nil {
+		t.Fatalf("valid headers rejected: %v", err)
+	}
+
+	if err := ValidateHeaders([]*BlockHeader{headers[0], headers[2]}); err == nil {
+		t.Fatal("expected a gap in heights to be rejected")
+	}
+	if _, err := chain.HeadersInRange(3, 1); err == nil {
+		t.Fatal("expected an inverted range to be rejected")
+	}
+}
+
+func TestVersionOneBlockStillValidates(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	block := NewBlock([]string{"CERT-001"}, []byte{}, 0, signer)
+
+	// Re-sign as a version 1 block, which signs the full certificate list
+	block.Version = 1
+	if err := block.SignWithSigner(signer); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	block.Hash = block.CalculateHash()
+
+	if !block.Verify(signer.PublicKey()) {
+		t.Fatal("version 1 block failed to verify")
+	}
+	if err := block.Validate(); err != nil {
+		t.Fatalf("version 1 block failed validation: %v", err)
+	}
+	if err := block.Header().Validate(); err == nil {
+		t.Fatal("expected header validation to require version 2")
+	}
+}
+
+func TestStrippedPublicKeyRejected(t *testing.T) {
+	chain, signer := newTestChain(t, 0)
+	tip, err := chain.Tip()
+	if err != nil {
+		t.Fatalf("tip: %v", err)
+	}
+	block := NewBlock([]string{"CERT-001"}, tip.Hash, tip.Height+1, signer)
+	// The public key is not hashed, so stripping it leaves the hash intact
+	block.PublicKey = nil
+	if !bytes.Equal(block.Hash, block.CalculateHash()) {
+		t.Fatal("expected the hash to be unchanged")
+	}
+
+	if err := block.Validate(); err == nil {
+		t.Fatal("block without a public key validated")
+	}
+	if err := block.Header().Validate(); err == nil {
+		t.Fatal("header without a public key validated")
+	}
+	if err := chain.ImportBlock(block); err == nil {
+		t.Fatal("block without a public key was imported")
+	}
+}
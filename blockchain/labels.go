@@ -0,0 +1,84 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// LabelSeparator separates a label's key from its value in a key:value
+// selector, so label keys may not contain it
+const LabelSeparator = ":"
+
+// ValidateLabels checks that every label key is non-empty and free of LabelSeparator
+func ValidateLabels(labels map[string]string) error {
+	for key := range labels {
+		if key == "" {
+			return fmt.Errorf("label key cannot be empty")
+		}
+		if strings.Contains(key, LabelSeparator) {
+			return fmt.Errorf("label key %q cannot contain %q", key, LabelSeparator)
+		}
+	}
+	return nil
+}
+
+// HasLabel reports whether the block carries the label key with the given value
+func (b *Block) HasLabel(key, value string) bool {
+	got, ok := b.Labels[key]
+	return ok && got == value
+}
+
+// ErrBlockNotFound is returned by SetBlockLabels when no block has the given hash
+var ErrBlockNotFound = errors.New("block not found")
+
+// SetBlockLabels replaces the labels of the block with the given hash and
+// returns the updated block. Labels are not part of the hash chain, so this
+// rewrites the stored block without changing its hash, signature or validity.
+// Empty labels remove them.
+func (bc *Blockchain) SetBlockLabels(hash []byte, labels map[string]string) (*Block, error) {
+	if err := ValidateLabels(labels); err != nil {
+		return nil, err
+	}
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	var labeled *Block
+	err := bc.Database.Update(func(txn *badger.Txn) error {
+		item, err := txn.Get(hash)
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return fmt.Errorf("%w: %x", ErrBlockNotFound, hash)
+		} else if err != nil {
+			return err
+		}
+		data, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+		// Other records share the keyspace; only a block stored under its own hash is labeled
+		var block Block
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&block); err != nil || !bytes.Equal(block.Hash, hash) {
+			return fmt.Errorf("%w: %x", ErrBlockNotFound, hash)
+		}
+		block.Labels = nil
+		if len(labels) > 0 {
+			block.Labels = maps.Clone(labels)
+		}
+		labeled = &block
+		return txn.Set(hash, block.Serialize())
+	})
+	if errors.Is(err, ErrBlockNotFound) {
+		return nil, err
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to save block labels: %v", err)
+	}
+	// Readers may hold the previously cached block, so it is replaced rather than changed
+	bc.cache.add(labeled)
+	return labeled, nil
+}
@@ -0,0 +1,62 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestBlockLabelsDoNotAffectHash(t *testing.T) {
+	chain, signer := newTestChain(t, 2)
+	block, err := chain.AddBlock([]string{"CERT-A", "CERT-B"}, signer)
+	if err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	labels := map[string]string{"batch": "2024-spring", "source": "registrar"}
+
+	labeled, err := chain.SetBlockLabels(block.Hash, labels)
+	if err != nil {
+		t.Fatalf("set labels: %v", err)
+	}
+	if !bytes.Equal(labeled.CalculateHash(), block.Hash) || !bytes.Equal(labeled.CalculateHashForSigning(), block.CalculateHashForSigning()) {
+		t.Fatal("labels changed the block hash")
+	}
+	labels["batch"] = "changed"
+	if !labeled.HasLabel("batch", "2024-spring") {
+		t.Fatal("labels were not copied")
+	}
+
+	// Labels round-trip through storage and the serialized form
+	chain.SetBlockCacheSize(0)
+	stored, err := chain.GetBlockByHash(block.Hash)
+	if err != nil {
+		t.Fatalf("get block: %v", err)
+	}
+	if !stored.HasLabel("batch", "2024-spring") || !stored.HasLabel("source", "registrar") {
+		t.Fatalf("labels lost in storage: %v", stored.Labels)
+	}
+	if decoded := Deserialize(stored.Serialize()); !decoded.HasLabel("source", "registrar") {
+		t.Fatalf("labels lost in serialization: %v", decoded.Labels)
+	}
+	if err := stored.Validate(); err != nil {
+		t.Fatalf("labeled block invalid: %v", err)
+	}
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("chain invalid after labeling: %v", err)
+	}
+
+	cleared, err := chain.SetBlockLabels(block.Hash, nil)
+	if err != nil {
+		t.Fatalf("clear labels: %v", err)
+	}
+	if cleared.Labels != nil {
+		t.Fatalf("expected labels cleared, got %v", cleared.Labels)
+	}
+
+	if _, err := chain.SetBlockLabels(block.Hash, map[string]string{"a:b": "c"}); err == nil {
+		t.Fatal("expected a key containing the separator to be rejected")
+	}
+	if _, err := chain.SetBlockLabels([]byte("lh"), labels); !errors.Is(err, ErrBlockNotFound) {
+		t.Fatalf("expected ErrBlockNotFound for a non-block key, got %v", err)
+	}
+}
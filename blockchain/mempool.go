@@ -0,0 +1,99 @@
+package blockchain
+
+import (
+	"sync"
+	"time"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+// Mempool accumulates pending certificate IDs so they can be written to the
+// chain as one block rather than one block per request. Pending IDs are held
+// in memory only and are lost if the process exits before they are flushed.
+type Mempool struct {
+	chain *Blockchain
+
+	mu      sync.Mutex
+	pending []string
+	queued  map[string]bool
+}
+
+// NewMempool creates an empty mempool that flushes into chain
+func NewMempool(chain *Blockchain) *Mempool {
+	return &Mempool{chain: chain, queued: make(map[string]bool)}
+}
+
+// AddPending validates ids and queues them for the next flush. IDs already
+// pending are ignored. It returns the number of IDs pending afterwards.
+func (m *Mempool) AddPending(ids ...string) (int, error) {
+	if err := ValidateCertificateIDs(ids); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		if m.queued[id] {
+			continue
+		}
+		m.queued[id] = true
+		m.pending = append(m.pending, id)
+	}
+	return len(m.pending), nil
+}
+
+// Pending returns a copy of the pending certificate IDs in the order they were added
+func (m *Mempool) Pending() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.pending...)
+}
+
+// Flush writes every pending certificate into one block signed by signer and
+// empties the mempool. With nothing pending it does nothing and returns a nil
+// block. If the block cannot be added, for instance with ErrNotLeader while
+// another validator leads the next height, the certificates stay pending.
+func (m *Mempool) Flush(signer identity.Signer) (*Block, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.pending) == 0 {
+		return nil, nil
+	}
+	block, err := m.chain.AddBlock(m.pending, signer)
+	if err != nil {
+		return nil, err
+	}
+	m.pending = nil
+	m.queued = make(map[string]bool)
+	return block, nil
+}
+
+// StartAutoFlush flushes the mempool every interval in the background,
+// passing any flush error to onError when it is non-nil. The returned function
+// stops the timer and waits for an in-progress flush; it may be called more than once.
+func (m *Mempool) StartAutoFlush(interval time.Duration, signer identity.Signer, onError func(error)) (stop func()) {
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := m.Flush(signer); err != nil && onError != nil {
+					onError(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-exited
+	}
+}
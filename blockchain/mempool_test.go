@@ -0,0 +1,69 @@
+package blockchain
+
+import "testing"
+
+func TestMempoolFlush(t *testing.T) {
+	chain, signer := newTestChain(t, 0)
+	mempool := NewMempool(chain)
+
+	if _, err := mempool.AddPending("CERT-001", "CERT-002"); err != nil {
+		t.Fatalf("add pending: %v", err)
+	}
+	pending, err := mempool.AddPending("CERT-002", "CERT-003")
+	if err != nil {
+		t.Fatalf("add pending: %v", err)
+	}
+	if pending != 3 {
+		t.Fatalf("expected 3 pending certificates after a duplicate, got %d", pending)
+	}
+	if tip, _ := chain.Tip(); tip.Height != 0 {
+		t.Fatalf("expected no blocks before flushing, tip is at %d", tip.Height)
+	}
+
+	block, err := mempool.Flush(signer)
+	if err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if block == nil || block.Height != 1 {
+		t.Fatalf("expected a block at height 1, got %+v", block)
+	}
+	if block.GetCertificateCount() != 3 {
+		t.Fatalf("expected 3 certificates in the block, got %d", block.GetCertificateCount())
+	}
+	for _, id := range []string{"CERT-001", "CERT-002", "CERT-003"} {
+		if !block.VerifyCertificate(id) {
+			t.Fatalf("expected %s in the flushed block", id)
+		}
+	}
+	if len(mempool.Pending()) != 0 {
+		t.Fatalf("expected the mempool to be empty after flushing, got %v", mempool.Pending())
+	}
+}
+
+func TestMempoolEmptyFlush(t *testing.T) {
+	chain, signer := newTestChain(t, 0)
+	mempool := NewMempool(chain)
+
+	block, err := mempool.Flush(signer)
+	if err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if block != nil {
+		t.Fatalf("expected no block from an empty flush, got height %d", block.Height)
+	}
+	if tip, _ := chain.Tip(); tip.Height != 0 {
+		t.Fatalf("expected an empty flush to leave the tip at 0, got %d", tip.Height)
+	}
+}
+
+func TestMempoolRejectsInvalidID(t *testing.T) {
+	chain, _ := newTestChain(t, 0)
+	mempool := NewMempool(chain)
+
+	if _, err := mempool.AddPending("CERT-001", "CERT,002"); err == nil {
+		t.Fatal("expected an invalid certificate ID to be rejected")
+	}
+	if len(mempool.Pending()) != 0 {
+		t.Fatalf("expected nothing queued from a rejected batch, got %v", mempool.Pending())
+	}
+}
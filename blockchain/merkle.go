@@ -20,6 +20,16 @@ type MerkleProof struct {
 	Directions []bool
 }
 
+// emptyMerkleLeaf is hashed to form the Merkle root of a block without
+// certificates, such as a genesis block without a payload
+var emptyMerkleLeaf = []byte("GENESIS")
+
+// EmptyMerkleRoot returns the Merkle root of a block with no certificates
+func EmptyMerkleRoot() []byte {
+	hash := sha256.Sum256(emptyMerkleLeaf)
+	return hash[:]
+}
+
 func NewMerkleNode(left, right *MerkleNode, data []byte) *MerkleNode {
 	node := &MerkleNode{}
 
@@ -42,9 +52,7 @@ func NewMerkleTree(certificateIDs []string) *MerkleTree {
 	var nodes []MerkleNode
 
 	if len(certificateIDs) == 0 {
-		// For empty certificate lists (like genesis block), create a single node with empty data
-		emptyNode := NewMerkleNode(nil, nil, []byte("GENESIS"))
-		return &MerkleTree{Root: emptyNode}
+		return &MerkleTree{Root: &MerkleNode{Data: EmptyMerkleRoot()}}
 	}
 
 	if len(certificateIDs)%2 != 0 {
@@ -72,6 +80,34 @@ func NewMerkleTree(certificateIDs []string) *MerkleTree {
 	return &MerkleTree{&nodes[0]}
 }
 
+// MerkleLevels returns the levels of the Merkle tree over leaves, from the
+// leaves up to the root, the same tree NewMerkleTree builds. Levels are not
+// padded: a level with an odd number of nodes has its last node paired with
+// itself to form the level above.
+func MerkleLevels(leaves [][]byte) [][][]byte {
+	if len(leaves) == 0 {
+		return [][][]byte{{EmptyMerkleRoot()}}
+	}
+	level := make([][]byte, len(leaves))
+	copy(level, leaves)
+
+	levels := [][][]byte{level}
+	for len(level) > 1 {
+		var next [][]byte
+		for i := 0; i < len(level); i += 2 {
+			left, right := level[i], level[i]
+			if i+1 < len(level) {
+				right = level[i+1]
+			}
+			parent := sha256.Sum256(append(append([]byte{}, left...), right...))
+			next = append(next, parent[:])
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels
+}
+
 func GenerateProof(leaves [][]byte, leafIndex int) MerkleProof {
 	if len(leaves) == 0 || leafIndex < 0 || leafIndex >= len(leaves) {
 		return MerkleProof{}
@@ -110,7 +146,12 @@ func GenerateProof(leaves [][]byte, leafIndex int) MerkleProof {
 	return MerkleProof{Siblings: siblings, Directions: dirs}
 }
 
+// VerifyProof reports whether proof places leafData under root. A malformed
+// proof, with a direction missing for some sibling, does not verify.
 func VerifyProof(leafData []byte, proof MerkleProof, root []byte) bool {
+	if len(proof.Siblings) != len(proof.Directions) {
+		return false
+	}
 	h := sha256.Sum256(leafData)
 	curr := h[:]
 	for i := range proof.Siblings {
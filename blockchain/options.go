@@ -0,0 +1,124 @@
+package blockchain
+
+import (
+	"time"
+
+	"github.com/amanechibana/veritas-chain/identity"
+	"github.com/dgraph-io/badger/v4"
+)
+
+// BlockchainOptions tunes how the underlying Badger database is opened and,
+// for a new chain, what its genesis block records
+type BlockchainOptions struct {
+	// InMemory keeps the whole database in memory and ignores the path.
+	// In-memory chains can only be created with InitBlockchain.
+	InMemory bool
+
+	// SyncWrites fsyncs every write before it is acknowledged, trading
+	// throughput for durability across crashes.
+	SyncWrites bool
+
+	// Logger receives Badger's log output. Nil keeps Badger's default
+	// logger; use NopLogger to silence it.
+	Logger badger.Logger
+
+	// ValueLogFileSize is the maximum size in bytes of each value log file.
+	// Zero keeps Badger's default.
+	ValueLogFileSize int64
+
+	// GenesisPayload is recorded in the genesis block of a newly created
+	// chain and hashed into its Merkle root, e.g. to anchor a hash of the
+	// authorized-signer set. Entries must be valid certificate IDs. It is
+	// ignored when an existing chain is loaded.
+	GenesisPayload []string
+
+	// RecordEquivocations stores evidence when ImportBlock receives a valid
+	// block at a height already occupied by a different block (see
+	// Equivocations). The block is rejected either way.
+	RecordEquivocations bool
+
+	// BlockCacheSize is how many recently used blocks are kept deserialized
+	// in memory. Zero uses DefaultBlockCacheSize; a negative size disables
+	// the cache.
+	BlockCacheSize int
+
+	// BlockCacheTTL drops cached blocks that have not been used for this
+	// long. A background sweeper removes them every BlockCacheTTL until the
+	// chain is closed. Zero keeps blocks until the cache is full.
+	BlockCacheTTL time.Duration
+
+	// MinBlockInterval, when non-zero, makes AddBlock reject a block created
+	// less than this long after the tip's timestamp with ErrBlockTooSoon.
+	// Imported blocks are not subject to it.
+	MinBlockInterval time.Duration
+
+	// SortCertificates makes AddBlock order each block's certificates by hash
+	// so the same batch yields the same Merkle root whatever order it was
+	// submitted in. Such blocks are flagged with SortedCertificates.
+	SortCertificates bool
+
+	// Validators, when non-empty, makes AddBlock write a block only at the
+	// heights its signer leads by LeaderForHeight and return ErrNotLeader at
+	// the others, so several authorized nodes take turns proposing blocks.
+	// Imported blocks are not subject to it. Nodes do not yet relay blocks to
+	// each other, so a caller setting it must import the other validators'
+	// blocks itself or the chain stalls at the first height another leads;
+	// node start does not offer it until blocks are propagated.
+	Validators identity.AuthorizedSigners
+
+	// CertificateFilterFPRate is the false-positive rate the certificate
+	// Bloom filter behind CertificateExists is sized for. A lower rate costs
+	// memory and disk space. Zero uses DefaultCertificateFilterFPRate.
+	CertificateFilterFPRate float64
+
+	// GenesisSigner, when set, is an address FullyValidate accepts as the
+	// genesis block's signer even if it is not in the authorized-signer set,
+	// for chains bootstrapped with a dedicated key. Later blocks must still be
+	// signed by an authorized signer.
+	GenesisSigner string
+}
+
+// DefaultBlockchainOptions returns on-disk options with Badger's defaults
+func DefaultBlockchainOptions() BlockchainOptions {
+	return BlockchainOptions{}
+}
+
+// blockCacheSize returns the configured block cache size
+func (o BlockchainOptions) blockCacheSize() int {
+	switch {
+	case o.BlockCacheSize == 0:
+		return DefaultBlockCacheSize
+	case o.BlockCacheSize < 0:
+		return 0
+	}
+	return o.BlockCacheSize
+}
+
+// badgerOptions translates the options into Badger options for dbPath
+func (o BlockchainOptions) badgerOptions(dbPath string) badger.Options {
+	opts := badger.DefaultOptions(dbPath)
+	if o.InMemory {
+		opts = badger.DefaultOptions("").WithInMemory(true)
+	}
+	opts = opts.WithSyncWrites(o.SyncWrites)
+	if o.Logger != nil {
+		opts = opts.WithLogger(o.Logger)
+	}
+	if o.ValueLogFileSize > 0 {
+		opts = opts.WithValueLogFileSize(o.ValueLogFileSize)
+	}
+	return opts
+}
+
+// nopLogger discards all Badger log output
+type nopLogger struct{}
+
+func (nopLogger) Errorf(string, ...interface{})   {}
+func (nopLogger) Warningf(string, ...interface{}) {}
+func (nopLogger) Infof(string, ...interface{})    {}
+func (nopLogger) Debugf(string, ...interface{})   {}
+
+// NopLogger returns a Badger logger that discards all output
+func NopLogger() badger.Logger {
+	return nopLogger{}
+}
@@ -0,0 +1,162 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// errIndexDisagrees reports that the height index does not describe the
+// chain reached by following PrevHash from the tip
+var errIndexDisagrees = errors.New("height index disagrees with the chain")
+
+// ValidateChainParallel is ValidateChain with blocks read from disk and
+// decoded by up to concurrency goroutines ahead of the checks, which still run
+// one block at a time in height order. At most about twice concurrency blocks
+// are held in memory. Blocks are found through the height index, and the first
+// block that fails a check is reported. A missing or stale index entry does not
+// make the chain invalid, so when the index disagrees with the chain's links
+// the chain is validated sequentially instead, reading the same number of
+// blocks at a time.
+func (bc *Blockchain) ValidateChainParallel(concurrency int) error {
+	if concurrency <= 0 {
+		return fmt.Errorf("invalid validation concurrency %d: must be positive", concurrency)
+	}
+	defer validateChainDuration.ObserveDuration(time.Now())
+	err := bc.validateChainPrefetched(concurrency)
+	if errors.Is(err, errIndexDisagrees) {
+		return bc.validateChain(nil, 2*concurrency)
+	}
+	return err
+}
+
+// validateChainPrefetched runs ValidateChain's checks on the blocks the height
+// index lists, failing at the first bad block, or with errIndexDisagrees when
+// the index does not match the chain's links
+func (bc *Blockchain) validateChainPrefetched(concurrency int) error {
+	tipHash := bc.lastHash()
+	if len(tipHash) == 0 {
+		return fmt.Errorf("blockchain is empty")
+	}
+	tip, err := bc.readBlockFromDisk(tipHash)
+	if err != nil {
+		return err
+	}
+	hashes, err := bc.indexedHashes(tip.Height)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errIndexDisagrees, err)
+	}
+	if !bytes.Equal(hashes[tip.Height], tipHash) {
+		return fmt.Errorf("%w: it does not lead to the tip", errIndexDisagrees)
+	}
+
+	done := make(chan struct{})
+	futures := bc.prefetchBlocks(hashes, concurrency, done)
+	defer func() {
+		close(done)
+		for range futures {
+		}
+	}()
+
+	// The first failure is only reported once every later block has been
+	// seen to link to the one indexed below it: ValidateChain follows PrevHash
+	// from the tip, so the blocks checked here are the same only while the
+	// links agree with the index all the way up
+	var (
+		prev    *Block
+		failure error
+	)
+	height := 0
+	for future := range futures {
+		result := <-future
+		if height > 0 && result.block != nil && !bytes.Equal(result.block.PrevHash, hashes[height-1]) {
+			return fmt.Errorf("%w: block %d does not link to the block indexed below it", errIndexDisagrees, height)
+		}
+		if failure == nil {
+			switch {
+			case result.err != nil:
+				failure = result.err
+			case height == 0:
+				failure = validateGenesis(result.block, bc.sigs)
+			default:
+				failure = validateSuccessor(result.block, prev, height, bc.sigs)
+			}
+		}
+		prev = result.block
+		height++
+	}
+	if failure != nil {
+		return failure
+	}
+	if !bytes.Equal(tipHash, prev.Hash) {
+		return fmt.Errorf("LastHash mismatch: expected %x, got %x", prev.Hash, tipHash)
+	}
+	return nil
+}
+
+// indexedHashes returns the hashes the height index records for heights 0 to tipHeight
+func (bc *Blockchain) indexedHashes(tipHeight int) ([][]byte, error) {
+	hashes := make([][]byte, tipHeight+1)
+	err := bc.Database.View(func(txn *badger.Txn) error {
+		for height := range hashes {
+			item, err := txn.Get(heightKey(height))
+			if err != nil {
+				return fmt.Errorf("no block indexed at height %d: %v", height, err)
+			}
+			if hashes[height], err = item.ValueCopy(nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return hashes, err
+}
+
+// prefetchedBlock is the result of reading one block ahead of the checks
+type prefetchedBlock struct {
+	block *Block
+	err   error
+}
+
+// prefetchBlocks reads the blocks stored under hashes with up to concurrency
+// reads in flight, delivering a future per block in the order of hashes. The
+// channel is closed once every block is delivered or done is closed, and only
+// after all reads have finished, so the caller can drain it before closing the
+// database.
+func (bc *Blockchain) prefetchBlocks(hashes [][]byte, concurrency int, done <-chan struct{}) <-chan chan prefetchedBlock {
+	futures := make(chan chan prefetchedBlock, concurrency)
+	go func() {
+		var reads sync.WaitGroup
+		defer func() {
+			reads.Wait()
+			close(futures)
+		}()
+
+		slots := make(chan struct{}, concurrency)
+		for _, hash := range hashes {
+			select {
+			case slots <- struct{}{}:
+			case <-done:
+				return
+			}
+			future := make(chan prefetchedBlock, 1)
+			reads.Add(1)
+			go func() {
+				defer reads.Done()
+				defer func() { <-slots }()
+				block, err := bc.readBlockFromDisk(hash)
+				future <- prefetchedBlock{block: block, err: err}
+			}()
+			select {
+			case futures <- future:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return futures
+}
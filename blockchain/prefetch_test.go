@@ -0,0 +1,105 @@
+package blockchain
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// checkParallelMatchesSequential fails unless ValidateChainParallel gives
+// ValidateChain's result at every concurrency
+func checkParallelMatchesSequential(t *testing.T, chain *Blockchain) error {
+	t.Helper()
+	want := chain.ValidateChain()
+	for _, concurrency := range []int{1, 2, 3, 8, 100} {
+		got := chain.ValidateChainParallel(concurrency)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("concurrency %d: expected %v, got %v", concurrency, want, got)
+		}
+	}
+	return want
+}
+
+func TestValidateChainParallelMatchesSequential(t *testing.T) {
+	chain, _ := newTestChain(t, 9)
+	if err := checkParallelMatchesSequential(t, chain); err != nil {
+		t.Fatalf("valid chain failed validation: %v", err)
+	}
+	if err := chain.ValidateChainParallel(0); err == nil {
+		t.Fatal("expected a zero concurrency to be rejected")
+	}
+
+	// A height index entry pointing at the wrong block does not make the chain
+	// invalid, so parallel validation must not report it either
+	block2, _ := chain.GetBlockByHeight(2)
+	err := chain.Database.Update(func(txn *badger.Txn) error {
+		return txn.Set(heightKey(6), block2.Hash)
+	})
+	if err != nil {
+		t.Fatalf("corrupt index: %v", err)
+	}
+	if err := checkParallelMatchesSequential(t, chain); err != nil {
+		t.Fatalf("chain with a stale index entry failed validation: %v", err)
+	}
+	err = chain.Database.Update(func(txn *badger.Txn) error {
+		return txn.Delete(heightKey(3))
+	})
+	if err != nil {
+		t.Fatalf("corrupt index: %v", err)
+	}
+	if err := checkParallelMatchesSequential(t, chain); err != nil {
+		t.Fatalf("chain with a missing index entry failed validation: %v", err)
+	}
+	// Nor does a stale genesis entry, although the block it points to fails
+	// the genesis checks
+	err = chain.Database.Update(func(txn *badger.Txn) error {
+		return txn.Set(heightKey(0), block2.Hash)
+	})
+	if err != nil {
+		t.Fatalf("corrupt index: %v", err)
+	}
+	if err := checkParallelMatchesSequential(t, chain); err != nil {
+		t.Fatalf("chain with a stale genesis index entry failed validation: %v", err)
+	}
+	if err := chain.Reindex(nil); err != nil {
+		t.Fatalf("reindex: %v", err)
+	}
+
+	// Tamper with block 5 on disk without re-signing it; with the index intact
+	// the parallel pass itself reports it
+	block5, _ := chain.GetBlockByHeight(5)
+	tampered := *block5
+	tampered.CertificateHashes = []string{strings.Repeat("0", 64)}
+	err = chain.Database.Update(func(txn *badger.Txn) error {
+		return txn.Set(block5.Hash, tampered.Serialize())
+	})
+	if err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+	if err := checkParallelMatchesSequential(t, chain); err == nil {
+		t.Fatal("expected the tampered chain to fail validation")
+	}
+}
+
+func BenchmarkValidateChain(b *testing.B) {
+	chain, _ := newTestChain(b, 500)
+
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := chain.ValidateChain(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	for _, concurrency := range []int{2, 4, 8} {
+		b.Run(fmt.Sprintf("parallel=%d", concurrency), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := chain.ValidateChainParallel(concurrency); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
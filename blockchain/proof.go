@@ -3,6 +3,10 @@ package blockchain
 import (
 	"crypto/ecdsa"
 	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/amanechibana/veritas-chain/identity"
 )
 
 type ProofOfAuthority struct {
@@ -26,3 +30,28 @@ func (p *ProofOfAuthority) Run() error {
 	}
 	return nil
 }
+
+// ErrNotLeader is returned by AddBlock when Validators is set and another
+// validator leads the next height
+var ErrNotLeader = errors.New("this node is not the leader for the next height")
+
+// LeaderForHeight returns the address of the validator that proposes the block
+// at height. Validators take turns in order of address, so every node with
+// the same validator set agrees on the leader without coordinating, and over
+// any run of len(validators) heights each leads exactly once. An address
+// listed under several names takes a single turn.
+func LeaderForHeight(height int, validators identity.AuthorizedSigners) (string, error) {
+	if height < 0 {
+		return "", fmt.Errorf("invalid height %d", height)
+	}
+	var addresses []string
+	for _, address := range validators {
+		addresses = append(addresses, address)
+	}
+	slices.Sort(addresses)
+	addresses = slices.Compact(addresses)
+	if len(addresses) == 0 {
+		return "", fmt.Errorf("no validators to elect a leader from")
+	}
+	return addresses[height%len(addresses)], nil
+}
@@ -0,0 +1,98 @@
+package blockchain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+func TestLeaderForHeightRotatesFairly(t *testing.T) {
+	validators := identity.AuthorizedSigners{}
+	for _, name := range []string{"harvard", "mit", "stanford", "yale"} {
+		validators[name] = string(identity.MakeIdentity().Address())
+	}
+	// The same address under a second name gets no extra turns
+	validators["mit-alias"] = validators["mit"]
+
+	led := make(map[string]int)
+	for height := 0; height < 40; height++ {
+		leader, err := LeaderForHeight(height, validators)
+		if err != nil {
+			t.Fatalf("LeaderForHeight(%d): %v", height, err)
+		}
+		led[leader]++
+
+		// Every node computes the same leader, whatever its map iteration order
+		again, _ := LeaderForHeight(height, validators)
+		if again != leader {
+			t.Fatalf("height %d: leader %s, then %s", height, leader, again)
+		}
+		if height > 0 {
+			if prev, _ := LeaderForHeight(height-1, validators); prev == leader {
+				t.Fatalf("%s leads consecutive heights %d and %d", leader, height-1, height)
+			}
+		}
+	}
+	if len(led) != 4 {
+		t.Fatalf("expected 4 distinct leaders, got %d", len(led))
+	}
+	for leader, count := range led {
+		if count != 10 {
+			t.Errorf("%s led %d of 40 heights, want 10", leader, count)
+		}
+	}
+
+	if _, err := LeaderForHeight(0, nil); err == nil {
+		t.Errorf("expected an error without validators")
+	}
+}
+
+func TestNonLeaderDefers(t *testing.T) {
+	first := identity.NewIdentitySigner(identity.MakeIdentity())
+	second := identity.NewIdentitySigner(identity.MakeIdentity())
+	validators := identity.AuthorizedSigners{
+		"first":  string(first.Address()),
+		"second": string(second.Address()),
+	}
+	options := testChainOptions
+	options.Validators = validators
+	chain := InitBlockchain("", first, options)
+	t.Cleanup(func() { chain.Close() })
+
+	for height := 1; height <= 4; height++ {
+		leaderAddress, err := LeaderForHeight(height, validators)
+		if err != nil {
+			t.Fatalf("LeaderForHeight: %v", err)
+		}
+		leader, follower := first, second
+		if leaderAddress != string(first.Address()) {
+			leader, follower = second, first
+		}
+
+		if _, err := chain.AddBlock([]string{"DEFERRED"}, follower); !errors.Is(err, ErrNotLeader) {
+			t.Fatalf("height %d: expected ErrNotLeader for the non-leader, got %v", height, err)
+		}
+		pool := NewMempool(chain)
+		if _, err := pool.AddPending("QUEUED"); err != nil {
+			t.Fatalf("AddPending: %v", err)
+		}
+		if _, err := pool.Flush(follower); !errors.Is(err, ErrNotLeader) {
+			t.Fatalf("height %d: expected the non-leader's flush to defer, got %v", height, err)
+		}
+		if pending := pool.Pending(); len(pending) != 1 {
+			t.Fatalf("height %d: deferred flush left %d certificates pending, want 1", height, len(pending))
+		}
+		if tip, _ := chain.Height(); tip != height-1 {
+			t.Fatalf("non-leader moved the tip to %d", tip)
+		}
+
+		block, err := chain.AddBlock([]string{"LED"}, leader)
+		if err != nil {
+			t.Fatalf("height %d: leader's block rejected: %v", height, err)
+		}
+		if block.Height != height {
+			t.Fatalf("expected block at height %d, got %d", height, block.Height)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// ReindexProgress is called as Reindex rebuilds the indexes, with the number of
+// blocks indexed so far and the total number of blocks on the chain
+type ReindexProgress func(indexed, total int)
+
+// Reindex rebuilds every secondary index from the canonical block records,
+// walking the chain from genesis to tip. It is idempotent: entries are
+// overwritten with their canonical values and entries for heights above the
+// tip are removed. The certificate filter is rebuilt too. progress may be nil.
+func (bc *Blockchain) Reindex(progress ReindexProgress) error {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	// Collect the canonical hashes tip→genesis, then index them genesis→tip
+	var hashes [][]byte
+	for hash := bc.LastHash; len(hash) > 0; {
+		block, err := bc.GetBlockByHash(hash)
+		if err != nil {
+			return fmt.Errorf("failed to walk chain for reindex: %v", err)
+		}
+		hashes = append(hashes, block.Hash)
+		hash = block.PrevHash
+	}
+	total := len(hashes)
+
+	batch := bc.Database.NewWriteBatch()
+	defer batch.Cancel()
+	for height := 0; height < total; height++ {
+		if err := batch.Set(heightKey(height), hashes[total-1-height]); err != nil {
+			return fmt.Errorf("failed to write height index: %v", err)
+		}
+		if progress != nil {
+			progress(height+1, total)
+		}
+	}
+	if err := batch.Flush(); err != nil {
+		return fmt.Errorf("failed to write height index: %v", err)
+	}
+
+	if err := bc.dropHeightEntriesFrom(total); err != nil {
+		return fmt.Errorf("failed to prune height index: %v", err)
+	}
+	return bc.rebuildCertificateFilter()
+}
+
+// dropHeightEntriesFrom deletes height index entries at or above height
+func (bc *Blockchain) dropHeightEntriesFrom(height int) error {
+	return bc.Database.Update(func(txn *badger.Txn) error {
+		prefix := []byte("h-")
+		it := txn.NewIterator(badger.IteratorOptions{PrefetchValues: false, Prefix: prefix})
+		defer it.Close()
+
+		var stale [][]byte
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			key := it.Item().KeyCopy(nil)
+			// Block records are keyed by raw hash and may share the prefix by chance
+			if len(key) != len(heightKey(0)) {
+				continue
+			}
+			var indexed int64
+			if err := binary.Read(bytes.NewReader(key[len(prefix):]), binary.BigEndian, &indexed); err != nil {
+				return err
+			}
+			if indexed >= int64(height) {
+				stale = append(stale, key)
+			}
+		}
+		for _, key := range stale {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
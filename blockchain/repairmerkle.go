@@ -0,0 +1,154 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"slices"
+
+	"github.com/amanechibana/veritas-chain/identity"
+	"github.com/dgraph-io/badger/v4"
+)
+
+// expectedMerkleRoot recomputes, from CertificateHashes, the Merkle root that
+// GenerateCertificateProof's proofs verify against. It returns nil for blocks
+// whose certificate hashes are not SHA-256, which cannot be recomputed.
+func (b *Block) expectedMerkleRoot() ([]byte, error) {
+	if b.CertificateHashAlgorithm() != HashAlgorithmSHA256 {
+		return nil, nil
+	}
+	if len(b.CertificateHashes) == 0 {
+		return EmptyMerkleRoot(), nil
+	}
+	leaves := make([][]byte, len(b.CertificateHashes))
+	for i, h := range b.CertificateHashes {
+		leaf, err := hex.DecodeString(h)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate hash at index %d: %v", i, err)
+		}
+		leaves[i] = leaf
+	}
+	// NewMerkleTree pairs a lone certificate with itself
+	if len(leaves) == 1 {
+		leaves = append(leaves, leaves[0])
+	}
+	levels := MerkleLevels(leaves)
+	return levels[len(levels)-1][0], nil
+}
+
+// RepairMerkleRoots recomputes every block's Merkle root from its certificate
+// hashes and rewrites the blocks whose stored root differs, such as blocks
+// written with an older leaf-hashing scheme, and returns how many roots it
+// fixed. A rewritten block gets a new hash, so every block after it is
+// relinked to its rewritten predecessor too. Rewritten blocks are re-signed
+// by signer, which must be the university that signed them; nothing is
+// written if any block needing a rewrite was signed by someone else.
+func (bc *Blockchain) RepairMerkleRoots(signer identity.Signer) (int, error) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	var blocks []*Block
+	for hash := bc.LastHash; len(hash) > 0; {
+		block, err := bc.GetBlockByHash(hash)
+		if err != nil {
+			return 0, fmt.Errorf("failed to walk chain for Merkle repair: %v", err)
+		}
+		blocks = append(blocks, block)
+		hash = block.PrevHash
+	}
+	slices.Reverse(blocks)
+
+	var rewritten, replaced []*Block
+	fixed := 0
+	var prevHash []byte
+	for i, block := range blocks {
+		root, err := block.expectedMerkleRoot()
+		if err != nil {
+			return 0, fmt.Errorf("block %d: %v", block.Height, err)
+		}
+		wrongRoot := root != nil && !bytes.Equal(root, block.MerkleRoot)
+		relink := i > 0 && !bytes.Equal(block.PrevHash, prevHash)
+		if !wrongRoot && !relink {
+			prevHash = block.Hash
+			continue
+		}
+
+		if signer == nil {
+			return 0, fmt.Errorf("block %d must be re-signed, which requires a signer", block.Height)
+		}
+		if !bytes.Equal(block.UniversityAddress, signer.Address()) {
+			return 0, fmt.Errorf("block %d is signed by %s, not %s; only its signer can re-sign it", block.Height, block.UniversityAddress, signer.Address())
+		}
+		repaired := *block
+		if wrongRoot {
+			repaired.MerkleRoot = root
+			fixed++
+		}
+		if i > 0 {
+			repaired.PrevHash = prevHash
+		}
+		if len(repaired.PublicKey) > 0 {
+			repaired.PublicKey = identity.EncodePublicKey(signer.PublicKey())
+		}
+		if err := repaired.SignWithSigner(signer); err != nil {
+			return 0, fmt.Errorf("failed to re-sign block %d: %v", block.Height, err)
+		}
+		repaired.Hash = repaired.CalculateHash()
+		if err := repaired.Validate(); err != nil {
+			return 0, fmt.Errorf("repaired block %d is invalid: %v", block.Height, err)
+		}
+		rewritten = append(rewritten, &repaired)
+		replaced = append(replaced, block)
+		prevHash = repaired.Hash
+	}
+	if len(rewritten) == 0 {
+		return 0, nil
+	}
+
+	// The rewritten blocks are new records, so writing them first leaves the
+	// chain intact. The height index and the tip then switch over in a single
+	// transaction, and only after that are the replaced records removed.
+	batch := bc.Database.NewWriteBatch()
+	defer batch.Cancel()
+	for _, block := range rewritten {
+		if err := batch.Set(block.Hash, block.Serialize()); err != nil {
+			return 0, fmt.Errorf("failed to write repaired block %d: %v", block.Height, err)
+		}
+	}
+	if err := batch.Flush(); err != nil {
+		return 0, fmt.Errorf("failed to write repaired blocks: %v", err)
+	}
+	err := bc.Database.Update(func(txn *badger.Txn) error {
+		for _, block := range rewritten {
+			if err := txn.Set(heightKey(block.Height), block.Hash); err != nil {
+				return fmt.Errorf("failed to write height index: %v", err)
+			}
+		}
+		if err := txn.Set([]byte("lh"), prevHash); err != nil {
+			return fmt.Errorf("failed to write chain tip: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	bc.LastHash = prevHash
+	bc.cache.clear()
+	bc.sigs.clear()
+	if err := bc.rebuildCertificateFilter(); err != nil {
+		return fixed, err
+	}
+
+	cleanup := bc.Database.NewWriteBatch()
+	defer cleanup.Cancel()
+	for _, block := range replaced {
+		if err := cleanup.Delete(block.Hash); err != nil {
+			return fixed, fmt.Errorf("repaired the chain, but failed to remove block %d: %v", block.Height, err)
+		}
+	}
+	if err := cleanup.Flush(); err != nil {
+		return fixed, fmt.Errorf("repaired the chain, but failed to remove the replaced blocks: %v", err)
+	}
+	return fixed, nil
+}
@@ -0,0 +1,107 @@
+package blockchain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+// appendLegacyMerkleBlock appends a block whose Merkle root was built with the
+// old scheme, which hashed the hex certificate hashes again as leaves
+func appendLegacyMerkleBlock(t *testing.T, chain *Blockchain, signer identity.Signer, ids []string) *Block {
+	t.Helper()
+	tip, err := chain.Tip()
+	if err != nil {
+		t.Fatalf("tip: %v", err)
+	}
+	block := NewBlock(ids, tip.Hash, tip.Height+1, signer)
+	block.MerkleRoot = NewMerkleTree(block.CertificateHashes).Root.Data
+	if err := block.SignWithSigner(signer); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	block.Hash = block.CalculateHash()
+
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+	if err := chain.appendBlock(block); err != nil {
+		t.Fatalf("append block: %v", err)
+	}
+	return block
+}
+
+func TestRepairMerkleRoots(t *testing.T) {
+	chain, signer := newTestChain(t, 1)
+	appendLegacyMerkleBlock(t, chain, signer, []string{"CERT-A", "CERT-B", "CERT-C"})
+	appendLegacyMerkleBlock(t, chain, signer, []string{"CERT-D"})
+	if _, err := chain.AddBlock([]string{"CERT-E", "CERT-F"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("legacy chain should still validate: %v", err)
+	}
+	proofVerifies := func(id string) bool {
+		t.Helper()
+		block, err := chain.FindCertificate(id)
+		if err != nil || block == nil {
+			t.Fatalf("find %s: %v", id, err)
+		}
+		proof, err := block.GenerateCertificateProof(id)
+		if err != nil {
+			t.Fatalf("proof for %s: %v", id, err)
+		}
+		return block.VerifyCertificateWithProof(id, proof)
+	}
+	if proofVerifies("CERT-B") || proofVerifies("CERT-D") {
+		t.Fatal("expected proofs against legacy roots to fail")
+	}
+
+	other := identity.NewIdentitySigner(identity.MakeIdentity())
+	tipBefore := chain.LastHash
+	if _, err := chain.RepairMerkleRoots(other); err == nil {
+		t.Fatal("expected another signer to be refused")
+	}
+	if !bytes.Equal(chain.LastHash, tipBefore) {
+		t.Fatal("a refused repair changed the chain")
+	}
+
+	fixed, err := chain.RepairMerkleRoots(signer)
+	if err != nil {
+		t.Fatalf("repair: %v", err)
+	}
+	if fixed != 2 {
+		t.Fatalf("expected 2 roots fixed, got %d", fixed)
+	}
+	for _, id := range []string{"CERT-A", "CERT-B", "CERT-C", "CERT-D", "CERT-E"} {
+		if !proofVerifies(id) {
+			t.Fatalf("proof for %s does not verify after repair", id)
+		}
+	}
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("repaired chain invalid: %v", err)
+	}
+	for height := 0; height <= 4; height++ {
+		if _, err := chain.GetBlockByHeight(height); err != nil {
+			t.Fatalf("height %d not indexed after repair: %v", height, err)
+		}
+	}
+	if exists, err := chain.CertificateExists("CERT-D"); err != nil || !exists {
+		t.Fatalf("expected CERT-D found after repair, got %v, %v", exists, err)
+	}
+
+	if fixed, err := chain.RepairMerkleRoots(nil); err != nil || fixed != 0 {
+		t.Fatalf("expected a second repair to find nothing, got %d, %v", fixed, err)
+	}
+}
+
+func TestRepairMerkleRootsLeavesHealthyChain(t *testing.T) {
+	chain, _ := newTestChain(t, 3)
+	tip := chain.LastHash
+	fixed, err := chain.RepairMerkleRoots(nil)
+	if err != nil || fixed != 0 {
+		t.Fatalf("expected nothing to repair, got %d, %v", fixed, err)
+	}
+	if !bytes.Equal(chain.LastHash, tip) {
+		t.Fatalf("tip changed from %x to %x", tip, chain.LastHash)
+	}
+}
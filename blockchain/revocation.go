@@ -0,0 +1,237 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/amanechibana/veritas-chain/identity"
+	"github.com/dgraph-io/badger/v4"
+)
+
+// revocationDomainTag separates revocation signatures from block signatures
+var revocationDomainTag = []byte("veritas-revocation-v2")
+
+// revocationNonceSize is the number of random bytes signed into each revocation
+const revocationNonceSize = 16
+
+// Revocation is a signed statement by the issuing university that a certificate is no longer valid.
+//
+// Height is the chain height when the revocation was signed. A revocation only
+// applies to an issuance at or below that height, so replaying it after the
+// certificate has been re-issued has no effect. Nonce makes every revocation
+// payload unique.
+type Revocation struct {
+	CertificateHash   string `json:"certificate_hash"`
+	Reason            string `json:"reason"`
+	Timestamp         int64  `json:"timestamp"`
+	Height            int    `json:"height"`
+	Nonce             []byte `json:"nonce"`
+	UniversityAddress []byte `json:"university_address"`
+	PublicKey         []byte `json:"public_key"`
+	Signature         []byte `json:"signature"`
+}
+
+// Certificate statuses reported by CertificateStatus
+const (
+	StatusValid      = "valid"
+	StatusRevoked    = "revoked"
+	StatusSuperseded = "superseded"
+	StatusUnknown    = "unknown"
+)
+
+// CertificateStatus combines a certificate's presence on the chain with the
+// revocation list and any supersession pointing to its replacement
+type CertificateStatus struct {
+	Status       string        `json:"status"`
+	BlockHeight  int           `json:"block_height,omitempty"`
+	Revocation   *Revocation   `json:"revocation,omitempty"`
+	Supersession *Supersession `json:"supersession,omitempty"`
+
+	// SupersededBy is the ID of the replacement certificate when Status is superseded
+	SupersededBy string `json:"superseded_by,omitempty"`
+}
+
+// revocationPrefix prefixes the keys revocations are stored under
+var revocationPrefix = []byte("r-")
+
+// revocationKey returns the key a certificate's revocation is stored under
+func revocationKey(certificateHash string) []byte {
+	return append(append([]byte{}, revocationPrefix...), certificateHash...)
+}
+
+// CalculateHashForSigning returns the digest the revoking university signs
+func (r *Revocation) CalculateHashForSigning() []byte {
+	data := bytes.Join([][]byte{
+		revocationDomainTag,
+		[]byte(r.CertificateHash),
+		[]byte(r.Reason),
+		ToHex(r.Timestamp),
+		ToHex(int64(r.Height)),
+		r.Nonce,
+		r.UniversityAddress,
+	}, []byte{})
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
+
+// Validate checks that the revocation was signed by the key belonging to its university
+func (r *Revocation) Validate() error {
+	return checkSignerKey(r.PublicKey, r.UniversityAddress, r.CalculateHashForSigning(), r.Signature)
+}
+
+func (r *Revocation) Serialize() []byte {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(r); err != nil {
+		log.Panic(err)
+	}
+	return buffer.Bytes()
+}
+
+func DeserializeRevocation(data []byte) (*Revocation, error) {
+	var revocation Revocation
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&revocation); err != nil {
+		return nil, fmt.Errorf("failed to decode revocation: %v", err)
+	}
+	return &revocation, nil
+}
+
+// Revoke records a signed revocation of certificateID. Only the university that
+// issued the certificate may revoke it, and an issuance is revoked at most once;
+// a certificate re-issued after being revoked can be revoked again.
+func (bc *Blockchain) Revoke(certificateID, reason string, signer identity.Signer) (*Revocation, error) {
+	if err := ValidateCertificateID(certificateID); err != nil {
+		return nil, err
+	}
+	block, err := bc.findIssuedBy(certificateID, signer, "revoked")
+	if err != nil {
+		return nil, err
+	}
+
+	tip, err := bc.Tip()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, revocationNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate revocation nonce: %v", err)
+	}
+
+	revocation := &Revocation{
+		CertificateHash:   hashCertificateIDs([]string{certificateID})[0],
+		Reason:            reason,
+		Timestamp:         timeNow().Unix(),
+		Height:            tip.Height,
+		Nonce:             nonce,
+		UniversityAddress: signer.Address(),
+		PublicKey:         identity.EncodePublicKey(signer.PublicKey()),
+	}
+	if revocation.Signature, err = signer.Sign(revocation.CalculateHashForSigning()); err != nil {
+		return nil, fmt.Errorf("failed to sign revocation: %v", err)
+	}
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	err = bc.Database.Update(func(txn *badger.Txn) error {
+		key := revocationKey(revocation.CertificateHash)
+		item, err := txn.Get(key)
+		if err == nil {
+			err = item.Value(func(val []byte) error {
+				existing, err := DeserializeRevocation(val)
+				if err != nil {
+					return err
+				}
+				if revocationApplies(existing, block) {
+					return fmt.Errorf("certificate %q is already revoked", certificateID)
+				}
+				return nil
+			})
+		} else if errors.Is(err, badger.ErrKeyNotFound) {
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+		return txn.Set(key, revocation.Serialize())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revocation, nil
+}
+
+// GetRevocation returns the revocation of certificateID, or nil if it is not revoked
+func (bc *Blockchain) GetRevocation(certificateID string) (*Revocation, error) {
+	var revocation *Revocation
+	err := bc.Database.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(revocationKey(hashCertificateIDs([]string{certificateID})[0]))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			revocation, err = DeserializeRevocation(val)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revocation: %v", err)
+	}
+	return revocation, nil
+}
+
+// IsRevoked reports whether certificateID is on the chain and validly revoked
+func (bc *Blockchain) IsRevoked(certificateID string) (bool, error) {
+	status, err := bc.CertificateStatus(certificateID)
+	return status.Status == StatusRevoked, err
+}
+
+// CertificateStatus reports whether certificateID is valid, revoked, superseded
+// or unknown to the chain. A revoked certificate is reported as revoked even if
+// it was also superseded.
+func (bc *Blockchain) CertificateStatus(certificateID string) (CertificateStatus, error) {
+	block, err := bc.FindCertificate(certificateID)
+	if err != nil {
+		return CertificateStatus{}, err
+	}
+	if block == nil {
+		return CertificateStatus{Status: StatusUnknown}, nil
+	}
+
+	revocation, err := bc.GetRevocation(certificateID)
+	if err != nil {
+		return CertificateStatus{}, err
+	}
+	if revocation != nil && revocationApplies(revocation, block) {
+		return CertificateStatus{Status: StatusRevoked, BlockHeight: block.Height, Revocation: revocation}, nil
+	}
+
+	supersession, err := bc.GetSupersession(certificateID)
+	if err != nil {
+		return CertificateStatus{}, err
+	}
+	if supersession != nil && supersessionApplies(supersession, block) {
+		return CertificateStatus{
+			Status:       StatusSuperseded,
+			BlockHeight:  block.Height,
+			Supersession: supersession,
+			SupersededBy: supersession.NewCertificateID,
+		}, nil
+	}
+	return CertificateStatus{Status: StatusValid, BlockHeight: block.Height}, nil
+}
+
+// revocationApplies reports whether a stored revocation is signed by the university that
+// issued block and was signed at or after block's height, i.e. targets this issuance
+// rather than an earlier one
+func revocationApplies(revocation *Revocation, block *Block) bool {
+	return bytes.Equal(revocation.UniversityAddress, block.UniversityAddress) &&
+		revocation.Height >= block.Height &&
+		revocation.Validate() == nil
+}
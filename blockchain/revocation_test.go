@@ -0,0 +1,78 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+func TestRevocationAppliesToIssuance(t *testing.T) {
+	chain, signer := newTestChain(t, 3)
+
+	revocation, err := chain.Revoke("CERT-001", "issued in error", signer)
+	if err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if revocation.Height != 3 || len(revocation.Nonce) != revocationNonceSize {
+		t.Fatalf("revocation not bound to the chain: height %d, nonce %x", revocation.Height, revocation.Nonce)
+	}
+	revoked, err := chain.IsRevoked("CERT-001")
+	if err != nil {
+		t.Fatalf("is revoked: %v", err)
+	}
+	if !revoked {
+		t.Fatal("expected CERT-001 to be revoked")
+	}
+
+	if _, err := chain.Revoke("CERT-001", "again", signer); err == nil {
+		t.Fatal("expected a second revocation of the same issuance to fail")
+	}
+
+	tampered := *revocation
+	tampered.Height = 10
+	storeRevocation(t, chain, &tampered)
+	if revoked, _ := chain.IsRevoked("CERT-001"); revoked {
+		t.Fatal("revocation with a tampered height should not apply")
+	}
+}
+
+func TestReplayedRevocationIgnoredAfterReissue(t *testing.T) {
+	chain, signer := newTestChain(t, 3)
+
+	stale, err := chain.Revoke("CERT-001", "issued in error", signer)
+	if err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+
+	// Re-issue the certificate in a later block, then replay the old revocation
+	if _, err := chain.AddBlock([]string{"CERT-001"}, signer); err != nil {
+		t.Fatalf("re-issue: %v", err)
+	}
+	storeRevocation(t, chain, stale)
+
+	status, err := chain.CertificateStatus("CERT-001")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.Status != StatusValid || status.BlockHeight != 4 {
+		t.Fatalf("replayed revocation applied to re-issued certificate: %+v", status)
+	}
+
+	if _, err := chain.Revoke("CERT-001", "revoked again", signer); err != nil {
+		t.Fatalf("revoke re-issued certificate: %v", err)
+	}
+	if revoked, _ := chain.IsRevoked("CERT-001"); !revoked {
+		t.Fatal("expected the re-issued certificate to be revoked")
+	}
+}
+
+// storeRevocation writes a revocation record directly, bypassing Revoke's checks
+func storeRevocation(t *testing.T, chain *Blockchain, revocation *Revocation) {
+	t.Helper()
+	err := chain.Database.Update(func(txn *badger.Txn) error {
+		return txn.Set(revocationKey(revocation.CertificateHash), revocation.Serialize())
+	})
+	if err != nil {
+		t.Fatalf("store revocation: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"sync"
+)
+
+// signatureCache records the blocks whose signatures have been verified, so
+// repeated validations of an unchanged chain skip the ECDSA checks. Version 2
+// block hashes leave out the public key and university address, so a block is
+// cached under its hash together with its signature, key and address: a block
+// re-attributed to another signer is a different entry and is checked afresh.
+// A nil *signatureCache caches nothing.
+type signatureCache struct {
+	mu       sync.Mutex
+	verified map[string]struct{}
+}
+
+func newSignatureCache() *signatureCache {
+	return &signatureCache{verified: make(map[string]struct{})}
+}
+
+// signatureCacheKey joins the fields a verified signature vouches for, each
+// prefixed with its length so no two blocks share a key
+func signatureCacheKey(b *Block) string {
+	var key []byte
+	for _, field := range [][]byte{b.Hash, b.Signature, b.PublicKey, b.UniversityAddress} {
+		key = binary.AppendUvarint(key, uint64(len(field)))
+		key = append(key, field...)
+	}
+	return string(key)
+}
+
+// has reports whether b's signature was verified with its recorded key and address
+func (c *signatureCache) has(b *Block) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.verified[signatureCacheKey(b)]
+	return ok
+}
+
+func (c *signatureCache) add(b *Block) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.verified[signatureCacheKey(b)] = struct{}{}
+}
+
+func (c *signatureCache) len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.verified)
+}
+
+// clear forgets every verified block, after the chain is rolled back
+func (c *signatureCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.verified)
+}
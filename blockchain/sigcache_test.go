@@ -0,0 +1,124 @@
+package blockchain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+func TestSignatureCacheFilledByValidation(t *testing.T) {
+	chain, _ := newTestChain(t, 3)
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if n := chain.sigs.len(); n != 4 {
+		t.Fatalf("expected 4 verified blocks, got %d", n)
+	}
+
+	// A block re-signed and re-hashed is a different block, checked afresh
+	tip, err := chain.Tip()
+	if err != nil {
+		t.Fatalf("tip: %v", err)
+	}
+	tampered := *tip
+	tampered.Signature = append([]byte{}, tip.Signature...)
+	tampered.Signature[0] ^= 0xff
+	tampered.Hash = tampered.CalculateHash()
+	if chain.sigs.has(&tampered) {
+		t.Fatalf("tampered block found in the signature cache")
+	}
+	if err := tampered.validate(chain.sigs); err == nil {
+		t.Fatalf("expected tampered signature to fail validation")
+	}
+}
+
+func TestSignatureCacheRejectsReattributedBlock(t *testing.T) {
+	chain, _ := newTestChain(t, 1)
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	tip, err := chain.Tip()
+	if err != nil {
+		t.Fatalf("tip: %v", err)
+	}
+
+	// The key and address are not hashed, so swapping them keeps the hash
+	// the warm cache holds, but not the cache entry
+	other := identity.NewIdentitySigner(identity.MakeIdentity())
+	reattributed := *tip
+	reattributed.PublicKey = identity.EncodePublicKey(other.PublicKey())
+	reattributed.UniversityAddress = other.Address()
+	if !bytes.Equal(reattributed.CalculateHash(), tip.Hash) {
+		t.Fatalf("expected the key and address to be left out of the hash")
+	}
+	if chain.sigs.has(&reattributed) {
+		t.Fatalf("re-attributed block found in the signature cache")
+	}
+	if err := reattributed.validate(chain.sigs); err == nil {
+		t.Fatalf("expected the re-attributed block to fail validation with a warm cache")
+	}
+	signers := identity.AuthorizedSigners{"other": string(other.Address())}
+	if err := checkAuthorizedSigner(&reattributed, signers, chain.sigs); err == nil {
+		t.Fatalf("expected the re-attributed block to fail the signer check with a warm cache")
+	}
+}
+
+func TestSignatureCacheClearedOnRollback(t *testing.T) {
+	chain, _ := newTestChain(t, 3)
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	orphan, err := chain.Tip()
+	if err != nil {
+		t.Fatalf("tip: %v", err)
+	}
+	if !chain.sigs.has(orphan) {
+		t.Fatalf("tip not cached after validation")
+	}
+
+	deleteKey(t, chain, orphan.Hash)
+	if err := chain.RepairTip(); err != nil {
+		t.Fatalf("repair: %v", err)
+	}
+	if chain.sigs.has(orphan) || chain.sigs.len() != 0 {
+		t.Fatalf("signature cache kept %d entries across the rollback", chain.sigs.len())
+	}
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("validate after repair: %v", err)
+	}
+	if n := chain.sigs.len(); n != 3 {
+		t.Fatalf("expected 3 verified blocks after repair, got %d", n)
+	}
+
+	if err := chain.Reset(); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if n := chain.sigs.len(); n != 0 {
+		t.Fatalf("signature cache kept %d entries across Reset", n)
+	}
+}
+
+func BenchmarkRepeatedValidation(b *testing.B) {
+	chain, _ := newTestChain(b, 200)
+
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			chain.sigs.clear()
+			if err := chain.ValidateChain(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		if err := chain.ValidateChain(); err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := chain.ValidateChain(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// NewStagingChain returns an empty chain to assemble blocks in with
+// ImportBlock before ReplaceWith swaps them into bc, so a failed or partial
+// import never touches bc. The staging chain is kept in memory when bc is,
+// and otherwise in a temporary directory that Close removes.
+func (bc *Blockchain) NewStagingChain() (*Blockchain, error) {
+	options := BlockchainOptions{
+		InMemory:         bc.options.InMemory,
+		Logger:           bc.options.Logger,
+		ValueLogFileSize: bc.options.ValueLogFileSize,
+		BlockCacheSize:   -1,
+		GenesisSigner:    bc.options.GenesisSigner,
+	}
+	dir := ""
+	if !options.InMemory {
+		var err error
+		if dir, err = os.MkdirTemp("", "veritas-staging-"); err != nil {
+			return nil, fmt.Errorf("failed to create staging directory: %v", err)
+		}
+	}
+	db, err := badger.Open(options.badgerOptions(dir))
+	if err != nil {
+		if dir != "" {
+			os.RemoveAll(dir)
+		}
+		return nil, fmt.Errorf("failed to open staging chain: %v", err)
+	}
+	staging := newBlockchain(nil, db, options)
+	staging.removeOnClose = dir
+	return staging, nil
+}
+
+// ReplaceWith replaces every block and record of the chain with those of
+// source, typically a staging chain from NewStagingChain. The new block
+// records are written first; the height index, the other records and the tip
+// are then switched in a single transaction, so a crash leaves either the old
+// chain or the new one. The old block records are removed last. Revocation,
+// supersession and equivocation records are not part of an export, so the
+// chain's own are kept and merged with any source holds, source's winning.
+func (bc *Blockchain) ReplaceWith(source *Blockchain) error {
+	source.mu.RLock()
+	defer source.mu.RUnlock()
+	if len(source.LastHash) == 0 {
+		return fmt.Errorf("cannot replace the chain with an empty one")
+	}
+	newBlocks, err := source.chainBlockHashes()
+	if err != nil {
+		return err
+	}
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	oldBlocks, err := bc.chainBlockHashes()
+	if err != nil {
+		return err
+	}
+
+	// New block records are content-addressed, so writing them first leaves
+	// the old chain intact
+	batch := bc.Database.NewWriteBatch()
+	defer batch.Cancel()
+	err = source.eachRecord(func(key, value []byte) error {
+		if !newBlocks[string(key)] {
+			return nil
+		}
+		return batch.Set(key, value)
+	})
+	if err == nil {
+		err = batch.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write replacement blocks: %v", err)
+	}
+
+	var stale [][]byte
+	err = bc.Database.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			key := it.Item().KeyCopy(nil)
+			if !newBlocks[string(key)] && !oldBlocks[string(key)] && !isMergedRecord(key) {
+				stale = append(stale, key)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read chain records: %v", err)
+	}
+	err = bc.Database.Update(func(txn *badger.Txn) error {
+		for _, key := range stale {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+		return source.eachRecord(func(key, value []byte) error {
+			if newBlocks[string(key)] {
+				return nil
+			}
+			return txn.Set(key, value)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to switch to the replacement chain: %v", err)
+	}
+
+	bc.LastHash = append([]byte{}, source.LastHash...)
+	bc.cache.clear()
+	bc.sigs.clear()
+	bc.filterMu.Lock()
+	bc.filter = nil
+	bc.filterMu.Unlock()
+
+	cleanup := bc.Database.NewWriteBatch()
+	defer cleanup.Cancel()
+	for hash := range oldBlocks {
+		if newBlocks[hash] {
+			continue
+		}
+		if err := cleanup.Delete([]byte(hash)); err != nil {
+			return fmt.Errorf("replaced the chain, but failed to remove old blocks: %v", err)
+		}
+	}
+	if err := cleanup.Flush(); err != nil {
+		return fmt.Errorf("replaced the chain, but failed to remove old blocks: %v", err)
+	}
+	return nil
+}
+
+// mergedRecordPrefixes prefix the records ReplaceWith keeps from the replaced chain
+var mergedRecordPrefixes = [][]byte{revocationPrefix, supersessionPrefix, equivocationPrefix}
+
+// isMergedRecord reports whether key is a record ReplaceWith keeps
+func isMergedRecord(key []byte) bool {
+	for _, prefix := range mergedRecordPrefixes {
+		if bytes.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// chainBlockHashes returns the set of hashes of the blocks from the tip back to genesis.
+// Callers hold bc.mu.
+func (bc *Blockchain) chainBlockHashes() (map[string]bool, error) {
+	hashes := make(map[string]bool)
+	for hash := bc.LastHash; len(hash) > 0; {
+		block, err := bc.readBlockFromDisk(hash)
+		if err != nil {
+			return nil, err
+		}
+		hashes[string(block.Hash)] = true
+		hash = block.PrevHash
+	}
+	return hashes, nil
+}
+
+// eachRecord calls fn with a copy of every key and value in the database
+func (bc *Blockchain) eachRecord(fn func(key, value []byte) error) error {
+	return bc.Database.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			if err := fn(item.KeyCopy(nil), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
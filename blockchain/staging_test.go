@@ -0,0 +1,79 @@
+package blockchain
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+func TestReplaceWithStagingChain(t *testing.T) {
+	source, _ := newTestChain(t, 2)
+
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	options := DefaultBlockchainOptions()
+	options.Logger = NopLogger()
+	chain := InitBlockchain(t.TempDir(), signer, options)
+	t.Cleanup(func() { chain.Close() })
+	for _, id := range []string{"CERT-OLD-1", "CERT-OLD-2", "CERT-OLD-3"} {
+		if _, err := chain.AddBlock([]string{id}, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+	if _, err := chain.Revoke("CERT-OLD-1", "test", signer); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+
+	staging, err := chain.NewStagingChain()
+	if err != nil {
+		t.Fatalf("staging chain: %v", err)
+	}
+	dir := staging.removeOnClose
+	if dir == "" {
+		t.Fatal("expected an on-disk staging chain for an on-disk chain")
+	}
+	if err := chain.ReplaceWith(staging); err == nil {
+		t.Fatal("expected replacing with an empty chain to fail")
+	}
+	tip, err := source.Tip()
+	if err != nil {
+		t.Fatalf("tip: %v", err)
+	}
+	for height := 0; height <= tip.Height; height++ {
+		block, err := source.GetBlockByHeight(height)
+		if err != nil {
+			t.Fatalf("get block: %v", err)
+		}
+		if err := staging.ImportBlock(block); err != nil {
+			t.Fatalf("stage block %d: %v", height, err)
+		}
+	}
+
+	if err := chain.ReplaceWith(staging); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if !bytes.Equal(chain.lastHash(), tip.Hash) {
+		t.Fatal("chain tip is not the staged tip")
+	}
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("replaced chain invalid: %v", err)
+	}
+	if block, _ := chain.FindCertificate("CERT-OLD-2"); block != nil {
+		t.Fatal("old certificates survived the replacement")
+	}
+	// Revocations are not exported, so they are kept across the replacement
+	if revocation, err := chain.GetRevocation("CERT-OLD-1"); err != nil || revocation == nil {
+		t.Fatalf("revocation was dropped by the replacement: %v", err)
+	}
+	if _, err := chain.GetBlockByHeight(3); err == nil {
+		t.Fatal("old height index entry survived the replacement")
+	}
+
+	if err := staging.Close(); err != nil {
+		t.Fatalf("close staging: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("staging directory %s was not removed", dir)
+	}
+}
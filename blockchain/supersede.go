@@ -0,0 +1,210 @@
+package blockchain
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/amanechibana/veritas-chain/identity"
+	"github.com/dgraph-io/badger/v4"
+)
+
+// supersessionDomainTag separates supersession signatures from block and revocation signatures
+var supersessionDomainTag = []byte("veritas-supersession-v1")
+
+// Supersession is a signed statement by the issuing university that a
+// certificate has been replaced by a corrected certificate, NewCertificateID,
+// which it has also issued on the chain. Height and Nonce play the same role
+// as in a Revocation: the record only applies to an issuance at or below
+// Height, and every payload is unique.
+type Supersession struct {
+	OldCertificateHash string `json:"old_certificate_hash"`
+	NewCertificateID   string `json:"new_certificate_id"`
+	Timestamp          int64  `json:"timestamp"`
+	Height             int    `json:"height"`
+	Nonce              []byte `json:"nonce"`
+	UniversityAddress  []byte `json:"university_address"`
+	PublicKey          []byte `json:"public_key"`
+	Signature          []byte `json:"signature"`
+}
+
+// supersessionPrefix prefixes the keys supersessions are stored under
+var supersessionPrefix = []byte("s-")
+
+// supersessionKey returns the key a certificate's supersession is stored under
+func supersessionKey(certificateHash string) []byte {
+	return append(append([]byte{}, supersessionPrefix...), certificateHash...)
+}
+
+// CalculateHashForSigning returns the digest the superseding university signs
+func (s *Supersession) CalculateHashForSigning() []byte {
+	data := bytes.Join([][]byte{
+		supersessionDomainTag,
+		[]byte(s.OldCertificateHash),
+		[]byte(s.NewCertificateID),
+		ToHex(s.Timestamp),
+		ToHex(int64(s.Height)),
+		s.Nonce,
+		s.UniversityAddress,
+	}, []byte{})
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
+
+// Validate checks that the supersession was signed by the key belonging to its university
+func (s *Supersession) Validate() error {
+	return checkSignerKey(s.PublicKey, s.UniversityAddress, s.CalculateHashForSigning(), s.Signature)
+}
+
+func (s *Supersession) Serialize() []byte {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(s); err != nil {
+		log.Panic(err)
+	}
+	return buffer.Bytes()
+}
+
+func DeserializeSupersession(data []byte) (*Supersession, error) {
+	var supersession Supersession
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&supersession); err != nil {
+		return nil, fmt.Errorf("failed to decode supersession: %v", err)
+	}
+	return &supersession, nil
+}
+
+// Supersede records a signed statement that oldCertificateID is replaced by
+// newCertificateID. Both certificates must already be on the chain, issued by
+// signer's university, and an issuance is superseded at most once.
+func (bc *Blockchain) Supersede(oldCertificateID, newCertificateID string, signer identity.Signer) (*Supersession, error) {
+	if err := ValidateCertificateIDs([]string{oldCertificateID, newCertificateID}); err != nil {
+		return nil, err
+	}
+	if oldCertificateID == newCertificateID {
+		return nil, fmt.Errorf("certificate %q cannot supersede itself", oldCertificateID)
+	}
+
+	oldBlock, err := bc.findIssuedBy(oldCertificateID, signer, "superseded")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := bc.findIssuedBy(newCertificateID, signer, "used as a replacement"); err != nil {
+		return nil, err
+	}
+
+	tip, err := bc.Tip()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, revocationNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate supersession nonce: %v", err)
+	}
+
+	supersession := &Supersession{
+		OldCertificateHash: hashCertificateIDs([]string{oldCertificateID})[0],
+		NewCertificateID:   newCertificateID,
+		Timestamp:          timeNow().Unix(),
+		Height:             tip.Height,
+		Nonce:              nonce,
+		UniversityAddress:  signer.Address(),
+		PublicKey:          identity.EncodePublicKey(signer.PublicKey()),
+	}
+	if supersession.Signature, err = signer.Sign(supersession.CalculateHashForSigning()); err != nil {
+		return nil, fmt.Errorf("failed to sign supersession: %v", err)
+	}
+
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	err = bc.Database.Update(func(txn *badger.Txn) error {
+		key := supersessionKey(supersession.OldCertificateHash)
+		item, err := txn.Get(key)
+		if err == nil {
+			err = item.Value(func(val []byte) error {
+				existing, err := DeserializeSupersession(val)
+				if err != nil {
+					return err
+				}
+				if supersessionApplies(existing, oldBlock) {
+					return fmt.Errorf("certificate %q is already superseded by %q", oldCertificateID, existing.NewCertificateID)
+				}
+				return nil
+			})
+		} else if errors.Is(err, badger.ErrKeyNotFound) {
+			err = nil
+		}
+		if err != nil {
+			return err
+		}
+		return txn.Set(key, supersession.Serialize())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return supersession, nil
+}
+
+// findIssuedBy returns the newest block recording certificateID, failing when
+// it is not on the chain or was issued by another university than signer's.
+// action describes what is being done to the certificate, for the error.
+func (bc *Blockchain) findIssuedBy(certificateID string, signer identity.Signer, action string) (*Block, error) {
+	block, err := bc.FindCertificate(certificateID)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("certificate %q is not on the chain", certificateID)
+	}
+	if !bytes.Equal(block.UniversityAddress, signer.Address()) {
+		return nil, fmt.Errorf("certificate %q was issued by %s and can only be %s by its issuer", certificateID, block.UniversityAddress, action)
+	}
+	return block, nil
+}
+
+// GetSupersession returns the stored supersession of certificateID, or nil if there is none
+func (bc *Blockchain) GetSupersession(certificateID string) (*Supersession, error) {
+	var supersession *Supersession
+	err := bc.Database.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(supersessionKey(hashCertificateIDs([]string{certificateID})[0]))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			supersession, err = DeserializeSupersession(val)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read supersession: %v", err)
+	}
+	return supersession, nil
+}
+
+// SupersededBy returns the ID of the certificate that replaces certificateID,
+// and whether certificateID is on the chain and validly superseded. Read
+// errors are reported as not superseded; use CertificateStatus to see them.
+func (bc *Blockchain) SupersededBy(certificateID string) (string, bool) {
+	block, err := bc.FindCertificate(certificateID)
+	if err != nil || block == nil {
+		return "", false
+	}
+	supersession, err := bc.GetSupersession(certificateID)
+	if err != nil || supersession == nil || !supersessionApplies(supersession, block) {
+		return "", false
+	}
+	return supersession.NewCertificateID, true
+}
+
+// supersessionApplies reports whether a stored supersession is signed by the
+// university that issued block and targets this issuance rather than an earlier one
+func supersessionApplies(supersession *Supersession, block *Block) bool {
+	return bytes.Equal(supersession.UniversityAddress, block.UniversityAddress) &&
+		supersession.Height >= block.Height &&
+		supersession.Validate() == nil
+}
@@ -0,0 +1,93 @@
+package blockchain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+func TestSupersedeLinksReplacement(t *testing.T) {
+	chain, signer := newTestChain(t, 2)
+	if _, ok := chain.SupersededBy("CERT-001"); ok {
+		t.Fatal("expected a freshly issued certificate not to be superseded")
+	}
+
+	// The replacement must be issued before it can supersede anything
+	if _, err := chain.Supersede("CERT-001", "CERT-001-R1", signer); err == nil || !strings.Contains(err.Error(), "not on the chain") {
+		t.Fatalf("expected an unissued replacement to be rejected, got %v", err)
+	}
+	if _, err := chain.AddBlock([]string{"CERT-001-R1"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+
+	supersession, err := chain.Supersede("CERT-001", "CERT-001-R1", signer)
+	if err != nil {
+		t.Fatalf("supersede: %v", err)
+	}
+	if err := supersession.Validate(); err != nil {
+		t.Fatalf("supersession signature invalid: %v", err)
+	}
+	if supersession.OldCertificateHash != hashCertificateIDs([]string{"CERT-001"})[0] {
+		t.Fatalf("supersession recorded the wrong certificate hash %s", supersession.OldCertificateHash)
+	}
+
+	replacement, ok := chain.SupersededBy("CERT-001")
+	if !ok || replacement != "CERT-001-R1" {
+		t.Fatalf("expected CERT-001 to be superseded by CERT-001-R1, got %q, %v", replacement, ok)
+	}
+	status, err := chain.CertificateStatus("CERT-001")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.Status != StatusSuperseded || status.SupersededBy != "CERT-001-R1" || status.Supersession == nil {
+		t.Fatalf("expected a superseded status pointing to CERT-001-R1, got %+v", status)
+	}
+
+	// The replacement itself is valid, and the link is one-way
+	if status, _ := chain.CertificateStatus("CERT-001-R1"); status.Status != StatusValid {
+		t.Fatalf("expected the replacement to be valid, got %s", status.Status)
+	}
+	if _, ok := chain.SupersededBy("CERT-001-R1"); ok {
+		t.Fatal("expected the replacement not to be superseded")
+	}
+
+	if _, err := chain.Supersede("CERT-001", "CERT-000", signer); err == nil || !strings.Contains(err.Error(), "already superseded") {
+		t.Fatalf("expected a second supersession to fail, got %v", err)
+	}
+}
+
+func TestSupersedeRequiresIssuer(t *testing.T) {
+	chain, signer := newTestChain(t, 2)
+	other := identity.NewIdentitySigner(identity.MakeIdentity())
+	if _, err := chain.AddBlock([]string{"CERT-OTHER"}, other); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+
+	if _, err := chain.Supersede("CERT-000", "CERT-OTHER", other); err == nil || !strings.Contains(err.Error(), "can only be superseded by its issuer") {
+		t.Fatalf("expected another university's certificate to be rejected, got %v", err)
+	}
+	if _, err := chain.Supersede("CERT-000", "CERT-OTHER", signer); err == nil || !strings.Contains(err.Error(), "replacement") {
+		t.Fatalf("expected another university's replacement to be rejected, got %v", err)
+	}
+	if _, err := chain.Supersede("CERT-000", "CERT-000", signer); err == nil {
+		t.Fatal("expected a certificate superseding itself to be rejected")
+	}
+}
+
+func TestRevokedTakesPrecedenceOverSuperseded(t *testing.T) {
+	chain, signer := newTestChain(t, 2)
+	if _, err := chain.Supersede("CERT-000", "CERT-001", signer); err != nil {
+		t.Fatalf("supersede: %v", err)
+	}
+	if _, err := chain.Revoke("CERT-000", "fraud", signer); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	status, err := chain.CertificateStatus("CERT-000")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.Status != StatusRevoked {
+		t.Fatalf("expected revoked, got %s", status.Status)
+	}
+}
@@ -0,0 +1,137 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/amanechibana/veritas-chain/identity"
+	"github.com/dgraph-io/badger/v4"
+)
+
+// syncCursorKey stores the height of the last block SyncFrom imported
+var syncCursorKey = []byte("sync-cursor")
+
+// DefaultSyncBatchSize is how many blocks SyncFrom requests at a time when no batch size is given
+const DefaultSyncBatchSize = 100
+
+// BlockSource serves the blocks of another copy of the chain, such as a peer
+type BlockSource interface {
+	// TipHeight returns the height of the source's newest block
+	TipHeight(ctx context.Context) (int, error)
+	// BlocksInRange returns the blocks from height from to to inclusive, in
+	// height order. It may return fewer blocks than asked for, but not none.
+	BlocksInRange(ctx context.Context, from, to int) ([]*Block, error)
+}
+
+// SyncCursor returns the height of the last block imported by SyncFrom, and
+// false when no sync has imported anything into this chain
+func (bc *Blockchain) SyncCursor() (int, bool, error) {
+	var cursor int64
+	found := false
+	err := bc.Database.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(syncCursorKey)
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			found = true
+			return binary.Read(bytes.NewReader(val), binary.BigEndian, &cursor)
+		})
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read sync cursor: %v", err)
+	}
+	return int(cursor), found, nil
+}
+
+func (bc *Blockchain) setSyncCursor(height int) error {
+	err := bc.Database.Update(func(txn *badger.Txn) error {
+		return txn.Set(syncCursorKey, ToHex(int64(height)))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save sync cursor: %v", err)
+	}
+	return nil
+}
+
+// SyncFrom imports the blocks source has beyond this chain, batchSize at a
+// time, and returns how many it imported. Each imported height is saved as
+// the sync cursor, so when a sync fails part way through, calling SyncFrom
+// again resumes after the last imported block instead of starting over. The
+// chain must be empty (see Reset) or a prefix of the source's chain. Every
+// imported block must be signed by one of signers, or for the genesis block by
+// the configured GenesisSigner; sync stops at the first block that is not.
+func (bc *Blockchain) SyncFrom(ctx context.Context, source BlockSource, signers identity.AuthorizedSigners, batchSize int) (int, error) {
+	if len(signers) == 0 {
+		return 0, fmt.Errorf("no authorized signers to check synced blocks against")
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultSyncBatchSize
+	}
+	next, err := bc.syncStart()
+	if err != nil {
+		return 0, err
+	}
+	target, err := source.TipHeight(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read source tip: %v", err)
+	}
+
+	imported := 0
+	for next <= target {
+		to := min(next+batchSize-1, target)
+		blocks, err := source.BlocksInRange(ctx, next, to)
+		if err != nil {
+			return imported, fmt.Errorf("failed to fetch blocks %d to %d: %v", next, to, err)
+		}
+		if len(blocks) == 0 {
+			return imported, fmt.Errorf("source returned no blocks from height %d", next)
+		}
+		for _, block := range blocks {
+			if block.Height != next {
+				return imported, fmt.Errorf("source returned block %d, expected %d", block.Height, next)
+			}
+			// Blocks the chain gained after the cursor was saved are not imported twice
+			if existing, err := bc.GetBlockByHeight(block.Height); err != nil || !bytes.Equal(existing.Hash, block.Hash) {
+				if err := bc.CheckBlockSigner(block, signers); err != nil {
+					return imported, err
+				}
+				if err := bc.ImportBlock(block); err != nil {
+					return imported, err
+				}
+				imported++
+			}
+			if err := bc.setSyncCursor(block.Height); err != nil {
+				return imported, err
+			}
+			next++
+		}
+	}
+	return imported, nil
+}
+
+// syncStart returns the first height SyncFrom must fetch: the one after the
+// sync cursor, or after the tip when there is no cursor. A cursor ahead of
+// the tip, left by a chain that was rewound since, is ignored.
+func (bc *Blockchain) syncStart() (int, error) {
+	if len(bc.lastHash()) == 0 {
+		return 0, nil
+	}
+	tip, err := bc.Tip()
+	if err != nil {
+		return 0, err
+	}
+	cursor, found, err := bc.SyncCursor()
+	if err != nil {
+		return 0, err
+	}
+	if found && cursor <= tip.Height {
+		return cursor + 1, nil
+	}
+	return tip.Height + 1, nil
+}
@@ -0,0 +1,117 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+// chainSource serves a local chain as a BlockSource, failing every request
+// for blocks above failAbove while it is non-negative
+type chainSource struct {
+	chain     *Blockchain
+	failAbove int
+	requested []int
+}
+
+func (s *chainSource) TipHeight(ctx context.Context) (int, error) {
+	tip, err := s.chain.Tip()
+	if err != nil {
+		return 0, err
+	}
+	return tip.Height, nil
+}
+
+func (s *chainSource) BlocksInRange(ctx context.Context, from, to int) ([]*Block, error) {
+	s.requested = append(s.requested, from)
+	if s.failAbove >= 0 && to > s.failAbove {
+		return nil, errors.New("connection reset")
+	}
+	return s.chain.BlocksInRange(from, to)
+}
+
+// newEmptyChain returns an in-memory chain with no blocks, ready to sync into
+func newEmptyChain(t *testing.T) *Blockchain {
+	t.Helper()
+	chain := InitBlockchain("", identity.NewIdentitySigner(identity.MakeIdentity()), testChainOptions)
+	t.Cleanup(func() { chain.Close() })
+	if err := chain.Reset(); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	return chain
+}
+
+func TestSyncResumesFromCursor(t *testing.T) {
+	sourceChain, signer := newTestChain(t, 9)
+	signers := identity.AuthorizedSigners{"uni": string(signer.Address())}
+	local := newEmptyChain(t)
+
+	// Batches of 2 cover heights 0-1, 2-3, 4-5, ...; the source fails from 4 on
+	source := &chainSource{chain: sourceChain, failAbove: 3}
+	imported, err := local.SyncFrom(context.Background(), source, signers, 2)
+	if err == nil {
+		t.Fatal("expected the interrupted sync to fail")
+	}
+	if imported != 4 {
+		t.Fatalf("expected 4 blocks imported before the failure, got %d", imported)
+	}
+	if cursor, found, err := local.SyncCursor(); err != nil || !found || cursor != 3 {
+		t.Fatalf("expected sync cursor 3, got %d %v %v", cursor, found, err)
+	}
+
+	source.failAbove = -1
+	source.requested = nil
+	imported, err = local.SyncFrom(context.Background(), source, signers, 2)
+	if err != nil {
+		t.Fatalf("resumed sync: %v", err)
+	}
+	if imported != 6 {
+		t.Fatalf("expected the remaining 6 blocks imported, got %d", imported)
+	}
+	if len(source.requested) == 0 || source.requested[0] != 4 {
+		t.Fatalf("expected the resumed sync to start at height 4, requested %v", source.requested)
+	}
+
+	localTip, _ := local.Tip()
+	sourceTip, _ := sourceChain.Tip()
+	if !bytes.Equal(localTip.Hash, sourceTip.Hash) {
+		t.Fatalf("local tip %x does not match source tip %x", localTip.Hash, sourceTip.Hash)
+	}
+	if err := local.ValidateChain(); err != nil {
+		t.Fatalf("synced chain is invalid: %v", err)
+	}
+
+	// A further sync finds nothing new
+	if imported, err := local.SyncFrom(context.Background(), source, signers, 2); err != nil || imported != 0 {
+		t.Fatalf("expected an up-to-date sync to import nothing, got %d %v", imported, err)
+	}
+}
+
+func TestSyncRejectsUnauthorizedSigner(t *testing.T) {
+	sourceChain, signer := newTestChain(t, 2)
+	outsider := identity.NewIdentitySigner(identity.MakeIdentity())
+	if _, err := sourceChain.AddBlock([]string{"CERT-OUTSIDER"}, outsider); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	signers := identity.AuthorizedSigners{"uni": string(signer.Address())}
+	local := newEmptyChain(t)
+
+	imported, err := local.SyncFrom(context.Background(), &chainSource{chain: sourceChain, failAbove: -1}, signers, 10)
+	if err == nil || !strings.Contains(err.Error(), "not an authorized signer") {
+		t.Fatalf("expected an unauthorized signer error, got %v", err)
+	}
+	if imported != 3 {
+		t.Fatalf("expected the 3 authorized blocks imported, got %d", imported)
+	}
+	if height, _ := local.Height(); height != 2 {
+		t.Fatalf("expected the chain to stop at height 2, got %d", height)
+	}
+
+	if _, err := local.SyncFrom(context.Background(), &chainSource{chain: sourceChain, failAbove: -1}, nil, 10); err == nil {
+		t.Fatal("expected a sync without authorized signers to fail")
+	}
+}
@@ -0,0 +1,853 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	"github.com/amanechibana/veritas-chain/identity"
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+)
+
+// blockchainCmd represents the blockchain command
+var blockchainCmd = &cobra.Command{
+	Use:   "blockchain",
+	Short: "Blockchain inspection commands",
+	Long:  `Commands for inspecting a node's local blockchain database.`,
+}
+
+// blockchainChainIDCmd prints the chain ID of the local blockchain
+var blockchainChainIDCmd = &cobra.Command{
+	Use:   "chain-id",
+	Short: "Print the chain ID",
+	Long:  `Print the chain ID (the hash of the genesis block) of the local blockchain.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		chain, err := openLocalChain(cmd)
+		if err != nil {
+			return err
+		}
+		defer chain.Close()
+
+		chainID, err := chain.ChainID()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), chainID)
+		return nil
+	},
+}
+
+// blockchainInfoCmd prints the chain's height and the age of its first and latest blocks
+var blockchainInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Print the chain's height and when its first and latest blocks were created",
+	Long: `Print the height of the local chain, the timestamps of its genesis block and
+tip, and how long ago the genesis block was created. Only those two blocks are
+read, so this is fast on large chains.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		chain, err := openLocalChain(cmd)
+		if err != nil {
+			return err
+		}
+		defer chain.Close()
+
+		info, err := chain.Info()
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Height:  %d (%d blocks)\n", info.Height, info.BlockCount)
+		fmt.Fprintf(out, "Genesis: %s\n", time.Unix(info.GenesisTimestamp, 0).UTC().Format(time.RFC3339))
+		fmt.Fprintf(out, "Tip:     %s\n", time.Unix(info.TipTimestamp, 0).UTC().Format(time.RFC3339))
+		fmt.Fprintf(out, "Age:     %s\n", time.Duration(info.AgeSeconds)*time.Second)
+		return nil
+	},
+}
+
+// blockchainListCmd groups listing commands
+var blockchainListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List blockchain contents",
+}
+
+// blockchainListBlocksCmd lists blocks, optionally only those issued since a time
+var blockchainListBlocksCmd = &cobra.Command{
+	Use:   "blocks",
+	Short: "List blocks, newest first",
+	Long: `List blocks from newest to oldest. With --since and --until, only blocks
+timestamped within the given RFC 3339 times, inclusive, are listed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		since, err := timeFlag(cmd, "since", 0)
+		if err != nil {
+			return err
+		}
+		until, err := timeFlag(cmd, "until", math.MaxInt64)
+		if err != nil {
+			return err
+		}
+
+		chain, err := openLocalChain(cmd)
+		if err != nil {
+			return err
+		}
+		defer chain.Close()
+
+		blocks, err := chain.BlocksBetween(since, until)
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		for i, block := range blocks {
+			printBlockLine(out, i, block)
+		}
+		fmt.Fprintf(out, "%d block(s)\n", len(blocks))
+		return nil
+	},
+}
+
+// timeFlag parses an RFC 3339 time flag as a Unix timestamp, returning def when it is not set
+func timeFlag(cmd *cobra.Command, name string, def int64) (int64, error) {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return def, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --%s %q: expected RFC 3339 time such as 2025-01-02T15:04:05Z", name, value)
+	}
+	return t.Unix(), nil
+}
+
+// blockchainRepairTipCmd rewinds an orphaned tip pointer to the highest intact block
+var blockchainRepairTipCmd = &cobra.Command{
+	Use:   "repair-tip",
+	Short: "Repair a tip pointer that references a missing block",
+	Long: `Check that the chain's tip (lh) pointer references an existing block and, if it
+does not, rewind it to the highest intact block found via the height index.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		chain, err := openLocalChainUnchecked(cmd, blockchain.DefaultBlockchainOptions())
+		if err != nil {
+			return err
+		}
+		defer chain.Close()
+
+		out := cmd.OutOrStdout()
+		if err := chain.CheckTip(); err == nil {
+			fmt.Fprintln(out, "Chain tip is intact; nothing to repair")
+			return nil
+		}
+		if err := chain.RepairTip(); err != nil {
+			return err
+		}
+		tip, err := chain.Tip()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Rewound chain tip to block %x at height %d\n", tip.Hash, tip.Height)
+		return nil
+	},
+}
+
+// blockchainRepairMerkleCmd rewrites blocks whose Merkle root does not match their certificates
+var blockchainRepairMerkleCmd = &cobra.Command{
+	Use:   "repair-merkle",
+	Short: "Recompute Merkle roots that certificate proofs do not verify against",
+	Long: `Recompute every block's Merkle root from its certificate hashes and rewrite the
+blocks whose stored root differs, such as blocks written with an older
+leaf-hashing scheme. A rewritten block gets a new hash, so the blocks after it
+are relinked and re-signed too. Re-signing uses SIGNER_PRIVATE_KEY_HEX, which
+must hold the key of the university that signed the blocks. Stop the node and
+back up the database first: the chain's block hashes change.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		chain, err := openLocalChain(cmd)
+		if err != nil {
+			return err
+		}
+		defer chain.Close()
+
+		_ = godotenv.Load()
+		loaded, err := identity.LoadSignerFromEnv()
+		if err != nil {
+			return fmt.Errorf("failed to load signer from env: %v", err)
+		}
+		// Without a key only a chain needing no rewrite can be checked
+		var signer identity.Signer
+		if loaded != nil {
+			signer = loaded
+		}
+
+		fixed, err := chain.RepairMerkleRoots(signer)
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		if fixed == 0 {
+			fmt.Fprintln(out, "Every Merkle root matches its certificates; nothing to repair")
+			return nil
+		}
+		tip, err := chain.Tip()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Fixed %d Merkle roots; new chain tip is %x at height %d\n", fixed, tip.Hash, tip.Height)
+		return nil
+	},
+}
+
+// validateResult is the --json output of blockchain validate
+type validateResult struct {
+	Valid      bool   `json:"valid"`
+	Error      string `json:"error,omitempty"`
+	BlockCount int    `json:"block_count"`
+}
+
+// errChainInvalid makes blockchain validate --json exit non-zero once the
+// failure has been reported as JSON
+var errChainInvalid = errors.New("chain validation failed")
+
+// blockchainValidateCmd validates the local chain
+var blockchainValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the local blockchain",
+	Long: `Validate every block of the local chain and the links between them. Exits
+non-zero when the chain is invalid. With --json, prints a single JSON object
+{"valid":bool,"error":"...","block_count":n} for scripts and CI pipelines.
+With --window, at most that many blocks are held in memory at a time. With
+--concurrency, blocks are read from disk by that many goroutines ahead of the
+checks, which gives the same result faster on large chains. With --signers, a
+registry file or URL in the authorized_signers.json format, every block,
+genesis included, must also carry a valid signature by an authorized signer;
+--genesis-signer additionally accepts the genesis block from that address.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		window, _ := cmd.Flags().GetInt("window")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		signersSource, _ := cmd.Flags().GetString("signers")
+		genesisSigner, _ := cmd.Flags().GetString("genesis-signer")
+		if window > 0 && concurrency > 0 {
+			return fmt.Errorf("--window and --concurrency cannot be combined")
+		}
+		if signersSource != "" && (window > 0 || concurrency > 0) {
+			return fmt.Errorf("--signers cannot be combined with --window or --concurrency")
+		}
+		if genesisSigner != "" && signersSource == "" {
+			return fmt.Errorf("--genesis-signer requires --signers")
+		}
+		var signers identity.AuthorizedSigners
+		if signersSource != "" {
+			var err error
+			if signers, err = identity.LoadAuthorizedSigners(signersSource); err != nil {
+				return fmt.Errorf("failed to load signers: %v", err)
+			}
+		}
+		out := cmd.OutOrStdout()
+
+		// An invalid chain is not a usage error, and in JSON mode the
+		// failure is reported in the JSON object rather than on stderr
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = asJSON
+
+		var result validateResult
+		options := blockchain.DefaultBlockchainOptions()
+		options.GenesisSigner = genesisSigner
+		chain, err := openLocalChainWithOptions(cmd, options)
+		if err == nil {
+			result.BlockCount = chain.GetStats().BlockCount
+			switch {
+			case signersSource != "":
+				err = chain.FullyValidate(signers)
+			case window > 0:
+				err = chain.ValidateChainWindow(window)
+			case concurrency > 0:
+				err = chain.ValidateChainParallel(concurrency)
+			default:
+				err = chain.ValidateChain()
+			}
+			chain.Close()
+		}
+		result.Valid = err == nil
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		if !asJSON {
+			if err != nil {
+				return fmt.Errorf("chain validation failed: %v", err)
+			}
+			fmt.Fprintf(out, "Chain is valid (%d blocks)\n", result.BlockCount)
+			return nil
+		}
+
+		if err := json.NewEncoder(out).Encode(result); err != nil {
+			return err
+		}
+		if !result.Valid {
+			return errChainInvalid
+		}
+		return nil
+	},
+}
+
+// blockchainMerkleCmd prints the Merkle tree of a block's certificates
+var blockchainMerkleCmd = &cobra.Command{
+	Use:   "merkle",
+	Short: "Print a block's Merkle tree",
+	Long: `Rebuild the Merkle tree of the block at --height from its certificate hashes and
+print every level from the leaves up to the root. When a level has an odd number
+of nodes its last node is paired with itself, shown as a duplicate entry.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		height, _ := cmd.Flags().GetInt("height")
+
+		chain, err := openLocalChain(cmd)
+		if err != nil {
+			return err
+		}
+		defer chain.Close()
+
+		block, err := chain.GetBlockByHeight(height)
+		if err != nil {
+			return err
+		}
+		levels, err := block.MerkleLevels()
+		if err != nil {
+			return err
+		}
+		printMerkleLevels(cmd.OutOrStdout(), block, levels)
+		return nil
+	},
+}
+
+// blockchainSigningHashCmd prints the digests a block's hash and signature are computed over
+var blockchainSigningHashCmd = &cobra.Command{
+	Use:   "signing-hash",
+	Short: "Print the digest a block's signature covers",
+	Long: `Print the signing hash of the block at --height, the digest its signer signed
+(CalculateHashForSigning), and its block hash recomputed from its fields
+(CalculateHash), to debug signature mismatches. The recomputed hash is compared
+with the stored one, and the signature is checked against the block's recorded
+public key when it has one.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		height, _ := cmd.Flags().GetInt("height")
+
+		chain, err := openLocalChain(cmd)
+		if err != nil {
+			return err
+		}
+		defer chain.Close()
+
+		block, err := chain.GetBlockByHeight(height)
+		if err != nil {
+			return err
+		}
+		printSigningHashes(cmd.OutOrStdout(), block)
+		return nil
+	},
+}
+
+// printSigningHashes prints block's signing hash and recomputed block hash
+func printSigningHashes(out io.Writer, block *blockchain.Block) {
+	fmt.Fprintf(out, "Block %d\n", block.Height)
+	fmt.Fprintf(out, "Signing hash: %x\n", block.CalculateHashForSigning())
+
+	hash := block.CalculateHash()
+	match := "matches the stored hash"
+	if !bytes.Equal(hash, block.Hash) {
+		match = fmt.Sprintf("differs from the stored hash %x", block.Hash)
+	}
+	fmt.Fprintf(out, "Block hash:   %x (%s)\n", hash, match)
+
+	switch {
+	case len(block.Signature) == 0:
+		fmt.Fprintln(out, "Signature:    none")
+	case len(block.PublicKey) == 0:
+		fmt.Fprintf(out, "Signature:    %x (no public key recorded to check it)\n", block.Signature)
+	default:
+		verdict := "invalid"
+		if publicKey, err := identity.DecodePublicKey(block.PublicKey); err == nil && block.Verify(publicKey) {
+			verdict = "valid"
+		}
+		fmt.Fprintf(out, "Signature:    %x (%s for the recorded public key)\n", block.Signature, verdict)
+	}
+}
+
+// printMerkleLevels prints levels from the leaves up to the root, followed by
+// whether the rebuilt root matches the block's stored Merkle root
+func printMerkleLevels(out io.Writer, block *blockchain.Block, levels [][][]byte) {
+	fmt.Fprintf(out, "Block %d Merkle tree (%d certificates)\n", block.Height, block.GetCertificateCount())
+	for depth, level := range levels {
+		label := ""
+		switch {
+		case depth == len(levels)-1:
+			label = " (root)"
+		case depth == 0:
+			label = " (leaves)"
+		}
+		fmt.Fprintf(out, "Level %d%s:\n", depth, label)
+		for i, node := range level {
+			fmt.Fprintf(out, "  [%d] %x\n", i, node)
+		}
+		if len(level) > 1 && len(level)%2 == 1 {
+			fmt.Fprintf(out, "  [%d] %x (duplicate of [%d])\n", len(level), level[len(level)-1], len(level)-1)
+		}
+	}
+
+	root := levels[len(levels)-1][0]
+	match := "matches the block's Merkle root"
+	if !bytes.Equal(root, block.MerkleRoot) {
+		match = fmt.Sprintf("does NOT match the block's Merkle root %x", block.MerkleRoot)
+	}
+	fmt.Fprintf(out, "Root: %x (%s)\n", root, match)
+}
+
+// reindexProgressInterval is how many blocks reindex processes between progress lines
+const reindexProgressInterval = 1000
+
+// blockchainReindexCmd rebuilds the chain's secondary indexes
+var blockchainReindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the chain's secondary indexes",
+	Long: `Walk the chain from genesis to tip and regenerate all secondary indexes (such as
+the height index) from the canonical block records. Safe to run repeatedly.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		chain, err := openLocalChain(cmd)
+		if err != nil {
+			return err
+		}
+		defer chain.Close()
+
+		out := cmd.OutOrStdout()
+		err = chain.Reindex(func(indexed, total int) {
+			if indexed%reindexProgressInterval == 0 || indexed == total {
+				fmt.Fprintf(out, "Indexed %d/%d blocks\n", indexed, total)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "Reindex complete")
+		return nil
+	},
+}
+
+// blockchainCompactCmd rewrites the chain database without dead data
+var blockchainCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Compact the chain database offline",
+	Long: `Copy all live keys of the chain database into a fresh Badger directory and swap it
+into place, reclaiming space held by dead data. The node must be stopped.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbPath, err := localDBPath(cmd)
+		if err != nil {
+			return err
+		}
+		result, err := blockchain.Compact(dbPath, blockchain.DefaultBlockchainOptions())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Compacted %s: %d bytes -> %d bytes\n", dbPath, result.SizeBefore, result.SizeAfter)
+		return nil
+	},
+}
+
+// blockchainDuCmd reports how much disk the chain uses
+var blockchainDuCmd = &cobra.Command{
+	Use:   "du",
+	Short: "Report the chain's size on disk",
+	Long: `Print the size of the chain's Badger directory, how many blocks and
+certificates it holds, and the average disk space per block. The size is
+measured after the database is closed, so the node should be stopped for an
+accurate figure.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbPath, err := localDBPath(cmd)
+		if err != nil {
+			return err
+		}
+		chain, err := openLocalChain(cmd)
+		if err != nil {
+			return err
+		}
+		stats := chain.GetStats()
+		chain.Close()
+
+		size, err := blockchain.DiskUsage(dbPath)
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Path:          %s\n", dbPath)
+		fmt.Fprintf(out, "Size:          %d bytes\n", size)
+		fmt.Fprintf(out, "Blocks:        %d\n", stats.BlockCount)
+		fmt.Fprintf(out, "Certificates:  %d\n", stats.CertificateCount)
+		if stats.BlockCount > 0 {
+			fmt.Fprintf(out, "Average block: %d bytes\n", size/int64(stats.BlockCount))
+		}
+		return nil
+	},
+}
+
+// blockchainDebugKeysCmd lists the raw keys of the chain database
+var blockchainDebugKeysCmd = &cobra.Command{
+	Use:    "debug-keys",
+	Short:  "List the raw database keys, for debugging",
+	Hidden: true,
+	Long: `List the raw Badger keys of the chain database with their value sizes, such as
+the lh tip pointer, the block records keyed by hash and the h- height index.
+Keys made only of printable characters are shown as text, others as 0x-prefixed
+hex. --prefix limits the listing to keys starting with the given text. The
+database is opened read-only, so the node must be stopped.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dbPath, err := localDBPath(cmd)
+		if err != nil {
+			return err
+		}
+		prefix, _ := cmd.Flags().GetString("prefix")
+		keys, err := blockchain.ListKeys(dbPath, []byte(prefix), blockchain.DefaultBlockchainOptions())
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		for _, key := range keys {
+			fmt.Fprintf(out, "%s\t%d bytes\n", formatRawKey(key.Key), key.ValueSize)
+		}
+		fmt.Fprintf(out, "%d keys\n", len(keys))
+		return nil
+	},
+}
+
+// formatRawKey returns key as text when every byte is printable ASCII, and as 0x-prefixed hex otherwise
+func formatRawKey(key []byte) string {
+	for _, b := range key {
+		if b < 0x20 || b > 0x7e {
+			return "0x" + hex.EncodeToString(key)
+		}
+	}
+	return string(key)
+}
+
+// openLocalChain opens the chain at --db-path, or at the signer's default path
+// when the flag is not set. It fails rather than creating a new chain, and
+// refuses chains whose tip pointer is orphaned.
+func openLocalChain(cmd *cobra.Command) (*blockchain.Blockchain, error) {
+	return openLocalChainWithOptions(cmd, blockchain.DefaultBlockchainOptions())
+}
+
+// openLocalChainWithOptions is openLocalChain opening the chain with options
+func openLocalChainWithOptions(cmd *cobra.Command, options blockchain.BlockchainOptions) (*blockchain.Blockchain, error) {
+	chain, err := openLocalChainUnchecked(cmd, options)
+	if err != nil {
+		return nil, err
+	}
+	if err := chain.CheckTip(); err != nil {
+		chain.Close()
+		return nil, fmt.Errorf("%v (run 'veritas blockchain repair-tip')", err)
+	}
+	return chain, nil
+}
+
+// openLocalChainUnchecked opens the local chain with options without verifying its tip
+func openLocalChainUnchecked(cmd *cobra.Command, options blockchain.BlockchainOptions) (*blockchain.Blockchain, error) {
+	dbPath, err := localDBPath(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if !blockchain.DBExists(dbPath) {
+		if err := blockchain.CheckInterruptedCompaction(dbPath); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("no blockchain found at %s", dbPath)
+	}
+	return blockchain.ContinueBlockchain(dbPath, options), nil
+}
+
+// blockchainExportCmd writes the local chain as NDJSON
+var blockchainExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the chain as NDJSON",
+	Long: `Write the local chain as NDJSON, one block per line from genesis to tip, the
+format accepted by a node's POST /import. --output names the file to write,
+creating missing parent directories; it defaults to - for stdout. An existing
+file is only replaced with --force.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		force, _ := cmd.Flags().GetBool("force")
+
+		chain, err := openLocalChain(cmd)
+		if err != nil {
+			return err
+		}
+		defer chain.Close()
+
+		if output == "-" {
+			return chain.Export(cmd.OutOrStdout())
+		}
+		return exportToFile(chain, output, force)
+	},
+}
+
+// blockchainVerifyExportCmd checks an export file without opening a database
+var blockchainVerifyExportCmd = &cobra.Command{
+	Use:   "verify-export",
+	Short: "Verify an exported chain file offline",
+	Long: `Check an NDJSON export, as written by blockchain export, before importing it:
+every block's hash, height and link to the block before it. With --signers, a
+registry file or URL in the authorized_signers.json format, every block must
+also be signed by an authorized signer. The first failure is reported.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString("file")
+		signersSource, _ := cmd.Flags().GetString("signers")
+
+		var signers identity.AuthorizedSigners
+		if signersSource != "" {
+			var err error
+			if signers, err = identity.LoadAuthorizedSigners(signersSource); err != nil {
+				return fmt.Errorf("failed to load signers: %v", err)
+			}
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		cmd.SilenceUsage = true
+		verified, err := blockchain.VerifyExport(file, signers)
+		if err != nil {
+			return fmt.Errorf("%s is invalid after %d good block(s): %v", path, verified, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s is valid (%d blocks)\n", path, verified)
+		return nil
+	},
+}
+
+// exportToFile exports chain to path, removing the file again if the export fails
+func exportToFile(chain *blockchain.Blockchain, path string, force bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(path, flags, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s already exists; use --force to overwrite it", path)
+	} else if err != nil {
+		return fmt.Errorf("failed to create %s: %v", path, err)
+	}
+
+	err = chain.Export(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to export to %s: %v", path, err)
+	}
+	return nil
+}
+
+// blockchainWatchCmd follows a running node and prints blocks as they are added
+var blockchainWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Print new blocks as a node adds them",
+	Long: `Poll a running node (--node) for its chain tip and print every block added
+after the command starts, like tail -f. With --json, prints each block header as
+one JSON object per line. Stops on Ctrl-C.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nodeURL, _ := cmd.Flags().GetString("node")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		if interval <= 0 {
+			return fmt.Errorf("invalid --interval %s: must be positive", interval)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		watcher := &blockWatcher{
+			client:  &http.Client{Timeout: 10 * time.Second},
+			nodeURL: strings.TrimSuffix(nodeURL, "/"),
+			out:     cmd.OutOrStdout(),
+			errOut:  cmd.ErrOrStderr(),
+			asJSON:  asJSON,
+		}
+		return watcher.run(ctx, interval)
+	},
+}
+
+// blockWatcher polls a node's /block/latest and prints the headers of new blocks
+type blockWatcher struct {
+	client  *http.Client
+	nodeURL string
+	out     io.Writer
+	errOut  io.Writer
+	asJSON  bool
+
+	// last is the height of the last block printed, or of the tip when watching began
+	last int
+}
+
+// run polls every interval until ctx is done. Failed polls are reported and
+// retried, so a restarting node does not end the watch.
+func (w *blockWatcher) run(ctx context.Context, interval time.Duration) error {
+	tip, err := w.fetchTip(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to reach node at %s: %v", w.nodeURL, err)
+	}
+	w.last = tip.Height
+	if !w.asJSON {
+		fmt.Fprintf(w.out, "Watching %s from height %d\n", w.nodeURL, w.last)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if err := w.poll(ctx); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(w.errOut, "poll failed: %v\n", err)
+		}
+	}
+}
+
+// poll prints every block between the last printed height and the node's tip
+func (w *blockWatcher) poll(ctx context.Context) error {
+	tip, err := w.fetchTip(ctx)
+	if err != nil {
+		return err
+	}
+	for w.last < tip.Height {
+		var headers []blockchain.BlockHeader
+		query := fmt.Sprintf("/headers/range?from=%d&to=%d", w.last+1, tip.Height)
+		if err := w.getJSON(ctx, query, &headers); err != nil {
+			return err
+		}
+		if len(headers) == 0 {
+			return fmt.Errorf("node returned no headers from height %d", w.last+1)
+		}
+		for i := range headers {
+			if err := w.print(&headers[i]); err != nil {
+				return err
+			}
+			w.last = headers[i].Height
+		}
+	}
+	return nil
+}
+
+func (w *blockWatcher) print(header *blockchain.BlockHeader) error {
+	if w.asJSON {
+		return json.NewEncoder(w.out).Encode(header)
+	}
+	fmt.Fprintf(w.out, "Block %d: Hash=%x, Certificates=%d, Address=%s, Time=%s\n",
+		header.Height, header.Hash, header.CertificateCount, string(header.UniversityAddress),
+		time.Unix(header.Timestamp, 0).UTC().Format(time.RFC3339))
+	return nil
+}
+
+func (w *blockWatcher) fetchTip(ctx context.Context) (*blockchain.BlockHeader, error) {
+	var tip blockchain.BlockHeader
+	if err := w.getJSON(ctx, "/block/latest", &tip); err != nil {
+		return nil, err
+	}
+	return &tip, nil
+}
+
+// getJSON fetches path from the node and decodes the JSON response into v
+func (w *blockWatcher) getJSON(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.nodeURL+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid response from GET %s: %v", path, err)
+	}
+	return nil
+}
+
+// localDBPath returns --db-path, or the signer's default path when the flag is not set
+func localDBPath(cmd *cobra.Command) (string, error) {
+	if dbPath, _ := cmd.Flags().GetString("db-path"); dbPath != "" {
+		return dbPath, nil
+	}
+	_ = godotenv.Load()
+	signer, err := identity.LoadSignerFromEnv()
+	if err != nil {
+		return "", fmt.Errorf("failed to load signer from env: %v", err)
+	}
+	if signer == nil {
+		return "", fmt.Errorf("--db-path or SIGNER_PRIVATE_KEY_HEX is required")
+	}
+	return signerChainPath(dataDirFromEnv(), signer), nil
+}
+
+func init() {
+	rootCmd.AddCommand(blockchainCmd)
+
+	blockchainCmd.PersistentFlags().String("db-path", "", "Blockchain database path (default is the signer's ./tmp/blocks_<address>)")
+
+	// Add blockchain subcommands
+	blockchainCmd.AddCommand(blockchainChainIDCmd)
+	blockchainCmd.AddCommand(blockchainInfoCmd)
+	blockchainCmd.AddCommand(blockchainListCmd)
+	blockchainCmd.AddCommand(blockchainRepairTipCmd)
+	blockchainCmd.AddCommand(blockchainRepairMerkleCmd)
+	blockchainCmd.AddCommand(blockchainReindexCmd)
+	blockchainCmd.AddCommand(blockchainCompactCmd)
+	blockchainCmd.AddCommand(blockchainDebugKeysCmd)
+	blockchainCmd.AddCommand(blockchainDuCmd)
+	blockchainCmd.AddCommand(blockchainValidateCmd)
+	blockchainCmd.AddCommand(blockchainMerkleCmd)
+	blockchainCmd.AddCommand(blockchainSigningHashCmd)
+	blockchainCmd.AddCommand(blockchainWatchCmd)
+	blockchainCmd.AddCommand(blockchainExportCmd)
+	blockchainCmd.AddCommand(blockchainVerifyExportCmd)
+	blockchainListCmd.AddCommand(blockchainListBlocksCmd)
+
+	blockchainListBlocksCmd.Flags().String("since", "", "Only list blocks timestamped at or after this RFC 3339 time")
+	blockchainListBlocksCmd.Flags().String("until", "", "Only list blocks timestamped at or before this RFC 3339 time")
+	blockchainValidateCmd.Flags().Bool("json", false, "Print the result as JSON")
+	blockchainValidateCmd.Flags().Int("window", 0, "Hold at most this many blocks in memory while validating (0 loads the whole chain)")
+	blockchainValidateCmd.Flags().Int("concurrency", 0, "Read blocks with this many goroutines ahead of the checks (0 reads them one at a time)")
+	blockchainValidateCmd.Flags().String("signers", "", "Authorized signers file or URL; also require each block, genesis included, to be signed by one")
+	blockchainValidateCmd.Flags().String("genesis-signer", "", "Address also accepted as the genesis block's signer with --signers")
+	blockchainMerkleCmd.Flags().Int("height", 0, "Height of the block to print")
+	_ = blockchainMerkleCmd.MarkFlagRequired("height")
+	blockchainSigningHashCmd.Flags().Int("height", 0, "Height of the block to print")
+	_ = blockchainSigningHashCmd.MarkFlagRequired("height")
+	blockchainDebugKeysCmd.Flags().String("prefix", "", "Only list keys starting with this text, e.g. h- for the height index")
+	blockchainExportCmd.Flags().StringP("output", "o", "-", "File to write the export to, or - for stdout")
+	blockchainExportCmd.Flags().Bool("force", false, "Overwrite --output if it already exists")
+	blockchainVerifyExportCmd.Flags().String("file", "", "NDJSON export to verify")
+	blockchainVerifyExportCmd.Flags().String("signers", "", "Authorized signers file or URL; also require each block to be signed by one")
+	_ = blockchainVerifyExportCmd.MarkFlagRequired("file")
+	blockchainWatchCmd.Flags().String("node", "http://localhost:8080", "Base URL of the node to watch")
+	blockchainWatchCmd.Flags().Duration("interval", 2*time.Second, "How often to poll the node for new blocks")
+	blockchainWatchCmd.Flags().Bool("json", false, "Print each new block header as a line of JSON")
+}
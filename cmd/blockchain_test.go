@@ -0,0 +1,555 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	"github.com/amanechibana/veritas-chain/identity"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// resetFlags restores every flag to its default so commands run in earlier tests don't leak state
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			_ = sv.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, child := range cmd.Commands() {
+		resetFlags(child)
+	}
+}
+
+// executeCommand runs the root command with args and returns its output
+func executeCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	resetFlags(rootCmd)
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetArgs(args)
+	defer rootCmd.SetArgs(nil)
+
+	err := rootCmd.Execute()
+	return out.String(), err
+}
+
+func TestInfoCommand(t *testing.T) {
+	dbPath := newTestDB(t, 2)
+	out, err := executeCommand(t, "blockchain", "info", "--db-path", dbPath)
+	if err != nil {
+		t.Fatalf("info failed: %v", err)
+	}
+	for _, want := range []string{"Height:  2 (3 blocks)", "Genesis: ", "Tip:     ", "Age:     "} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output %q", want, out)
+		}
+	}
+}
+
+func TestChainIDCommand(t *testing.T) {
+	dbPath := newTestDB(t, 1)
+	chain := blockchain.ContinueBlockchain(dbPath, blockchain.DefaultBlockchainOptions())
+	want, err := chain.ChainID()
+	chain.Close()
+	if err != nil {
+		t.Fatalf("chain ID: %v", err)
+	}
+
+	out, err := executeCommand(t, "blockchain", "chain-id", "--db-path", dbPath)
+	if err != nil {
+		t.Fatalf("chain-id failed: %v", err)
+	}
+	if strings.TrimSpace(out) != want {
+		t.Fatalf("expected chain ID %s, got %q", want, out)
+	}
+}
+
+func TestListBlocksSince(t *testing.T) {
+	dbPath := newTestDB(t, 2)
+
+	out, err := executeCommand(t, "blockchain", "list", "blocks", "--db-path", dbPath, "--since", "2000-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("list blocks failed: %v", err)
+	}
+	if !strings.Contains(out, "3 block(s)") {
+		t.Fatalf("expected all 3 blocks since 2000, got %q", out)
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	out, err = executeCommand(t, "blockchain", "list", "blocks", "--db-path", dbPath, "--since", future)
+	if err != nil {
+		t.Fatalf("list blocks failed: %v", err)
+	}
+	if !strings.Contains(out, "0 block(s)") {
+		t.Fatalf("expected no blocks since %s, got %q", future, out)
+	}
+
+	if _, err := executeCommand(t, "blockchain", "list", "blocks", "--db-path", dbPath, "--since", "yesterday"); err == nil {
+		t.Fatal("expected invalid --since to fail")
+	}
+
+	out, err = executeCommand(t, "blockchain", "list", "blocks", "--db-path", dbPath, "--until", "2000-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("list blocks failed: %v", err)
+	}
+	if !strings.Contains(out, "0 block(s)") {
+		t.Fatalf("expected no blocks until 2000, got %q", out)
+	}
+	if _, err := executeCommand(t, "blockchain", "list", "blocks", "--db-path", dbPath, "--since", future, "--until", "2000-01-01T00:00:00Z"); err == nil {
+		t.Fatal("expected --until before --since to fail")
+	}
+}
+
+func TestReindexRestoresHeightIndex(t *testing.T) {
+	dbPath := newTestDB(t, 3)
+
+	// Corrupt the height index: drop one entry, point another at the wrong
+	// block and add one above the tip
+	chain := blockchain.ContinueBlockchain(dbPath, blockchain.DefaultBlockchainOptions())
+	genesis, err := chain.GetBlockByHeight(0)
+	if err != nil {
+		t.Fatalf("genesis: %v", err)
+	}
+	err = chain.Database.Update(func(txn *badger.Txn) error {
+		if err := txn.Delete(heightIndexKey(2)); err != nil {
+			return err
+		}
+		if err := txn.Set(heightIndexKey(1), genesis.Hash); err != nil {
+			return err
+		}
+		return txn.Set(heightIndexKey(7), genesis.Hash)
+	})
+	chain.Close()
+	if err != nil {
+		t.Fatalf("corrupt index: %v", err)
+	}
+
+	// Reindexing twice must leave the same, correct index
+	for run := 0; run < 2; run++ {
+		out, err := executeCommand(t, "blockchain", "reindex", "--db-path", dbPath)
+		if err != nil {
+			t.Fatalf("reindex failed: %v", err)
+		}
+		if !strings.Contains(out, "Indexed 4/4 blocks") {
+			t.Fatalf("expected progress output, got %q", out)
+		}
+	}
+
+	chain = blockchain.ContinueBlockchain(dbPath, blockchain.DefaultBlockchainOptions())
+	defer chain.Close()
+	for height := 0; height <= 3; height++ {
+		block, err := chain.GetBlockByHeight(height)
+		if err != nil {
+			t.Fatalf("height %d: %v", height, err)
+		}
+		if block.Height != height {
+			t.Fatalf("height index %d points at block %d", height, block.Height)
+		}
+	}
+	if _, err := chain.GetBlockByHeight(7); err == nil {
+		t.Fatal("expected the entry above the tip to be removed")
+	}
+}
+
+// heightIndexKey mirrors the blockchain package's height index key layout
+func heightIndexKey(height int) []byte {
+	return append([]byte("h-"), blockchain.ToHex(int64(height))...)
+}
+
+func TestRepairMerkleCommand(t *testing.T) {
+	dbPath := t.TempDir()
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	chain := blockchain.InitBlockchain(dbPath, signer, blockchain.DefaultBlockchainOptions())
+	tip, err := chain.Tip()
+	if err != nil {
+		t.Fatalf("tip: %v", err)
+	}
+	// A block whose root was built by hashing the hex certificate hashes again
+	legacy := blockchain.NewBlock([]string{"CERT-A", "CERT-B", "CERT-C"}, tip.Hash, tip.Height+1, signer)
+	legacy.MerkleRoot = blockchain.NewMerkleTree(legacy.CertificateHashes).Root.Data
+	if err := legacy.SignWithSigner(signer); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	legacy.Hash = legacy.CalculateHash()
+	if err := chain.ImportBlock(legacy); err != nil {
+		t.Fatalf("import legacy block: %v", err)
+	}
+	chain.Close()
+
+	t.Setenv("SIGNER_PRIVATE_KEY_HEX", signer.PrivateKeyHex())
+	out, err := executeCommand(t, "blockchain", "repair-merkle", "--db-path", dbPath)
+	if err != nil {
+		t.Fatalf("repair-merkle: %v", err)
+	}
+	if !strings.Contains(out, "Fixed 1 Merkle roots") {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	chain = blockchain.ContinueBlockchain(dbPath, blockchain.DefaultBlockchainOptions())
+	block, err := chain.FindCertificate("CERT-B")
+	if err != nil || block == nil {
+		t.Fatalf("find certificate: %v", err)
+	}
+	proof, err := block.GenerateCertificateProof("CERT-B")
+	if err != nil || !block.VerifyCertificateWithProof("CERT-B", proof) {
+		t.Fatalf("proof does not verify after repair: %v", err)
+	}
+	chain.Close()
+
+	out, err = executeCommand(t, "blockchain", "repair-merkle", "--db-path", dbPath)
+	if err != nil || !strings.Contains(out, "nothing to repair") {
+		t.Fatalf("expected nothing left to repair, got %q, %v", out, err)
+	}
+}
+
+func TestValidateWithSigners(t *testing.T) {
+	dbPath := newTestDB(t, 2)
+	chain := blockchain.ContinueBlockchain(dbPath, blockchain.DefaultBlockchainOptions())
+	tip, err := chain.Tip()
+	chain.Close()
+	if err != nil {
+		t.Fatalf("tip: %v", err)
+	}
+	address := string(tip.UniversityAddress)
+
+	authorized := writeJSONFile(t, "signers.json", identity.AuthorizedSigners{"uni-a": address})
+	if out, err := executeCommand(t, "blockchain", "validate", "--db-path", dbPath, "--signers", authorized); err != nil {
+		t.Fatalf("validate with the signer authorized: %v (%s)", err, out)
+	}
+
+	other := identity.NewIdentitySigner(identity.MakeIdentity())
+	unauthorized := writeJSONFile(t, "signers.json", identity.AuthorizedSigners{"uni-b": string(other.Address())})
+	_, err = executeCommand(t, "blockchain", "validate", "--db-path", dbPath, "--signers", unauthorized)
+	if err == nil || !strings.Contains(err.Error(), "genesis block was signed by "+address) {
+		t.Fatalf("expected the genesis signer to be rejected, got %v", err)
+	}
+	_, err = executeCommand(t, "blockchain", "validate", "--db-path", dbPath, "--signers", unauthorized, "--genesis-signer", address)
+	if err == nil || !strings.Contains(err.Error(), "block 1 was signed by") {
+		t.Fatalf("expected block 1 to be rejected past an accepted genesis, got %v", err)
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	// Re-executed below as a subprocess to observe Execute's exit status
+	if dbPath := os.Getenv("VERITAS_TEST_VALIDATE_DB"); dbPath != "" {
+		rootCmd.SetArgs([]string{"blockchain", "validate", "--db-path", dbPath, "--json"})
+		Execute()
+		os.Exit(0)
+	}
+
+	dbPath := newTestDB(t, 3)
+
+	out, err := executeCommand(t, "blockchain", "validate", "--db-path", dbPath, "--json")
+	if err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	var result validateResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("decode %q: %v", out, err)
+	}
+	if !result.Valid || result.Error != "" || result.BlockCount != 4 {
+		t.Fatalf("unexpected result for a valid chain %+v", result)
+	}
+
+	// Tamper with block 2's certificates without re-signing it
+	chain := blockchain.ContinueBlockchain(dbPath, blockchain.DefaultBlockchainOptions())
+	block, err := chain.GetBlockByHeight(2)
+	if err != nil {
+		t.Fatalf("get block: %v", err)
+	}
+	block.CertificateHashes = []string{strings.Repeat("0", 64)}
+	err = chain.Database.Update(func(txn *badger.Txn) error {
+		return txn.Set(block.Hash, block.Serialize())
+	})
+	chain.Close()
+	if err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+
+	proc := exec.Command(os.Args[0], "-test.run=^TestValidateJSON$")
+	proc.Env = append(os.Environ(), "VERITAS_TEST_VALIDATE_DB="+dbPath)
+	var stderr bytes.Buffer
+	proc.Stderr = &stderr
+	stdout, err := proc.Output()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		t.Fatalf("expected exit status 1 for a tampered chain, got %v (stderr %q)", err, stderr.String())
+	}
+	result = validateResult{}
+	if err := json.Unmarshal(stdout, &result); err != nil {
+		t.Fatalf("output is not a single JSON object %q: %v", stdout, err)
+	}
+	if result.Valid || result.Error == "" || result.BlockCount != 4 {
+		t.Fatalf("unexpected result for a tampered chain %+v", result)
+	}
+}
+
+func TestMerkleCommandRootMatches(t *testing.T) {
+	dbPath := t.TempDir()
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	chain := blockchain.InitBlockchain(dbPath, signer, blockchain.DefaultBlockchainOptions())
+	// Five leaves leave an odd node at the leaf level and at the level above
+	block, err := chain.AddBlock([]string{"CERT-001", "CERT-002", "CERT-003", "CERT-004", "CERT-005"}, signer)
+	chain.Close()
+	if err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+
+	out, err := executeCommand(t, "blockchain", "merkle", "--height", "1", "--db-path", dbPath)
+	if err != nil {
+		t.Fatalf("merkle: %v\n%s", err, out)
+	}
+
+	wantRoot := fmt.Sprintf("Root: %x (matches the block's Merkle root)", block.MerkleRoot)
+	if !strings.Contains(out, wantRoot) {
+		t.Fatalf("expected %q in output:\n%s", wantRoot, out)
+	}
+	if !strings.Contains(out, "[5] ") || !strings.Contains(out, "(duplicate of [4])") {
+		t.Fatalf("expected the odd leaf to be shown duplicated:\n%s", out)
+	}
+	if !strings.Contains(out, "(duplicate of [2])") {
+		t.Fatalf("expected the odd node of level 1 to be shown duplicated:\n%s", out)
+	}
+}
+
+func TestSigningHashMatchesVerify(t *testing.T) {
+	dbPath := t.TempDir()
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	chain := blockchain.InitBlockchain(dbPath, signer, blockchain.DefaultBlockchainOptions())
+	block, err := chain.AddBlock([]string{"CERT-001", "CERT-002"}, signer)
+	chain.Close()
+	if err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+
+	out, err := executeCommand(t, "blockchain", "signing-hash", "--height", "1", "--db-path", dbPath)
+	if err != nil {
+		t.Fatalf("signing-hash: %v\n%s", err, out)
+	}
+	match := regexp.MustCompile(`Signing hash: ([0-9a-f]+)`).FindStringSubmatch(out)
+	if match == nil {
+		t.Fatalf("no signing hash in output:\n%s", out)
+	}
+	digest, err := hex.DecodeString(match[1])
+	if err != nil {
+		t.Fatalf("decode signing hash: %v", err)
+	}
+	if !identity.VerifySignature(signer.PublicKey(), digest, block.Signature) {
+		t.Fatalf("the block's signature does not verify over the printed signing hash %x", digest)
+	}
+	if !strings.Contains(out, fmt.Sprintf("Block hash:   %x (matches the stored hash)", block.Hash)) {
+		t.Fatalf("expected the block hash to match:\n%s", out)
+	}
+	if !strings.Contains(out, "(valid for the recorded public key)") {
+		t.Fatalf("expected the signature to be reported valid:\n%s", out)
+	}
+}
+
+func TestWatchPrintsNewBlocks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The stub's tip is at height 0 on the first poll and 2 afterwards. The
+	// third poll comes after the two new blocks were printed and stops the watch.
+	var polls atomic.Int32
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/block/latest":
+			height := 2
+			switch polls.Add(1) {
+			case 1:
+				height = 0
+			case 3:
+				cancel()
+			}
+			json.NewEncoder(w).Encode(blockchain.BlockHeader{Height: height})
+		case "/headers/range":
+			if r.URL.Query().Get("from") != "1" || r.URL.Query().Get("to") != "2" {
+				http.Error(w, "unexpected range", http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode([]blockchain.BlockHeader{
+				{Height: 1, Hash: []byte{0xaa}, CertificateCount: 3},
+				{Height: 2, Hash: []byte{0xbb}, CertificateCount: 1},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer node.Close()
+
+	var out, errOut bytes.Buffer
+	watcher := &blockWatcher{client: node.Client(), nodeURL: node.URL, out: &out, errOut: &errOut}
+	done := make(chan error, 1)
+	go func() { done <- watcher.run(ctx, 10*time.Millisecond) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("watch failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not stop")
+	}
+	if errOut.Len() != 0 {
+		t.Fatalf("unexpected poll errors:\n%s", errOut.String())
+	}
+	for _, want := range []string{"Block 1: Hash=aa, Certificates=3", "Block 2: Hash=bb, Certificates=1"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestExportToFileAndReimport(t *testing.T) {
+	dbPath := newTestDB(t, 3)
+	output := filepath.Join(t.TempDir(), "exports", "chain.ndjson")
+
+	if _, err := executeCommand(t, "blockchain", "export", "--db-path", dbPath, "--output", output); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if _, err := executeCommand(t, "blockchain", "export", "--db-path", dbPath, "--output", output); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected exporting over an existing file to fail without --force, got %v", err)
+	}
+	if _, err := executeCommand(t, "blockchain", "export", "--db-path", dbPath, "--output", output, "--force"); err != nil {
+		t.Fatalf("export with --force failed: %v", err)
+	}
+
+	file, err := os.Open(output)
+	if err != nil {
+		t.Fatalf("open export: %v", err)
+	}
+	defer file.Close()
+	imported := blockchain.InitBlockchain("", identity.NewIdentitySigner(identity.MakeIdentity()), blockchain.BlockchainOptions{InMemory: true, Logger: blockchain.NopLogger()})
+	defer imported.Close()
+	if err := imported.Reset(); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		var block blockchain.Block
+		if err := decoder.Decode(&block); err != nil {
+			t.Fatalf("decode export: %v", err)
+		}
+		if err := imported.ImportBlock(&block); err != nil {
+			t.Fatalf("import block %d: %v", block.Height, err)
+		}
+	}
+
+	original := blockchain.ContinueBlockchain(dbPath, blockchain.DefaultBlockchainOptions())
+	defer original.Close()
+	want, _ := original.Tip()
+	got, err := imported.Tip()
+	if err != nil || !bytes.Equal(got.Hash, want.Hash) {
+		t.Fatalf("re-imported tip %v does not match the original tip %x (%v)", got, want.Hash, err)
+	}
+}
+
+func TestVerifyExportCommand(t *testing.T) {
+	dbPath := newTestDB(t, 2)
+	output := filepath.Join(t.TempDir(), "chain.ndjson")
+	if _, err := executeCommand(t, "blockchain", "export", "--db-path", dbPath, "--output", output); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	out, err := executeCommand(t, "blockchain", "verify-export", "--file", output)
+	if err != nil {
+		t.Fatalf("verify clean export: %v", err)
+	}
+	if !strings.Contains(out, "is valid (3 blocks)") {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("read export: %v", err)
+	}
+	lines := strings.SplitAfter(strings.TrimSpace(string(data)), "\n")
+	// Swapping the last two blocks breaks the height sequence
+	corrupted := lines[0] + lines[2] + "\n" + lines[1]
+	if err := os.WriteFile(output, []byte(corrupted), 0o644); err != nil {
+		t.Fatalf("write corrupted export: %v", err)
+	}
+	if _, err := executeCommand(t, "blockchain", "verify-export", "--file", output); err == nil || !strings.Contains(err.Error(), "after 1 good block") {
+		t.Fatalf("expected the corrupted export to fail at block 1, got %v", err)
+	}
+}
+
+func TestDebugKeysListsTipAndBlocks(t *testing.T) {
+	dbPath := newTestDB(t, 2)
+	chain := blockchain.ContinueBlockchain(dbPath, blockchain.DefaultBlockchainOptions())
+	tip, err := chain.Tip()
+	if err != nil {
+		t.Fatalf("tip: %v", err)
+	}
+	chain.Close()
+
+	out, err := executeCommand(t, "blockchain", "debug-keys", "--db-path", dbPath)
+	if err != nil {
+		t.Fatalf("debug-keys: %v", err)
+	}
+	if !strings.Contains(out, "lh\t32 bytes\n") {
+		t.Errorf("expected the lh key in %q", out)
+	}
+	if !strings.Contains(out, "0x"+hex.EncodeToString(tip.Hash)+"\t") {
+		t.Errorf("expected the tip block key in %q", out)
+	}
+
+	out, err = executeCommand(t, "blockchain", "debug-keys", "--db-path", dbPath, "--prefix", "lh")
+	if err != nil {
+		t.Fatalf("debug-keys --prefix: %v", err)
+	}
+	if !strings.HasSuffix(out, "1 keys\n") {
+		t.Errorf("expected only the lh key, got %q", out)
+	}
+}
+
+func TestDuCommand(t *testing.T) {
+	dbPath := newTestDB(t, 3)
+
+	out, err := executeCommand(t, "blockchain", "du", "--db-path", dbPath)
+	if err != nil {
+		t.Fatalf("du: %v", err)
+	}
+	field := func(name string) int64 {
+		t.Helper()
+		m := regexp.MustCompile(`(?m)^` + name + `:\s+(\d+)`).FindStringSubmatch(out)
+		if m == nil {
+			t.Fatalf("no %s in %q", name, out)
+		}
+		n, _ := strconv.ParseInt(m[1], 10, 64)
+		return n
+	}
+	size, blocks, certs, average := field("Size"), field("Blocks"), field("Certificates"), field("Average block")
+	if blocks != 4 || certs != 3 {
+		t.Fatalf("expected 4 blocks and 3 certificates, got %d and %d", blocks, certs)
+	}
+	if size <= 0 || average <= 0 || average != size/blocks {
+		t.Fatalf("unexpected size %d and average %d for %d blocks", size, average, blocks)
+	}
+}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	"github.com/spf13/cobra"
+)
+
+// certCmd represents the cert command
+var certCmd = &cobra.Command{
+	Use:   "cert",
+	Short: "Certificate verification commands",
+	Long:  `Commands for verifying certificates against Veritas Chain blocks.`,
+}
+
+// certExportCmd writes a certificate's block header and Merkle proof as a
+// document that cert verify-offline can check without the chain
+var certExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export an offline-verifiable proof of a certificate",
+	Long: `Write a JSON document proving that a certificate is recorded on the local
+chain: the certificate ID, the chain ID, the header of the block recording it,
+a Merkle proof against that block's Merkle root and the block signer's public
+key. Check it anywhere with 'veritas cert verify-offline --document'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		certificateID, _ := cmd.Flags().GetString("id")
+		outFile, _ := cmd.Flags().GetString("out")
+		if err := blockchain.ValidateCertificateID(certificateID); err != nil {
+			return err
+		}
+
+		chain, err := openLocalChain(cmd)
+		if err != nil {
+			return err
+		}
+		defer chain.Close()
+
+		doc, err := chain.CertificateDocument(certificateID)
+		if err != nil {
+			return err
+		}
+		// Catch a document that would not verify before handing it to anyone
+		if err := blockchain.VerifyCertificateDocument(doc); err != nil {
+			return fmt.Errorf("exported proof does not verify: %v", err)
+		}
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode proof: %v", err)
+		}
+		if err := os.WriteFile(outFile, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", outFile, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Wrote proof of certificate %s in block %d to %s\n", certificateID, doc.Header.Height, outFile)
+		return nil
+	},
+}
+
+// certVerifyOfflineCmd checks that a certificate is recorded in a block read from a file
+var certVerifyOfflineCmd = &cobra.Command{
+	Use:   "verify-offline",
+	Short: "Verify a certificate against a block file",
+	Long: `Verify that a certificate is recorded in a single JSON-encoded block, without
+access to the chain. The block's hash and signature are checked first. With
+--proof, a JSON-encoded Merkle proof is also checked against the block's Merkle root.
+
+With --document instead of --block, check a proof written by 'veritas cert
+export'. --id is then optional, and --chain-id requires the document to come
+from that chain.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		blockFile, _ := cmd.Flags().GetString("block")
+		documentFile, _ := cmd.Flags().GetString("document")
+		certificateID, _ := cmd.Flags().GetString("id")
+		proofFile, _ := cmd.Flags().GetString("proof")
+		if (blockFile == "") == (documentFile == "") {
+			return fmt.Errorf("exactly one of --block and --document is required")
+		}
+		if documentFile != "" {
+			return verifyCertificateDocument(cmd, documentFile, certificateID)
+		}
+		if err := blockchain.ValidateCertificateID(certificateID); err != nil {
+			return err
+		}
+
+		var block blockchain.Block
+		if err := readJSONFile(blockFile, &block); err != nil {
+			return err
+		}
+		if err := block.Validate(); err != nil {
+			return fmt.Errorf("block %d is invalid: %v", block.Height, err)
+		}
+		if !block.VerifyCertificate(certificateID) {
+			return fmt.Errorf("certificate %q is not in block %d", certificateID, block.Height)
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Certificate %s is recorded in block %d (%x)\n", certificateID, block.Height, block.Hash)
+
+		if proofFile == "" {
+			return nil
+		}
+		var proof blockchain.MerkleProof
+		if err := readJSONFile(proofFile, &proof); err != nil {
+			return err
+		}
+		if len(proof.Siblings) != len(proof.Directions) {
+			return fmt.Errorf("invalid proof: %d siblings but %d directions", len(proof.Siblings), len(proof.Directions))
+		}
+		if !block.VerifyCertificateWithProof(certificateID, proof) {
+			return fmt.Errorf("proof for certificate %q does not match the Merkle root of block %d", certificateID, block.Height)
+		}
+		fmt.Fprintf(out, "Merkle proof verified against root %x\n", block.MerkleRoot)
+		return nil
+	},
+}
+
+// verifyCertificateDocument checks the document written by cert export at path
+func verifyCertificateDocument(cmd *cobra.Command, path, certificateID string) error {
+	chainID, _ := cmd.Flags().GetString("chain-id")
+
+	var doc blockchain.CertificateDocument
+	if err := readJSONFile(path, &doc); err != nil {
+		return err
+	}
+	if certificateID != "" && doc.CertificateID != certificateID {
+		return fmt.Errorf("document is for certificate %q, not %q", doc.CertificateID, certificateID)
+	}
+	if chainID != "" && !strings.EqualFold(doc.ChainID, chainID) {
+		return fmt.Errorf("document is for chain %s, not %s", doc.ChainID, chainID)
+	}
+	if err := blockchain.VerifyCertificateDocument(doc); err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Certificate %s is recorded in block %d (%x)\n", doc.CertificateID, doc.Header.Height, doc.Header.Hash)
+	fmt.Fprintf(out, "Signed by %s on chain %s\n", doc.Header.UniversityAddress, doc.ChainID)
+	return nil
+}
+
+// certVerifyCmd asks several nodes for a certificate's status and compares their answers
+var certVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify a certificate against several nodes",
+	Long: `Ask every node in --nodes for the status of a certificate and report each
+answer. The command fails when the nodes that answered disagree, on the status
+or on the block recording the certificate, or when no node could be reached.
+Unreachable nodes are reported separately and do not count as disagreement.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nodes, _ := cmd.Flags().GetStringSlice("nodes")
+		certificateID, _ := cmd.Flags().GetString("id")
+		if err := blockchain.ValidateCertificateID(certificateID); err != nil {
+			return err
+		}
+		if len(nodes) == 0 {
+			return fmt.Errorf("--nodes must list at least one node")
+		}
+
+		client := &http.Client{Timeout: 10 * time.Second}
+		results := queryCertificateStatus(cmd.Context(), client, nodes, certificateID)
+
+		out := cmd.OutOrStdout()
+		reachable := 0
+		for _, result := range results {
+			if result.err != nil {
+				fmt.Fprintf(out, "%s: unreachable: %v\n", result.node, result.err)
+				continue
+			}
+			reachable++
+			fmt.Fprintf(out, "%s: %s\n", result.node, describeCertificateStatus(result.status))
+		}
+		if reachable == 0 {
+			return fmt.Errorf("no node could be reached")
+		}
+		if !certificateStatusesAgree(results) {
+			return fmt.Errorf("nodes disagree on the status of certificate %q", certificateID)
+		}
+		fmt.Fprintf(out, "%d of %d nodes agree\n", reachable, len(results))
+		return nil
+	},
+}
+
+// nodeCertificateStatus is one node's answer to a certificate status query
+type nodeCertificateStatus struct {
+	node   string
+	status blockchain.CertificateStatus
+	// err is set when the node could not be reached or gave no usable answer
+	err error
+}
+
+// queryCertificateStatus asks each node for the status of certificateID,
+// concurrently, and returns the answers in the order of nodes
+func queryCertificateStatus(ctx context.Context, client *http.Client, nodes []string, certificateID string) []nodeCertificateStatus {
+	results := make([]nodeCertificateStatus, len(nodes))
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i].node = node
+			results[i].err = fetchCertificateStatus(ctx, client, node, certificateID, &results[i].status)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// fetchCertificateStatus decodes node's /cert-status answer for certificateID into status
+func fetchCertificateStatus(ctx context.Context, client *http.Client, node, certificateID string, status *blockchain.CertificateStatus) error {
+	target := strings.TrimSuffix(node, "/") + "/cert-status?id=" + url.QueryEscape(certificateID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET /cert-status returned %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(status); err != nil {
+		return fmt.Errorf("invalid response from GET /cert-status: %v", err)
+	}
+	return nil
+}
+
+// describeCertificateStatus formats a status for the per-node report
+func describeCertificateStatus(status blockchain.CertificateStatus) string {
+	if status.Status == blockchain.StatusUnknown {
+		return status.Status
+	}
+	description := fmt.Sprintf("%s (block %d)", status.Status, status.BlockHeight)
+	if status.SupersededBy != "" {
+		description += ", superseded by " + status.SupersededBy
+	}
+	return description
+}
+
+// certificateStatusesAgree reports whether every reachable node gave the same
+// status, block height and replacement
+func certificateStatusesAgree(results []nodeCertificateStatus) bool {
+	var first *blockchain.CertificateStatus
+	for i := range results {
+		if results[i].err != nil {
+			continue
+		}
+		status := &results[i].status
+		if first == nil {
+			first = status
+			continue
+		}
+		if status.Status != first.Status || status.BlockHeight != first.BlockHeight || status.SupersededBy != first.SupersededBy {
+			return false
+		}
+	}
+	return true
+}
+
+// readJSONFile decodes the JSON file at path into v
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", path, err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(certCmd)
+
+	certCmd.AddCommand(certExportCmd)
+	certCmd.AddCommand(certVerifyCmd)
+	certCmd.AddCommand(certVerifyOfflineCmd)
+
+	certExportCmd.Flags().String("id", "", "Certificate ID to export")
+	certExportCmd.Flags().String("out", "", "File to write the proof document to")
+	certExportCmd.Flags().String("db-path", "", "Blockchain database path (default is the signer's ./tmp/blocks_<address>)")
+	_ = certExportCmd.MarkFlagRequired("id")
+	_ = certExportCmd.MarkFlagRequired("out")
+
+	certVerifyCmd.Flags().StringSlice("nodes", nil, "Comma-separated node URLs to ask, e.g. http://a:8080,http://b:8080")
+	certVerifyCmd.Flags().String("id", "", "Certificate ID to look up")
+	_ = certVerifyCmd.MarkFlagRequired("nodes")
+	_ = certVerifyCmd.MarkFlagRequired("id")
+
+	certVerifyOfflineCmd.Flags().String("block", "", "JSON-encoded block file")
+	certVerifyOfflineCmd.Flags().String("id", "", "Certificate ID to look for")
+	certVerifyOfflineCmd.Flags().String("proof", "", "Optional JSON-encoded Merkle proof file")
+	certVerifyOfflineCmd.Flags().String("document", "", "Proof document written by 'veritas cert export', instead of --block")
+	certVerifyOfflineCmd.Flags().String("chain-id", "", "With --document, the chain ID the document must come from")
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+// writeJSONFile writes v as JSON to a file in a temp dir and returns its path
+func writeJSONFile(t *testing.T, name string, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("encode %s: %v", name, err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestCertVerifyOffline(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	block := blockchain.NewBlock([]string{"CERT-001", "CERT-002", "CERT-003"}, []byte{}, 0, signer)
+	blockFile := writeJSONFile(t, "block.json", block)
+
+	out, err := executeCommand(t, "cert", "verify-offline", "--block", blockFile, "--id", "CERT-002")
+	if err != nil {
+		t.Fatalf("verify present certificate: %v", err)
+	}
+	if !strings.Contains(out, "Certificate CERT-002 is recorded in block 0") {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	if _, err := executeCommand(t, "cert", "verify-offline", "--block", blockFile, "--id", "CERT-999"); err == nil ||
+		!strings.Contains(err.Error(), "not in block") {
+		t.Fatalf("expected absent certificate to fail, got %v", err)
+	}
+
+	proof, err := block.GenerateCertificateProof("CERT-002")
+	if err != nil {
+		t.Fatalf("generate proof: %v", err)
+	}
+	proofFile := writeJSONFile(t, "proof.json", proof)
+	out, err = executeCommand(t, "cert", "verify-offline", "--block", blockFile, "--id", "CERT-002", "--proof", proofFile)
+	if err != nil {
+		t.Fatalf("verify with proof: %v", err)
+	}
+	if !strings.Contains(out, "Merkle proof verified") {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	// A proof for another certificate in the same block does not match
+	otherProof, _ := block.GenerateCertificateProof("CERT-001")
+	otherFile := writeJSONFile(t, "other-proof.json", otherProof)
+	if _, err := executeCommand(t, "cert", "verify-offline", "--block", blockFile, "--id", "CERT-002", "--proof", otherFile); err == nil ||
+		!strings.Contains(err.Error(), "does not match the Merkle root") {
+		t.Fatalf("expected proof mismatch to fail, got %v", err)
+	}
+
+	tampered := *block
+	tampered.CertificateHashes = append([]string{}, block.CertificateHashes...)
+	tampered.CertificateHashes[0] = strings.Repeat("0", 64)
+	tamperedFile := writeJSONFile(t, "tampered.json", &tampered)
+	if _, err := executeCommand(t, "cert", "verify-offline", "--block", tamperedFile, "--id", "CERT-002"); err == nil {
+		t.Fatal("expected a tampered block to be rejected")
+	}
+}
+
+func TestCertExportVerifiesOffline(t *testing.T) {
+	dbPath := newTestDB(t, 3)
+	docFile := filepath.Join(t.TempDir(), "cert-proof.json")
+
+	out, err := executeCommand(t, "cert", "export", "--id", "CERT-001", "--out", docFile, "--db-path", dbPath)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if !strings.Contains(out, "block 2") {
+		t.Fatalf("unexpected output %q", out)
+	}
+	chainID, err := executeCommand(t, "blockchain", "chain-id", "--db-path", dbPath)
+	if err != nil {
+		t.Fatalf("chain-id: %v", err)
+	}
+	chainID = strings.TrimSpace(chainID)
+
+	// The chain is not needed from here on
+	if err := os.RemoveAll(dbPath); err != nil {
+		t.Fatalf("remove chain: %v", err)
+	}
+	out, err = executeCommand(t, "cert", "verify-offline", "--document", docFile, "--chain-id", chainID)
+	if err != nil {
+		t.Fatalf("verify document: %v", err)
+	}
+	if !strings.Contains(out, "Certificate CERT-001 is recorded in block 2") {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	if _, err := executeCommand(t, "cert", "verify-offline", "--document", docFile, "--id", "CERT-002"); err == nil {
+		t.Fatal("expected a document for another certificate to be rejected")
+	}
+	if _, err := executeCommand(t, "cert", "verify-offline", "--document", docFile, "--chain-id", strings.Repeat("0", 64)); err == nil ||
+		!strings.Contains(err.Error(), "chain") {
+		t.Fatalf("expected a chain ID mismatch to fail, got %v", err)
+	}
+
+	var doc blockchain.CertificateDocument
+	if err := readJSONFile(docFile, &doc); err != nil {
+		t.Fatalf("read document: %v", err)
+	}
+	doc.Header.Timestamp++
+	tamperedFile := writeJSONFile(t, "tampered.json", doc)
+	if _, err := executeCommand(t, "cert", "verify-offline", "--document", tamperedFile); err == nil {
+		t.Fatal("expected a tampered document to be rejected")
+	}
+}
+
+// stubStatusNode serves status as the /cert-status answer for every certificate
+func stubStatusNode(t *testing.T, status blockchain.CertificateStatus) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cert-status" || r.URL.Query().Get("id") != "CERT-001" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(status)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestCertVerifyAcrossNodes(t *testing.T) {
+	valid := blockchain.CertificateStatus{Status: blockchain.StatusValid, BlockHeight: 3}
+	nodeA := stubStatusNode(t, valid)
+	nodeB := stubStatusNode(t, valid)
+	unknown := stubStatusNode(t, blockchain.CertificateStatus{Status: blockchain.StatusUnknown})
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+
+	out, err := executeCommand(t, "cert", "verify", "--nodes", nodeA+","+nodeB, "--id", "CERT-001")
+	if err != nil {
+		t.Fatalf("consistent nodes: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, nodeA+": valid (block 3)") || !strings.Contains(out, "2 of 2 nodes agree") {
+		t.Fatalf("unexpected output for consistent nodes %q", out)
+	}
+
+	out, err = executeCommand(t, "cert", "verify", "--nodes", nodeA+","+downURL+","+nodeB, "--id", "CERT-001")
+	if err != nil {
+		t.Fatalf("an unreachable node must not count as disagreement: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, downURL+": unreachable") || !strings.Contains(out, "2 of 3 nodes agree") {
+		t.Fatalf("unexpected output with an unreachable node %q", out)
+	}
+
+	out, err = executeCommand(t, "cert", "verify", "--nodes", nodeA+","+unknown, "--id", "CERT-001")
+	if err == nil || !strings.Contains(err.Error(), "disagree") {
+		t.Fatalf("expected disagreement error, got %v", err)
+	}
+	if !strings.Contains(out, unknown+": unknown") {
+		t.Fatalf("unexpected output for inconsistent nodes %q", out)
+	}
+
+	if _, err := executeCommand(t, "cert", "verify", "--nodes", downURL, "--id", "CERT-001"); err == nil || !strings.Contains(err.Error(), "no node") {
+		t.Fatalf("expected error when no node is reachable, got %v", err)
+	}
+}
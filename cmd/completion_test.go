@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompletionCommand(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		out, err := executeCommand(t, "completion", shell)
+		if err != nil {
+			t.Fatalf("%s: completion failed: %v", shell, err)
+		}
+		if !strings.Contains(out, "veritas") {
+			t.Fatalf("%s: expected a completion script for veritas, got %d bytes", shell, len(out))
+		}
+	}
+
+	if _, err := executeCommand(t, "completion", "tcsh"); err == nil {
+		t.Fatal("expected an error for an unsupported shell")
+	}
+}
@@ -1,11 +1,14 @@
 package cmd
 
 import (
-	"crypto/ecdsa"
-	"crypto/elliptic"
-	"crypto/rand"
-	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"time"
 
 	"github.com/amanechibana/veritas-chain/identity"
 	"github.com/spf13/cobra"
@@ -24,23 +27,137 @@ var identityKeygenCmd = &cobra.Command{
 	Short: "Generate a new signer key",
 	Long:  `Generate a new P-256 private key and print SIGNER_PRIVATE_KEY_HEX and derived address.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		priv, addr := generateKeyAndAddress()
-		hexD := hex.EncodeToString(priv.D.Bytes())
-		fmt.Println("Generated signer key:")
-		fmt.Printf("  SIGNER_PRIVATE_KEY_HEX=%s\n", hexD)
-		fmt.Printf("  Address=%s\n", addr)
+		printSignerKey(cmd.OutOrStdout(), identity.NewIdentitySigner(identity.MakeIdentity()))
 	},
 }
 
-func generateKeyAndAddress() (*ecdsa.PrivateKey, string) {
-	curve := elliptic.P256()
-	priv, err := ecdsa.GenerateKey(curve, rand.Reader)
-	if err != nil {
-		panic(err)
+// printSignerKey prints the signer's key in SIGNER_PRIVATE_KEY_HEX form and its
+// address, as derived by the signer itself so it matches what the node reports
+func printSignerKey(out io.Writer, signer *identity.IdentitySigner) {
+	fmt.Fprintln(out, "Generated signer key:")
+	fmt.Fprintf(out, "  SIGNER_PRIVATE_KEY_HEX=%s\n", signer.PrivateKeyHex())
+	fmt.Fprintf(out, "  Address=%s\n", signer.Address())
+}
+
+// identityDiffCmd compares the addresses in two identities files
+var identityDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare the identities in two identities files",
+	Long: `List the addresses found only in identities file A, only in file B, and in
+both. Only addresses are printed; private keys are never shown.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pathA, _ := cmd.Flags().GetString("a")
+		pathB, _ := cmd.Flags().GetString("b")
+
+		a, err := identity.LoadIdentitiesFromFile(pathA)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %v", pathA, err)
+		}
+		b, err := identity.LoadIdentitiesFromFile(pathB)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %v", pathB, err)
+		}
+
+		onlyA, onlyB, both := diffIdentities(a, b)
+		out := cmd.OutOrStdout()
+		printAddresses(out, fmt.Sprintf("Only in %s", pathA), onlyA)
+		printAddresses(out, fmt.Sprintf("Only in %s", pathB), onlyB)
+		printAddresses(out, "In both", both)
+		return nil
+	},
+}
+
+// identityRotateCmd signs a rotation from the old signer key to the new one
+var identityRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Sign a rotation from an old signer key to a new one",
+	Long: `Write a key rotation record in which the old key signs over the new key's
+address, so verifiers can follow the university to its new key. With
+--identities, the identities file is updated too: the old identity is removed
+and the new one added. Restart the node with the new SIGNER_PRIVATE_KEY_HEX.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		oldHex, _ := cmd.Flags().GetString("old-hex")
+		newHex, _ := cmd.Flags().GetString("new-hex")
+		outFile, _ := cmd.Flags().GetString("out")
+		identitiesFile, _ := cmd.Flags().GetString("identities")
+
+		oldSigner, err := identity.NewP256SignerFromHexD(oldHex)
+		if err != nil {
+			return fmt.Errorf("invalid --old-hex: %v", err)
+		}
+		newSigner, err := identity.NewP256SignerFromHexD(newHex)
+		if err != nil {
+			return fmt.Errorf("invalid --new-hex: %v", err)
+		}
+		rotation, err := identity.NewKeyRotation(oldSigner, newSigner, time.Now().Unix())
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(rotation, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode rotation: %v", err)
+		}
+		if err := os.WriteFile(outFile, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", outFile, err)
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Wrote rotation from %s to %s to %s\n", rotation.OldAddress, rotation.NewAddress, outFile)
+		if identitiesFile == "" {
+			return nil
+		}
+		if err := rotateIdentities(identitiesFile, oldSigner, newSigner); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Updated %s: removed %s, added %s\n", identitiesFile, rotation.OldAddress, rotation.NewAddress)
+		return nil
+	},
+}
+
+// rotateIdentities replaces the old signer's identity with the new signer's
+// in the identities file at path, creating the file if it does not exist
+func rotateIdentities(path string, oldSigner, newSigner *identity.IdentitySigner) error {
+	identities, err := identity.LoadIdentitiesFromFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		identities = make(map[string]*identity.Identity)
+	} else if err != nil {
+		return fmt.Errorf("failed to load %s: %v", path, err)
+	}
+	delete(identities, string(oldSigner.Address()))
+	identities[string(newSigner.Address())] = newSigner.Identity()
+	if err := identity.SaveIdentitiesToFile(identities, path); err != nil {
+		return fmt.Errorf("failed to save %s: %v", path, err)
+	}
+	return nil
+}
+
+// diffIdentities splits the addresses of a and b into those only in a, only
+// in b and in both, each sorted
+func diffIdentities(a, b map[string]*identity.Identity) (onlyA, onlyB, both []string) {
+	for address := range a {
+		if _, ok := b[address]; ok {
+			both = append(both, address)
+		} else {
+			onlyA = append(onlyA, address)
+		}
+	}
+	for address := range b {
+		if _, ok := a[address]; !ok {
+			onlyB = append(onlyB, address)
+		}
+	}
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+	sort.Strings(both)
+	return onlyA, onlyB, both
+}
+
+// printAddresses prints a heading with the number of addresses, then one address per line
+func printAddresses(out io.Writer, heading string, addresses []string) {
+	fmt.Fprintf(out, "%s (%d):\n", heading, len(addresses))
+	for _, address := range addresses {
+		fmt.Fprintf(out, "  %s\n", address)
 	}
-	pubBytes := append(priv.PublicKey.X.Bytes(), priv.PublicKey.Y.Bytes()...)
-	id := &identity.Identity{PrivateKey: *priv, PublicKey: pubBytes}
-	return priv, string(id.Address())
 }
 
 func init() {
@@ -48,4 +165,19 @@ func init() {
 
 	// Add identity subcommands
 	identityCmd.AddCommand(identityKeygenCmd)
+	identityCmd.AddCommand(identityDiffCmd)
+	identityCmd.AddCommand(identityRotateCmd)
+
+	identityDiffCmd.Flags().String("a", "", "First identities file")
+	identityDiffCmd.Flags().String("b", "", "Second identities file")
+	_ = identityDiffCmd.MarkFlagRequired("a")
+	_ = identityDiffCmd.MarkFlagRequired("b")
+
+	identityRotateCmd.Flags().String("old-hex", "", "Current signer key, as SIGNER_PRIVATE_KEY_HEX")
+	identityRotateCmd.Flags().String("new-hex", "", "New signer key, as printed by 'veritas identity keygen'")
+	identityRotateCmd.Flags().String("out", "", "File to write the rotation record to")
+	identityRotateCmd.Flags().String("identities", "", "Identities file to move from the old identity to the new one")
+	_ = identityRotateCmd.MarkFlagRequired("old-hex")
+	_ = identityRotateCmd.MarkFlagRequired("new-hex")
+	_ = identityRotateCmd.MarkFlagRequired("out")
 }
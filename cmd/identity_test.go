@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+var keygenLine = regexp.MustCompile(`SIGNER_PRIVATE_KEY_HEX=([0-9a-f]+)\s+Address=(\S+)`)
+
+// checkKeygenOutput asserts that the printed address is the one a node loading the printed key derives
+func checkKeygenOutput(t *testing.T, out string) {
+	t.Helper()
+	m := keygenLine.FindStringSubmatch(out)
+	if m == nil {
+		t.Fatalf("unexpected keygen output %q", out)
+	}
+	signer, err := identity.NewP256SignerFromHexD(m[1])
+	if err != nil {
+		t.Fatalf("load printed key: %v", err)
+	}
+	if string(signer.Address()) != m[2] {
+		t.Fatalf("keygen printed address %s, but the key derives %s", m[2], signer.Address())
+	}
+}
+
+func TestKeygenAddressMatchesSigner(t *testing.T) {
+	out, err := executeCommand(t, "identity", "keygen")
+	if err != nil {
+		t.Fatalf("keygen failed: %v", err)
+	}
+	checkKeygenOutput(t, out)
+
+	// Keys whose public key has a short Y or X coordinate
+	for _, hexD := range []string{"2b", "017b"} {
+		signer, err := identity.NewP256SignerFromHexD(hexD)
+		if err != nil {
+			t.Fatalf("signer from hex: %v", err)
+		}
+		var buf bytes.Buffer
+		printSignerKey(&buf, signer)
+		checkKeygenOutput(t, buf.String())
+	}
+}
+
+func TestIdentityDiff(t *testing.T) {
+	shared := identity.MakeIdentity()
+	onlyA := identity.MakeIdentity()
+	onlyB := identity.MakeIdentity()
+
+	dir := t.TempDir()
+	pathA := filepath.Join(dir, "a.data")
+	pathB := filepath.Join(dir, "b.data")
+	if err := identity.SaveIdentitiesToFile(map[string]*identity.Identity{
+		string(shared.Address()): shared,
+		string(onlyA.Address()):  onlyA,
+	}, pathA); err != nil {
+		t.Fatalf("save A: %v", err)
+	}
+	if err := identity.SaveIdentitiesToFile(map[string]*identity.Identity{
+		string(shared.Address()): shared,
+		string(onlyB.Address()):  onlyB,
+	}, pathB); err != nil {
+		t.Fatalf("save B: %v", err)
+	}
+
+	out, err := executeCommand(t, "identity", "diff", "--a", pathA, "--b", pathB)
+	if err != nil {
+		t.Fatalf("diff failed: %v", err)
+	}
+	want := fmt.Sprintf("Only in %s (1):\n  %s\nOnly in %s (1):\n  %s\nIn both (1):\n  %s\n",
+		pathA, onlyA.Address(), pathB, onlyB.Address(), shared.Address())
+	if out != want {
+		t.Errorf("diff output:\n%s\nwant:\n%s", out, want)
+	}
+	for _, id := range []*identity.Identity{shared, onlyA, onlyB} {
+		if privateKey := id.ToSerializable().PrivateKeyD; strings.Contains(out, privateKey.String()) || strings.Contains(out, privateKey.Text(16)) {
+			t.Errorf("diff output exposes a private key")
+		}
+	}
+}
+
+func TestIdentityRotate(t *testing.T) {
+	oldSigner := identity.NewIdentitySigner(identity.MakeIdentity())
+	newSigner := identity.NewIdentitySigner(identity.MakeIdentity())
+	other := identity.MakeIdentity()
+
+	dir := t.TempDir()
+	identitiesPath := filepath.Join(dir, "identities.data")
+	if err := identity.SaveIdentitiesToFile(map[string]*identity.Identity{
+		string(oldSigner.Address()): oldSigner.Identity(),
+		string(other.Address()):     other,
+	}, identitiesPath); err != nil {
+		t.Fatalf("save identities: %v", err)
+	}
+	rotationPath := filepath.Join(dir, "rotation.json")
+
+	out, err := executeCommand(t, "identity", "rotate", "--old-hex", oldSigner.PrivateKeyHex(), "--new-hex", newSigner.PrivateKeyHex(),
+		"--out", rotationPath, "--identities", identitiesPath)
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if !strings.Contains(out, "Updated "+identitiesPath) {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	var rotation identity.KeyRotation
+	if err := readJSONFile(rotationPath, &rotation); err != nil {
+		t.Fatalf("read rotation: %v", err)
+	}
+	if rotation.OldAddress != string(oldSigner.Address()) || rotation.NewAddress != string(newSigner.Address()) {
+		t.Fatalf("unexpected rotation %s -> %s", rotation.OldAddress, rotation.NewAddress)
+	}
+	if !identity.VerifySignature(oldSigner.PublicKey(), rotation.CalculateHashForSigning(), rotation.Signature) {
+		t.Fatal("rotation record is not signed by the old key")
+	}
+	if err := rotation.Verify(); err != nil {
+		t.Fatalf("verify rotation: %v", err)
+	}
+
+	identities, err := identity.LoadIdentitiesFromFile(identitiesPath)
+	if err != nil {
+		t.Fatalf("load identities: %v", err)
+	}
+	if _, ok := identities[string(oldSigner.Address())]; ok {
+		t.Fatal("old identity still in the identities file")
+	}
+	if _, ok := identities[string(newSigner.Address())]; !ok {
+		t.Fatal("new identity missing from the identities file")
+	}
+	if _, ok := identities[string(other.Address())]; !ok {
+		t.Fatal("unrelated identity was removed")
+	}
+
+	if _, err := executeCommand(t, "identity", "rotate", "--old-hex", "zz", "--new-hex", newSigner.PrivateKeyHex(), "--out", rotationPath); err == nil ||
+		!strings.Contains(err.Error(), "--old-hex") {
+		t.Fatalf("expected an invalid old key to be rejected, got %v", err)
+	}
+	if _, err := executeCommand(t, "identity", "rotate", "--old-hex", newSigner.PrivateKeyHex(), "--new-hex", "00", "--out", rotationPath); err == nil ||
+		!strings.Contains(err.Error(), "--new-hex") {
+		t.Fatalf("expected an invalid new key to be rejected, got %v", err)
+	}
+}
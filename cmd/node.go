@@ -2,13 +2,21 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/amanechibana/veritas-chain/blockchain"
 	"github.com/amanechibana/veritas-chain/identity"
+	"github.com/amanechibana/veritas-chain/server"
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 )
@@ -27,70 +35,401 @@ var nodeInteractiveCmd = &cobra.Command{
 	Long: `Start a Veritas Chain node in interactive mode.
 This allows you to interact with the blockchain through a command-line interface.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// Load .env if present
-		_ = godotenv.Load()
+		policy, err := certificateIDPolicy(cmd)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		chain, signer, err := openNodeChain(blockchain.DefaultBlockchainOptions(), dataDirFromEnv())
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer chain.Close()
 
-		fmt.Printf("Configuration:\n")
+		// Start interactive mode
+		startInteractiveMode(chain, signer, policy)
+	},
+}
 
-		// Load signer from env (required)
-		signer, err := identity.LoadSignerFromEnv()
+// nodeStartCmd starts the node's HTTP API
+var nodeStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start node HTTP server",
+	Long: `Start a Veritas Chain node serving its blockchain over HTTP.
+The node shuts down gracefully on SIGINT/SIGTERM, finishing in-flight block writes before closing the database.
+
+For container deployments, VERITAS_PORT, VERITAS_UNIVERSITY, VERITAS_DATA_DIR and
+VERITAS_VERBOSE (also read from .env) set --port, --university, --data-dir and
+--verbose. Flags given on the command line take precedence.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := loadNodeStartConfig(cmd)
 		if err != nil {
-			fmt.Printf("Failed to load signer from env: %v\n", err)
+			fmt.Println(err)
 			return
 		}
-		if signer == nil {
-			fmt.Println("SIGNER_PRIVATE_KEY_HEX is required. Use 'veritas identity keygen' to generate one.")
+		readOnly := config.Server.ReadOnly
+		if err := checkSignerRegistry(cmd.OutOrStdout(), config.Server.Signers, config.PoA); err != nil {
+			fmt.Println(err)
 			return
 		}
 
-		addr := string(signer.Address())
-		fmt.Printf("  Address: %s\n", addr)
+		chain, signer, err := openNodeChain(config.Options, config.DataDir)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if err := checkUniversity(config.Server.Signers, config.Server.University, signer); err != nil {
+			fmt.Println(err)
+			chain.Close()
+			return
+		}
+		if config.Verbose {
+			for _, name := range config.EnvOverrides {
+				fmt.Printf("  Set from environment: %s\n", name)
+			}
+		}
 
-		// Compute per-signer DB path
-		dbPath := filepath.Join("./tmp", "blocks_"+addr)
-		fmt.Printf("  DB Path: %s\n", dbPath)
+		node := server.NewNode(chain, signer, config.Server)
 
-		// Optionally load authorized signers mapping and resolve name
-		if _, err := os.Stat("authorized_signers.json"); err == nil {
-			m, err := identity.LoadAuthorizedSigners("authorized_signers.json")
-			if err == nil {
-				if name, e2 := m.ResolveNameByAddress(addr); e2 == nil {
-					fmt.Printf("  Resolved Name: %s\n", name)
-				}
-			}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if err := node.Listen(); err != nil {
+			fmt.Printf("Failed to listen: %v\n", err)
+			chain.Close()
+			return
 		}
 
-		// Initialize or continue blockchain
-		var chain *blockchain.Blockchain
-		if blockchain.DBExists(dbPath) {
-			chain = blockchain.ContinueBlockchain(dbPath)
-			fmt.Println("Loaded existing blockchain")
-		} else {
-			chain = blockchain.InitBlockchain(dbPath, signer)
-			fmt.Println("Created new blockchain with genesis block")
+		errCh := make(chan error, 1)
+		go func() { errCh <- node.Serve() }()
+		scheme := "http"
+		if node.TLS() {
+			scheme = "https"
+		}
+		fmt.Printf("Node listening on %s://%s\n", scheme, node.Addr())
+		if addr := node.GRPCAddr(); addr != nil {
+			fmt.Printf("gRPC API listening on %s\n", addr)
+		}
+		if config.Server.Leader != "" {
+			fmt.Printf("Following leader %s every %s\n", config.Server.Leader, config.Server.FollowInterval)
+		}
+		if readOnly {
+			fmt.Println("Read-only mode: write endpoints are disabled")
 		}
-		defer chain.Close()
 
-		// Start interactive mode
-		startInteractiveMode(chain, signer)
+		select {
+		case err := <-errCh:
+			if err != nil {
+				fmt.Printf("Server error: %v\n", err)
+			}
+		case <-ctx.Done():
+			fmt.Println("Shutting down...")
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := node.Stop(shutdownCtx); err != nil {
+			fmt.Printf("Shutdown error: %v\n", err)
+		}
 	},
 }
 
+// Environment variables node start reads for settings whose flag is not given
+const (
+	envPort       = "VERITAS_PORT"
+	envUniversity = "VERITAS_UNIVERSITY"
+	envDataDir    = "VERITAS_DATA_DIR"
+	envVerbose    = "VERITAS_VERBOSE"
+
+	// envAuthorizedSigners replaces authorized_signers.json with another
+	// file or an http(s):// URL; it has no flag
+	envAuthorizedSigners = "VERITAS_AUTHORIZED_SIGNERS"
+)
+
+// defaultDataDir holds the per-signer chain databases unless --data-dir or VERITAS_DATA_DIR is set
+const defaultDataDir = "./tmp"
+
+// nodeStartConfig is the configuration node start resolves from its flags and the environment
+type nodeStartConfig struct {
+	Server  server.Config
+	Options blockchain.BlockchainOptions
+	DataDir string
+	Verbose bool
+
+	// PoA is the --poa mode, how startup treats an empty signer registry
+	PoA string
+
+	// EnvOverrides names the environment variables that supplied a setting
+	EnvOverrides []string
+}
+
+// loadNodeStartConfig resolves node start's flags, falling back to the VERITAS_*
+// environment variables (after loading .env) for flags not given on the command line
+func loadNodeStartConfig(cmd *cobra.Command) (nodeStartConfig, error) {
+	_ = godotenv.Load()
+
+	var config nodeStartConfig
+	flags := cmd.Flags()
+	config.Server.Port, _ = flags.GetInt("port")
+	config.Server.ListenAddr, _ = flags.GetString("listen")
+	config.Server.GRPCPort, _ = flags.GetInt("grpc-port")
+	config.Server.ReadOnly, _ = flags.GetBool("read-only")
+	config.Server.TLSCertFile, _ = flags.GetString("tls-cert")
+	config.Server.TLSKeyFile, _ = flags.GetString("tls-key")
+	config.Server.TLSSelfSigned, _ = flags.GetBool("tls-self-signed")
+	config.Server.FlushInterval, _ = flags.GetDuration("flush-interval")
+	config.Server.University, _ = flags.GetString("university")
+	config.Server.Peers, _ = flags.GetStringSlice("peer")
+	config.Server.PeerCheckInterval, _ = flags.GetDuration("peer-check-interval")
+	config.Server.Leader, _ = flags.GetString("follow")
+	config.Server.FollowInterval, _ = flags.GetDuration("follow-interval")
+	config.Server.MaxRequestBodyBytes, _ = flags.GetInt64("max-request-body")
+	config.Server.MaxImportBodyBytes, _ = flags.GetInt64("max-import-body")
+	if config.Server.Leader != "" {
+		config.Server.ReadOnly = true
+	}
+	signers, err := loadAuthorizedSigners()
+	if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "WARNING: %v\n", err)
+	}
+	config.Server.Signers = signers
+	if config.Server.Leader != "" && len(signers) == 0 {
+		return config, fmt.Errorf("--follow needs the authorized signer registry (%s or %s) to check the leader's blocks", authorizedSignersFile, envAuthorizedSigners)
+	}
+	policy, err := certificateIDPolicy(cmd)
+	if err != nil {
+		return config, err
+	}
+	config.Server.CertificateIDPolicy = policy
+	config.DataDir, _ = flags.GetString("data-dir")
+	config.Verbose, _ = flags.GetBool("verbose")
+	config.PoA, _ = flags.GetString("poa")
+
+	config.Options = blockchain.DefaultBlockchainOptions()
+	config.Options.BlockCacheSize, _ = flags.GetInt("block-cache-size")
+	config.Options.BlockCacheTTL, _ = flags.GetDuration("block-cache-ttl")
+	config.Options.MinBlockInterval, _ = flags.GetDuration("min-block-interval")
+	config.Options.SortCertificates, _ = flags.GetBool("sort-certificates")
+
+	if value, ok := envOverride(cmd, "port", envPort); ok {
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 0 || port > 65535 {
+			return config, fmt.Errorf("invalid %s %q: must be a port number between 0 and 65535", envPort, value)
+		}
+		config.Server.Port = port
+		config.EnvOverrides = append(config.EnvOverrides, envPort)
+	}
+	if value, ok := envOverride(cmd, "university", envUniversity); ok {
+		config.Server.University = value
+		config.EnvOverrides = append(config.EnvOverrides, envUniversity)
+	}
+	if value, ok := envOverride(cmd, "data-dir", envDataDir); ok {
+		config.DataDir = value
+		config.EnvOverrides = append(config.EnvOverrides, envDataDir)
+	}
+	if value, ok := envOverride(cmd, "verbose", envVerbose); ok {
+		verbose, err := strconv.ParseBool(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid %s %q: must be true or false", envVerbose, value)
+		}
+		config.Verbose = verbose
+		config.EnvOverrides = append(config.EnvOverrides, envVerbose)
+	}
+
+	if config.Verbose {
+		config.Server.TraceLogger = slog.New(slog.NewTextHandler(os.Stderr, nil))
+	}
+
+	switch config.PoA {
+	case poaOff, poaWarn, poaEnforce:
+	default:
+		return config, fmt.Errorf("invalid --poa %q: expected %s, %s or %s", config.PoA, poaOff, poaWarn, poaEnforce)
+	}
+	if config.Server.ListenAddr != "" {
+		if err := server.ValidateListenAddr(config.Server.ListenAddr); err != nil {
+			return config, err
+		}
+	}
+	return config, nil
+}
+
+// certificateIDPolicy builds the certificate ID policy from the --cert-id-max-length and --cert-id-charset flags
+func certificateIDPolicy(cmd *cobra.Command) (*blockchain.CertificateIDPolicy, error) {
+	maxLength, _ := cmd.Flags().GetInt("cert-id-max-length")
+	charset, _ := cmd.Flags().GetString("cert-id-charset")
+	return blockchain.NewCertificateIDPolicy(maxLength, charset)
+}
+
+// envOverride returns the value of envVar when flag was not given on the command line and envVar is set
+func envOverride(cmd *cobra.Command, flag, envVar string) (string, bool) {
+	if cmd.Flags().Changed(flag) {
+		return "", false
+	}
+	value := os.Getenv(envVar)
+	return value, value != ""
+}
+
+// checkUniversity fails when signers registers university under an address
+// other than the signer's. A university the registry does not list is accepted.
+func checkUniversity(signers identity.AuthorizedSigners, university string, signer identity.Signer) error {
+	if university == "" {
+		return nil
+	}
+	if addr, ok := signers[university]; ok && addr != string(signer.Address()) {
+		return fmt.Errorf("university %q is registered to %s, not this node's signer %s", university, addr, signer.Address())
+	}
+	return nil
+}
+
+// shutdownTimeout bounds how long node start waits for in-flight requests on exit
+const shutdownTimeout = 10 * time.Second
+
+// --poa modes: how node start treats a missing or empty authorized signer registry
+const (
+	poaOff     = "off"
+	poaWarn    = "warn"
+	poaEnforce = "enforce"
+)
+
+// checkSignerRegistry checks that a PoA node has authorized signers to
+// validate blocks against. With --poa warn a missing or empty registry is
+// reported on out; with --poa enforce it is an error.
+func checkSignerRegistry(out io.Writer, signers identity.AuthorizedSigners, mode string) error {
+	if mode == poaOff || len(signers) > 0 {
+		return nil
+	}
+	problem := fmt.Sprintf("the authorized signer registry (%s or %s) is missing or empty, so no block signer can be checked", authorizedSignersFile, envAuthorizedSigners)
+	if mode == poaEnforce {
+		return fmt.Errorf("refusing to start: %s", problem)
+	}
+	fmt.Fprintf(out, "WARNING: %s\n", problem)
+	return nil
+}
+
+// authorizedSignersFile maps university names to signer addresses
+const authorizedSignersFile = "authorized_signers.json"
+
+// loadAuthorizedSigners loads the authorized signers mapping from
+// VERITAS_AUTHORIZED_SIGNERS or authorized_signers.json. It returns nil if
+// neither is set, and an error naming the source if it cannot be read or fetched.
+func loadAuthorizedSigners() (identity.AuthorizedSigners, error) {
+	source := os.Getenv(envAuthorizedSigners)
+	if source == "" {
+		if _, err := os.Stat(authorizedSignersFile); err != nil {
+			return nil, nil
+		}
+		source = authorizedSignersFile
+	}
+	signers, err := identity.LoadAuthorizedSigners(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load authorized signers from %s: %v", source, err)
+	}
+	return signers, nil
+}
+
+// openNodeChain loads the signer from the environment and opens (or creates) its blockchain
+func openNodeChain(options blockchain.BlockchainOptions, dataDir string) (*blockchain.Blockchain, identity.Signer, error) {
+	// Load .env if present
+	_ = godotenv.Load()
+
+	fmt.Printf("Configuration:\n")
+
+	// Load signer from env (required)
+	signer, err := identity.LoadSignerFromEnv()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to load signer from env: %v", err)
+	}
+	if signer == nil {
+		return nil, nil, fmt.Errorf("SIGNER_PRIVATE_KEY_HEX is required. Use 'veritas identity keygen' to generate one.")
+	}
+
+	addr := string(signer.Address())
+	fmt.Printf("  Address: %s\n", addr)
+
+	// Compute per-signer DB path
+	dbPath := signerChainPath(dataDir, signer)
+	fmt.Printf("  DB Path: %s\n", dbPath)
+
+	// Optionally resolve the signer's name from the authorized signers mapping
+	signers, _ := loadAuthorizedSigners()
+	if name, err := signers.ResolveNameByAddress(addr); err == nil {
+		fmt.Printf("  Resolved Name: %s\n", name)
+	}
+
+	// Initialize or continue blockchain, unless a compaction left the only
+	// copy of the chain aside
+	var chain *blockchain.Blockchain
+	if !blockchain.DBExists(dbPath) {
+		if err := blockchain.CheckInterruptedCompaction(dbPath); err != nil {
+			return nil, nil, err
+		}
+	}
+	if blockchain.DBExists(dbPath) {
+		chain = blockchain.ContinueBlockchain(dbPath, options)
+		fmt.Println("Loaded existing blockchain")
+	} else {
+		chain = blockchain.InitBlockchain(dbPath, signer, options)
+		fmt.Println("Created new blockchain with genesis block")
+	}
+
+	// Refuse to run on a chain whose tip pointer references a missing block
+	if err := chain.CheckTip(); err != nil {
+		chain.Close()
+		return nil, nil, fmt.Errorf("%v (run 'veritas blockchain repair-tip --db-path %s')", err, dbPath)
+	}
+	return chain, signer, nil
+}
+
+// signerDBPath returns the per-signer database directory under dataDir for a signer address
+func signerDBPath(dataDir, addr string) string {
+	return filepath.Join(dataDir, "blocks_"+addr)
+}
+
+// dataDirFromEnv returns VERITAS_DATA_DIR, or the default data directory when it is not set
+func dataDirFromEnv() string {
+	if dir := os.Getenv(envDataDir); dir != "" {
+		return dir
+	}
+	return defaultDataDir
+}
+
+// signerChainPath returns the signer's database directory. Chains created
+// before addresses used a fixed-width key encoding live under the legacy
+// address, which differs for a few keys; such an existing chain is kept.
+func signerChainPath(dataDir string, signer identity.Signer) string {
+	path := signerDBPath(dataDir, string(signer.Address()))
+	legacy := signerDBPath(dataDir, string(identity.LegacyAddressFromPublicKey(signer.PublicKey())))
+	if legacy != path && !blockchain.DBExists(path) && blockchain.DBExists(legacy) {
+		return legacy
+	}
+	return path
+}
+
 // startInteractiveMode starts the interactive terminal
-func startInteractiveMode(chain *blockchain.Blockchain, signer identity.Signer) {
-	reader := bufio.NewReader(os.Stdin)
+func startInteractiveMode(chain *blockchain.Blockchain, signer identity.Signer, policy *blockchain.CertificateIDPolicy) {
+	runInteractive(os.Stdin, os.Stdout, chain, signer, policy)
+}
+
+// runInteractive reads commands from in and writes their results to out until
+// exit or EOF. Added certificate IDs must satisfy policy.
+func runInteractive(in io.Reader, out io.Writer, chain *blockchain.Blockchain, signer identity.Signer, policy *blockchain.CertificateIDPolicy) {
+	reader := bufio.NewReader(in)
 
-	fmt.Println("\n=== Veritas Chain Interactive Mode ===")
-	fmt.Println("Type 'help' for available commands")
-	fmt.Printf("Signer Address: %s\n", string(signer.Address()))
-	fmt.Println("=====================================")
+	fmt.Fprintln(out, "\n=== Veritas Chain Interactive Mode ===")
+	fmt.Fprintln(out, "Type 'help' for available commands")
+	fmt.Fprintf(out, "Signer Address: %s\n", string(signer.Address()))
+	fmt.Fprintln(out, "=====================================")
 
 	for {
-		fmt.Print("veritas> ")
-		input, _ := reader.ReadString('\n')
+		fmt.Fprint(out, "veritas> ")
+		input, err := reader.ReadString('\n')
 		input = strings.TrimSpace(input)
 
 		if input == "" {
+			if err != nil {
+				return
+			}
 			continue
 		}
 
@@ -99,83 +438,153 @@ func startInteractiveMode(chain *blockchain.Blockchain, signer identity.Signer)
 
 		switch command {
 		case "help":
-			showHelp()
+			showHelp(out)
 		case "add":
 			if len(parts) < 2 {
-				fmt.Println("Usage: add <certificate1,certificate2,...>")
+				fmt.Fprintln(out, "Usage: add <certificate1,certificate2,...>")
 				continue
 			}
-			certificates := strings.Split(parts[1], ",")
-			addBlock(chain, signer, certificates)
+			certificates, err := parseCertificateIDs(strings.Join(parts[1:], " "))
+			if err != nil {
+				fmt.Fprintf(out, "Failed to add block: %v\n", err)
+				continue
+			}
+			addBlock(out, chain, signer, policy, certificates)
 		case "list":
-			listBlocks(chain)
+			limit, reverse, err := parseListArgs(parts[1:])
+			if err != nil {
+				fmt.Fprintf(out, "%v\nUsage: list [limit] [--reverse]\n", err)
+				continue
+			}
+			listBlocks(out, chain, limit, reverse)
 		case "validate":
-			validateChain(chain)
+			validateChain(out, chain)
 		case "stats":
-			showStats(chain)
+			showStats(out, chain)
 		case "exit", "quit":
-			fmt.Println("Goodbye!")
+			fmt.Fprintln(out, "Goodbye!")
 			return
 		default:
-			fmt.Printf("Unknown command: %s. Type 'help' for available commands.\n", command)
+			fmt.Fprintf(out, "Unknown command: %s. Type 'help' for available commands.\n", command)
 		}
 	}
 }
 
-func showHelp() {
-	fmt.Println("Available commands:")
-	fmt.Println("  add <cert1,cert2,...>  - Add a new block with certificates")
-	fmt.Println("  list                   - List all blocks")
-	fmt.Println("  validate               - Validate the blockchain")
-	fmt.Println("  stats                  - Show blockchain statistics")
-	fmt.Println("  help                   - Show this help message")
-	fmt.Println("  exit/quit              - Exit interactive mode")
+func showHelp(out io.Writer) {
+	fmt.Fprintln(out, "Available commands:")
+	fmt.Fprintln(out, "  add <cert1,cert2,...>  - Add a new block with certificates")
+	fmt.Fprintln(out, "  list [n] [--reverse]   - List n blocks (default 10), oldest first with --reverse")
+	fmt.Fprintln(out, "  validate               - Validate the blockchain")
+	fmt.Fprintln(out, "  stats                  - Show blockchain statistics")
+	fmt.Fprintln(out, "  help                   - Show this help message")
+	fmt.Fprintln(out, "  exit/quit              - Exit interactive mode")
+}
+
+// parseCertificateIDs splits a comma-separated certificate list, trimming
+// whitespace around each ID and rejecting empty entries such as "a,,b".
+func parseCertificateIDs(arg string) ([]string, error) {
+	ids := strings.Split(arg, ",")
+	for i, id := range ids {
+		ids[i] = strings.TrimSpace(id)
+		if ids[i] == "" {
+			return nil, fmt.Errorf("empty certificate ID at position %d in %q", i+1, arg)
+		}
+	}
+	return ids, nil
 }
 
-func addBlock(chain *blockchain.Blockchain, signer identity.Signer, certificates []string) {
+func addBlock(out io.Writer, chain *blockchain.Blockchain, signer identity.Signer, policy *blockchain.CertificateIDPolicy, certificates []string) {
+	if err := policy.ValidateAll(certificates); err != nil {
+		fmt.Fprintf(out, "Failed to add block: %v\n", err)
+		return
+	}
+
 	block, err := chain.AddBlock(certificates, signer)
 
 	if err != nil {
-		fmt.Printf("Failed to add block: %v\n", err)
+		fmt.Fprintf(out, "Failed to add block: %v\n", err)
 		return
 	}
-	fmt.Printf("Block added successfully!\n")
-	fmt.Printf("   Height: %d\n", block.Height)
-	fmt.Printf("   Hash: %x\n", block.Hash)
-	fmt.Printf("   Address: %s\n", string(block.UniversityAddress))
+	fmt.Fprintf(out, "Block added successfully!\n")
+	fmt.Fprintf(out, "   Height: %d\n", block.Height)
+	fmt.Fprintf(out, "   Hash: %x\n", block.Hash)
+	fmt.Fprintf(out, "   Address: %s\n", string(block.UniversityAddress))
 }
 
-func listBlocks(chain *blockchain.Blockchain) {
-	iter := chain.Iterator()
-	blockCount := 0
+// defaultListLimit is the number of blocks shown by list when no limit is given
+const defaultListLimit = 10
 
-	fmt.Println("Blockchain:")
-	for blockCount < 10 { // Limit to 10 blocks
-		block := iter.Next()
-		fmt.Printf("Block %d: Height=%d, Hash=%x, Address=%s\n",
-			blockCount, block.Height, block.Hash, string(block.UniversityAddress))
+// parseListArgs parses the optional "[limit] [--reverse]" arguments of the list command
+func parseListArgs(args []string) (limit int, reverse bool, err error) {
+	limit = defaultListLimit
+	limitSet := false
+	for _, arg := range args {
+		if arg == "--reverse" {
+			reverse = true
+			continue
+		}
+		if limitSet {
+			return 0, false, fmt.Errorf("unexpected argument: %s", arg)
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			return 0, false, fmt.Errorf("invalid limit %q: must be a positive integer", arg)
+		}
+		limit = n
+		limitSet = true
+	}
+	return limit, reverse, nil
+}
+
+// listBlocks prints up to limit blocks, newest first or, with reverse, oldest first
+func listBlocks(out io.Writer, chain *blockchain.Blockchain, limit int, reverse bool) {
+	fmt.Fprintln(out, "Blockchain:")
+	if reverse {
+		tip, err := chain.Tip()
+		if err != nil {
+			fmt.Fprintf(out, "Failed to load chain tip: %v\n", err)
+			return
+		}
+		for height := 0; height <= tip.Height && height < limit; height++ {
+			block, err := chain.GetBlockByHeight(height)
+			if err != nil {
+				fmt.Fprintf(out, "Failed to load block: %v\n", err)
+				return
+			}
+			printBlockLine(out, height, block)
+		}
+		return
+	}
 
-		blockCount++
+	iter := chain.Iterator()
+	for blockCount := 0; blockCount < limit; blockCount++ {
+		block := iter.Next()
+		printBlockLine(out, blockCount, block)
 		if len(block.PrevHash) == 0 {
 			break
 		}
 	}
 }
 
-func validateChain(chain *blockchain.Blockchain) {
+func printBlockLine(out io.Writer, index int, block *blockchain.Block) {
+	fmt.Fprintf(out, "Block %d: Height=%d, Hash=%x, Address=%s\n",
+		index, block.Height, block.Hash, string(block.UniversityAddress))
+}
+
+func validateChain(out io.Writer, chain *blockchain.Blockchain) {
 	err := chain.ValidateChain()
 	if err != nil {
-		fmt.Printf("  Chain validation failed: %v\n", err)
+		fmt.Fprintf(out, "  Chain validation failed: %v\n", err)
 	} else {
-		fmt.Println("  Chain validation successful")
+		fmt.Fprintln(out, "  Chain validation successful")
 	}
 }
 
-func showStats(chain *blockchain.Blockchain) {
+func showStats(out io.Writer, chain *blockchain.Blockchain) {
 	stats := chain.GetStats()
-	fmt.Printf("Blockchain Statistics:\n")
-	fmt.Printf("  Total Blocks: %d\n", stats.BlockCount)
-	fmt.Printf("  Total Certificates: %d\n", stats.CertificateCount)
+	fmt.Fprintf(out, "Blockchain Statistics:\n")
+	fmt.Fprintf(out, "  Total Blocks: %d\n", stats.BlockCount)
+	fmt.Fprintf(out, "  Total Certificates: %d\n", stats.CertificateCount)
 }
 
 func init() {
@@ -183,4 +592,29 @@ func init() {
 
 	// Add node subcommands
 	nodeCmd.AddCommand(nodeInteractiveCmd)
+	nodeCmd.AddCommand(nodeStartCmd)
+
+	nodeCmd.PersistentFlags().Int("cert-id-max-length", blockchain.DefaultCertificateIDMaxLength, "Longest certificate ID accepted, in bytes (0 disables the limit)")
+	nodeCmd.PersistentFlags().String("cert-id-charset", "", "Characters allowed in certificate IDs, as a regular expression character class such as "+blockchain.StrictCertificateIDCharset+" (empty allows any)")
+	nodeStartCmd.Flags().IntP("port", "p", 8080, "HTTP port to listen on")
+	nodeStartCmd.Flags().Int("grpc-port", 0, "Also serve the gRPC API on this port (0 disables it)")
+	nodeStartCmd.Flags().String("listen", "", "Address to listen on, e.g. 127.0.0.1:8080 (overrides --port)")
+	nodeStartCmd.Flags().String("tls-cert", "", "PEM certificate file; serve HTTPS (requires --tls-key)")
+	nodeStartCmd.Flags().String("tls-key", "", "PEM private key file for --tls-cert")
+	nodeStartCmd.Flags().Bool("tls-self-signed", false, "Serve HTTPS with a generated self-signed certificate (local testing only)")
+	nodeStartCmd.Flags().String("university", "", "Name of the university this node signs for; must match its address in authorized_signers.json when listed there")
+	nodeStartCmd.Flags().String("data-dir", defaultDataDir, "Directory holding the per-signer chain databases")
+	nodeStartCmd.Flags().String("poa", poaOff, "Proof-of-authority registry check at startup: off, warn (warn when authorized_signers.json is missing or empty) or enforce (refuse to start)")
+	nodeStartCmd.Flags().Bool("read-only", false, "Serve read endpoints only; disable /add-block and other writes")
+	nodeStartCmd.Flags().Int("block-cache-size", 0, "Recently used blocks kept in memory (0 uses the default, negative disables the cache)")
+	nodeStartCmd.Flags().Duration("flush-interval", 0, "Write certificates queued with POST /pending into a block this often, e.g. 1m (0 flushes only on POST /flush)")
+	nodeStartCmd.Flags().StringSlice("peer", nil, "Base URL of another node to health-check, e.g. http://10.0.0.2:8080 (repeatable)")
+	nodeStartCmd.Flags().Duration("peer-check-interval", 30*time.Second, "How often to poll each --peer's /health (0 disables polling)")
+	nodeStartCmd.Flags().String("follow", "", "Run as a read replica of the leader node at this base URL, pulling its new blocks; implies --read-only")
+	nodeStartCmd.Flags().Duration("follow-interval", server.DefaultFollowInterval, "How often a --follow replica pulls new blocks from its leader")
+	nodeStartCmd.Flags().Bool("sort-certificates", false, "Order each new block's certificates by hash so identical batches get identical Merkle roots")
+	nodeStartCmd.Flags().Duration("min-block-interval", 0, "Reject new blocks created less than this long after the previous one, e.g. 30s (0 disables)")
+	nodeStartCmd.Flags().Duration("block-cache-ttl", 0, "Drop cached blocks unused for this long, e.g. 10m (0 keeps them until the cache is full)")
+	nodeStartCmd.Flags().Int64("max-request-body", server.DefaultMaxRequestBodyBytes, "Largest POST request body accepted, in bytes; larger requests get 413 (see --max-import-body for /import)")
+	nodeStartCmd.Flags().Int64("max-import-body", server.DefaultMaxImportBodyBytes, "Largest POST /import body accepted, in bytes")
 }
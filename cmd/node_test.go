@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+// testChainOptions keeps test chains in memory and silences Badger
+var testChainOptions = blockchain.BlockchainOptions{InMemory: true, Logger: blockchain.NopLogger()}
+
+// newTestChain creates a chain in a temp dir with the given number of blocks after genesis
+func newTestChain(t *testing.T, blocks int) (*blockchain.Blockchain, identity.Signer) {
+	t.Helper()
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	chain := blockchain.InitBlockchain("", signer, testChainOptions)
+	t.Cleanup(func() { chain.Close() })
+
+	for i := 0; i < blocks; i++ {
+		if _, err := chain.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+	return chain, signer
+}
+
+// newTestDB creates a closed chain database in a temp dir and returns its path
+func newTestDB(t *testing.T, blocks int) string {
+	t.Helper()
+	dbPath := t.TempDir()
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	chain := blockchain.InitBlockchain(dbPath, signer, blockchain.DefaultBlockchainOptions())
+	defer chain.Close()
+
+	for i := 0; i < blocks; i++ {
+		if _, err := chain.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+	return dbPath
+}
+
+var blockLine = regexp.MustCompile(`Block \d+: Height=(\d+),`)
+
+func listedHeights(output string) []string {
+	var heights []string
+	for _, m := range blockLine.FindAllStringSubmatch(output, -1) {
+		heights = append(heights, m[1])
+	}
+	return heights
+}
+
+func TestInteractiveListLimitAndReverse(t *testing.T) {
+	chain, signer := newTestChain(t, 4)
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"list", []string{"4", "3", "2", "1", "0"}},
+		{"list 2", []string{"4", "3"}},
+		{"list 3 --reverse", []string{"0", "1", "2"}},
+		{"list --reverse 20", []string{"0", "1", "2", "3", "4"}},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		runInteractive(strings.NewReader(tt.input+"\nexit\n"), &out, chain, signer, nil)
+
+		got := listedHeights(out.String())
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%q: expected heights %v, got %v", tt.input, tt.want, got)
+		}
+	}
+}
+
+func TestInteractiveListRejectsInvalidLimit(t *testing.T) {
+	chain, signer := newTestChain(t, 1)
+
+	for _, input := range []string{"list 0", "list -3", "list abc", "list 2 3"} {
+		var out bytes.Buffer
+		runInteractive(strings.NewReader(input+"\nexit\n"), &out, chain, signer, nil)
+
+		if !strings.Contains(out.String(), "Usage: list") {
+			t.Errorf("%q: expected usage message, got %q", input, out.String())
+		}
+		if len(listedHeights(out.String())) != 0 {
+			t.Errorf("%q: expected no blocks listed", input)
+		}
+	}
+}
+
+func TestInteractiveAddRejectsSeparatorInCertificateID(t *testing.T) {
+	chain, signer := newTestChain(t, 0)
+
+	// An empty entry from a doubled comma is rejected by the CLI split path
+	var out bytes.Buffer
+	runInteractive(strings.NewReader("add CERT-001,,CERT-002\nexit\n"), &out, chain, signer, nil)
+	if !strings.Contains(out.String(), "empty certificate ID") {
+		t.Fatalf("expected empty ID error, got %q", out.String())
+	}
+
+	// An ID with an embedded comma passed directly is rejected and named
+	out.Reset()
+	addBlock(&out, chain, signer, nil, []string{"CERT,003"})
+	if !strings.Contains(out.String(), `"CERT,003"`) {
+		t.Fatalf("expected error naming CERT,003, got %q", out.String())
+	}
+
+	if tip, _ := chain.Tip(); tip.Height != 0 {
+		t.Fatalf("rejected block was added at height %d", tip.Height)
+	}
+}
+
+func TestInteractiveAddAppliesCertificateIDPolicy(t *testing.T) {
+	chain, signer := newTestChain(t, 0)
+	policy, err := blockchain.NewCertificateIDPolicy(blockchain.DefaultCertificateIDMaxLength, blockchain.StrictCertificateIDCharset)
+	if err != nil {
+		t.Fatalf("NewCertificateIDPolicy: %v", err)
+	}
+
+	var out bytes.Buffer
+	runInteractive(strings.NewReader("add CERT-001,CERT_002\nexit\n"), &out, chain, signer, policy)
+	if !strings.Contains(out.String(), `"CERT_002"`) || !strings.Contains(out.String(), "not in the allowed set") {
+		t.Fatalf("expected an error naming CERT_002, got %q", out.String())
+	}
+	if tip, _ := chain.Tip(); tip.Height != 0 {
+		t.Fatalf("rejected block was added at height %d", tip.Height)
+	}
+
+	// The default policy still accepts IDs outside the strict charset
+	config, err := parseNodeStartConfig(t)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if err := config.Server.CertificateIDPolicy.Validate("CERT_002.v2"); err != nil {
+		t.Fatalf("expected the default policy to accept CERT_002.v2: %v", err)
+	}
+
+	config, err = parseNodeStartConfig(t, "--cert-id-max-length", "32", "--cert-id-charset", "A-Z0-9_")
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if got := config.Server.CertificateIDPolicy; got == nil || got.MaxLength != 32 || got.Validate("CERT_002") != nil {
+		t.Fatalf("unexpected policy from flags: %+v", got)
+	}
+	if _, err := parseNodeStartConfig(t, "--cert-id-charset", "z-a"); err == nil {
+		t.Fatalf("expected an invalid --cert-id-charset to be rejected")
+	}
+}
+
+func TestParseCertificateIDsTrimsWhitespace(t *testing.T) {
+	ids, err := parseCertificateIDs("CERT-001, CERT-002")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if strings.Join(ids, "|") != "CERT-001|CERT-002" {
+		t.Fatalf("unexpected IDs %q", ids)
+	}
+}
+
+// parseNodeStartConfig parses args as node start flags and resolves its configuration
+func parseNodeStartConfig(t *testing.T, args ...string) (nodeStartConfig, error) {
+	t.Helper()
+	resetFlags(rootCmd)
+	if err := nodeStartCmd.ParseFlags(args); err != nil {
+		t.Fatalf("parse flags: %v", err)
+	}
+	return loadNodeStartConfig(nodeStartCmd)
+}
+
+func TestNodeStartConfigFromEnv(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv("VERITAS_PORT", "9191")
+	t.Setenv("VERITAS_UNIVERSITY", "uni-a")
+	t.Setenv("VERITAS_DATA_DIR", dataDir)
+	t.Setenv("VERITAS_VERBOSE", "true")
+
+	config, err := parseNodeStartConfig(t)
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if config.Server.Port != 9191 {
+		t.Errorf("expected port 9191 from VERITAS_PORT, got %d", config.Server.Port)
+	}
+	if config.Server.University != "uni-a" {
+		t.Errorf("expected university uni-a from VERITAS_UNIVERSITY, got %q", config.Server.University)
+	}
+	if config.DataDir != dataDir {
+		t.Errorf("expected data dir %s from VERITAS_DATA_DIR, got %s", dataDir, config.DataDir)
+	}
+	if !config.Verbose {
+		t.Error("expected verbose from VERITAS_VERBOSE")
+	}
+	if len(config.EnvOverrides) != 4 {
+		t.Errorf("expected 4 environment overrides, got %v", config.EnvOverrides)
+	}
+
+	// Flags given on the command line win over the environment
+	config, err = parseNodeStartConfig(t, "--port", "7070", "--university", "uni-b", "--data-dir", "flag-dir", "--verbose=false")
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if config.Server.Port != 7070 || config.Server.University != "uni-b" || config.DataDir != "flag-dir" || config.Verbose {
+		t.Errorf("expected flag values to take precedence, got %+v", config)
+	}
+	if len(config.EnvOverrides) != 0 {
+		t.Errorf("expected no environment overrides, got %v", config.EnvOverrides)
+	}
+}
+
+func TestNodeStartConfigRejectsInvalidEnv(t *testing.T) {
+	t.Setenv("VERITAS_PORT", "eighty")
+	if _, err := parseNodeStartConfig(t); err == nil || !strings.Contains(err.Error(), "VERITAS_PORT") {
+		t.Fatalf("expected an invalid VERITAS_PORT error, got %v", err)
+	}
+
+	t.Setenv("VERITAS_PORT", "")
+	t.Setenv("VERITAS_VERBOSE", "loud")
+	if _, err := parseNodeStartConfig(t); err == nil || !strings.Contains(err.Error(), "VERITAS_VERBOSE") {
+		t.Fatalf("expected an invalid VERITAS_VERBOSE error, got %v", err)
+	}
+}
+
+func TestCheckUniversity(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	other := identity.NewIdentitySigner(identity.MakeIdentity())
+	signers := identity.AuthorizedSigners{
+		"uni-a": string(signer.Address()),
+		"uni-b": string(other.Address()),
+	}
+
+	if err := checkUniversity(signers, "uni-a", signer); err != nil {
+		t.Errorf("expected the signer's own university to be accepted: %v", err)
+	}
+	if err := checkUniversity(signers, "uni-unlisted", signer); err != nil {
+		t.Errorf("expected an unlisted university to be accepted: %v", err)
+	}
+	if err := checkUniversity(signers, "uni-b", signer); err == nil {
+		t.Error("expected another university's name to be rejected")
+	}
+}
+
+func TestCheckSignerRegistryEmptyRegistry(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "authorized_signers.json")
+	if err := os.WriteFile(empty, []byte("{}"), 0o644); err != nil {
+		t.Fatalf("write registry: %v", err)
+	}
+	t.Setenv("VERITAS_AUTHORIZED_SIGNERS", empty)
+
+	config, err := parseNodeStartConfig(t, "--poa", "enforce")
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	var out bytes.Buffer
+	if err := checkSignerRegistry(&out, config.Server.Signers, config.PoA); err == nil || !strings.Contains(err.Error(), "refusing to start") {
+		t.Fatalf("expected enforce to refuse an empty registry, got %v", err)
+	}
+
+	config, err = parseNodeStartConfig(t, "--poa", "warn")
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	if err := checkSignerRegistry(&out, config.Server.Signers, config.PoA); err != nil {
+		t.Fatalf("expected warn to start: %v", err)
+	}
+	if !strings.Contains(out.String(), "WARNING") {
+		t.Fatalf("expected a warning, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := checkSignerRegistry(&out, nil, poaOff); err != nil || out.Len() != 0 {
+		t.Fatalf("expected off to skip the check, got %v and %q", err, out.String())
+	}
+
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	signers := identity.AuthorizedSigners{"uni-a": string(signer.Address())}
+	if err := checkSignerRegistry(&out, signers, poaEnforce); err != nil {
+		t.Fatalf("expected a populated registry to pass: %v", err)
+	}
+
+	if _, err := parseNodeStartConfig(t, "--poa", "strict"); err == nil || !strings.Contains(err.Error(), "--poa") {
+		t.Fatalf("expected an invalid --poa error, got %v", err)
+	}
+}
+
+func TestLoadAuthorizedSignersReportsFetchErrors(t *testing.T) {
+	registry := httptest.NewServer(http.NotFoundHandler())
+	defer registry.Close()
+	t.Setenv(envAuthorizedSigners, registry.URL)
+
+	signers, err := loadAuthorizedSigners()
+	if err == nil || !strings.Contains(err.Error(), registry.URL) {
+		t.Fatalf("expected an error naming %s, got %v", registry.URL, err)
+	}
+	if signers != nil {
+		t.Fatalf("expected no signers, got %v", signers)
+	}
+}
+
+func TestNodeStartFollowRequiresSigners(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv(envAuthorizedSigners, "")
+	if _, err := parseNodeStartConfig(t, "--follow", "http://leader:8080"); err == nil || !strings.Contains(err.Error(), "--follow") {
+		t.Fatalf("expected --follow without a signer registry to be refused, got %v", err)
+	}
+}
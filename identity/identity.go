@@ -8,6 +8,7 @@ import (
 	"crypto/sha256"
 	"log"
 
+	"github.com/mr-tron/base58"
 	"golang.org/x/crypto/ripemd160"
 )
 
@@ -22,7 +23,42 @@ type Identity struct {
 }
 
 func (w *Identity) Address() []byte {
-	pubHash := PublicKeyHash(w.PublicKey)
+	return addressFromPublicKeyBytes(w.PublicKey)
+}
+
+// AddressFromPublicKey derives the address belonging to an ECDSA public key
+func AddressFromPublicKey(pub ecdsa.PublicKey) []byte {
+	return addressFromPublicKeyBytes(marshalPublicKey(pub))
+}
+
+// LegacyAddressFromPublicKey derives an address from the unpadded X||Y
+// encoding used before coordinates were fixed-width. It differs from
+// AddressFromPublicKey only for keys with a coordinate that has a leading
+// zero byte, and exists so blocks signed by such keys before the change still
+// validate.
+func LegacyAddressFromPublicKey(pub ecdsa.PublicKey) []byte {
+	return addressFromPublicKeyBytes(append(pub.X.Bytes(), pub.Y.Bytes()...))
+}
+
+// marshalPublicKey encodes pub as X||Y, each coordinate padded to the curve's
+// byte size. big.Int.Bytes() strips leading zeros, which would make the split
+// between X and Y ambiguous. Addresses are derived from this encoding, and
+// every Identity is built through newIdentity so they all agree on it.
+func marshalPublicKey(pub ecdsa.PublicKey) []byte {
+	size := (pub.Curve.Params().BitSize + 7) / 8
+	buf := make([]byte, 2*size)
+	pub.X.FillBytes(buf[:size])
+	pub.Y.FillBytes(buf[size:])
+	return buf
+}
+
+// newIdentity wraps a private key, deriving the public key bytes from it
+func newIdentity(private ecdsa.PrivateKey) *Identity {
+	return &Identity{PrivateKey: private, PublicKey: marshalPublicKey(private.PublicKey)}
+}
+
+func addressFromPublicKeyBytes(publicKey []byte) []byte {
+	pubHash := PublicKeyHash(publicKey)
 
 	versionedHash := append([]byte{version}, pubHash...)
 	checksum := Checksum(versionedHash)
@@ -41,15 +77,12 @@ func NewKeyPair() (ecdsa.PrivateKey, []byte) {
 		log.Panic(err)
 	}
 
-	pub := append(private.PublicKey.X.Bytes(), private.PublicKey.Y.Bytes()...)
-	return *private, pub
+	return *private, marshalPublicKey(private.PublicKey)
 }
 
 func MakeIdentity() *Identity {
-	private, public := NewKeyPair()
-	identity := Identity{private, public}
-
-	return &identity
+	private, _ := NewKeyPair()
+	return newIdentity(private)
 }
 
 func PublicKeyHash(pubKey []byte) []byte {
@@ -73,8 +106,13 @@ func Checksum(payload []byte) []byte {
 	return secondHash[:checksumLength]
 }
 
+// ValidateAddress reports whether address is well-formed base58 with a valid checksum.
+// Malformed input returns false rather than panicking.
 func ValidateAddress(address string) bool {
-	pubKeyHash := Base58Decode(address)
+	pubKeyHash, err := base58.Decode(address)
+	if err != nil || len(pubKeyHash) <= 1+checksumLength {
+		return false
+	}
 	actualChecksum := pubKeyHash[len(pubKeyHash)-checksumLength:]
 	version := pubKeyHash[0]
 	pubKeyHash = pubKeyHash[1 : len(pubKeyHash)-checksumLength]
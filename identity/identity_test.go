@@ -0,0 +1,86 @@
+package identity
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// Private scalars whose public key has a coordinate with a leading zero byte,
+// so X.Bytes() or Y.Bytes() is shorter than 32 bytes
+const (
+	shortYKeyHex = "000000000000000000000000000000000000000000000000000000000000002b"
+	shortXKeyHex = "000000000000000000000000000000000000000000000000000000000000017b"
+)
+
+func TestAddressDerivationPathsAgree(t *testing.T) {
+	random := NewIdentitySigner(MakeIdentity()).PrivateKeyHex()
+	for name, hexD := range map[string]string{"short Y": shortYKeyHex, "short X": shortXKeyHex, "random": random} {
+		t.Run(name, func(t *testing.T) {
+			signer, err := NewP256SignerFromHexD(hexD)
+			if err != nil {
+				t.Fatalf("signer from hex: %v", err)
+			}
+			if signer.PrivateKeyHex() != hexD {
+				t.Fatalf("private key hex %s does not round trip %s", signer.PrivateKeyHex(), hexD)
+			}
+			want := signer.Address()
+
+			// keygen used to print D without padding
+			unpadded, err := NewP256SignerFromHexD(hex.EncodeToString(signer.identity.PrivateKey.D.Bytes()))
+			if err != nil {
+				t.Fatalf("signer from unpadded hex: %v", err)
+			}
+			serialized := signer.identity.ToSerializable().FromSerializable()
+
+			for path, got := range map[string][]byte{
+				"Identity.Address":               signer.identity.Address(),
+				"AddressFromPublicKey":           AddressFromPublicKey(signer.PublicKey()),
+				"unpadded NewP256SignerFromHexD": unpadded.Address(),
+				"IdentitySigner from serialized": NewIdentitySigner(serialized).Address(),
+			} {
+				if !bytes.Equal(got, want) {
+					t.Errorf("%s gives address %s, expected %s", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPublicKeyEncodingIsFixedWidth(t *testing.T) {
+	for name, hexD := range map[string]string{"short Y": shortYKeyHex, "short X": shortXKeyHex} {
+		signer, err := NewP256SignerFromHexD(hexD)
+		if err != nil {
+			t.Fatalf("%s: signer from hex: %v", name, err)
+		}
+		pub := signer.PublicKey()
+		if len(pub.X.Bytes()) == 32 && len(pub.Y.Bytes()) == 32 {
+			t.Fatalf("%s: test key has no short coordinate", name)
+		}
+
+		encoded := marshalPublicKey(pub)
+		if len(encoded) != 64 || len(signer.identity.PublicKey) != 64 {
+			t.Fatalf("%s: expected 64-byte encodings, got %d and %d", name, len(encoded), len(signer.identity.PublicKey))
+		}
+		if !bytes.Equal(encoded, EncodePublicKey(pub)) || !bytes.Equal(encoded, signer.identity.PublicKey) {
+			t.Fatalf("%s: public key encodings differ", name)
+		}
+		decoded, err := DecodePublicKey(encoded)
+		if err != nil {
+			t.Fatalf("%s: decode: %v", name, err)
+		}
+		if decoded.X.Cmp(pub.X) != 0 || decoded.Y.Cmp(pub.Y) != 0 {
+			t.Fatalf("%s: decoded key differs from the original", name)
+		}
+
+		if bytes.Equal(AddressFromPublicKey(pub), LegacyAddressFromPublicKey(pub)) {
+			t.Fatalf("%s: expected the fixed-width address to differ from the legacy one", name)
+		}
+	}
+
+	for i := 0; i < 500; i++ {
+		if id := MakeIdentity(); len(id.PublicKey) != 64 {
+			t.Fatalf("generated identity has a %d-byte public key", len(id.PublicKey))
+		}
+	}
+}
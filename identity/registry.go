@@ -3,13 +3,44 @@ package identity
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
 	"os"
+	"strings"
+	"sync"
+	"time"
 )
 
+// Remote registries are fetched with registryFetchTimeout and reused for registryCacheTTL
+const (
+	registryFetchTimeout = 10 * time.Second
+	registryCacheTTL     = 5 * time.Minute
+
+	// maxRegistrySize bounds the body read from a remote registry
+	maxRegistrySize = 1 << 20
+)
+
+var registryClient = &http.Client{Timeout: registryFetchTimeout}
+
+// registryCache holds remote registries by URL
+var registryCache = struct {
+	sync.Mutex
+	entries map[string]cachedRegistry
+}{entries: make(map[string]cachedRegistry)}
+
+type cachedRegistry struct {
+	signers   AuthorizedSigners
+	fetchedAt time.Time
+}
+
 // AuthorizedSigners represents a mapping of university/organization name to address.
 type AuthorizedSigners map[string]string
 
-// LoadAuthorizedSigners loads a JSON file mapping names to addresses.
+// LoadAuthorizedSigners loads a JSON file mapping names to addresses. path may
+// also be an http:// or https:// URL of a centrally maintained registry, see
+// fetchAuthorizedSigners.
 // Example file content:
 //
 //	{
@@ -17,6 +48,9 @@ type AuthorizedSigners map[string]string
 //	  "mit": "..."
 //	}
 func LoadAuthorizedSigners(path string) (AuthorizedSigners, error) {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return fetchAuthorizedSigners(path)
+	}
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -28,6 +62,63 @@ func LoadAuthorizedSigners(path string) (AuthorizedSigners, error) {
 	return m, nil
 }
 
+// fetchAuthorizedSigners downloads the registry at url, reusing a copy fetched
+// within registryCacheTTL. The cache is not locked during the download, so a
+// slow registry server does not hold up lookups of other URLs.
+func fetchAuthorizedSigners(url string) (AuthorizedSigners, error) {
+	registryCache.Lock()
+	cached, ok := registryCache.entries[url]
+	registryCache.Unlock()
+	if ok && time.Since(cached.fetchedAt) < registryCacheTTL {
+		return maps.Clone(cached.signers), nil
+	}
+
+	signers, err := downloadAuthorizedSigners(url)
+	if err != nil {
+		return nil, err
+	}
+	registryCache.Lock()
+	registryCache.entries[url] = cachedRegistry{signers: maps.Clone(signers), fetchedAt: time.Now()}
+	registryCache.Unlock()
+	return signers, nil
+}
+
+// downloadAuthorizedSigners fetches the registry at url. Unlike a local file,
+// the fetched registry must be a non-empty object mapping names to valid
+// addresses, so a misconfigured server cannot silently authorize no one or an
+// unusable address.
+func downloadAuthorizedSigners(url string) (AuthorizedSigners, error) {
+	resp, err := registryClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch authorized signers: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch authorized signers: %s returned %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRegistrySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch authorized signers: %v", err)
+	}
+
+	var signers AuthorizedSigners
+	if err := json.Unmarshal(data, &signers); err != nil {
+		return nil, fmt.Errorf("invalid authorized signers from %s: expected an object mapping names to addresses: %v", url, err)
+	}
+	if len(signers) == 0 {
+		return nil, fmt.Errorf("invalid authorized signers from %s: registry is empty", url)
+	}
+	for name, address := range signers {
+		if name == "" {
+			return nil, fmt.Errorf("invalid authorized signers from %s: empty name", url)
+		}
+		if !ValidateAddress(address) {
+			return nil, fmt.Errorf("invalid authorized signers from %s: invalid address %q for %q", url, address, name)
+		}
+	}
+	return signers, nil
+}
+
 // ResolveNameByAddress returns the first name whose address matches the provided address.
 func (a AuthorizedSigners) ResolveNameByAddress(address string) (string, error) {
 	for name, addr := range a {
@@ -37,3 +128,9 @@ func (a AuthorizedSigners) ResolveNameByAddress(address string) (string, error)
 	}
 	return "", errors.New("address not found in authorized signers")
 }
+
+// IsAuthorized reports whether address belongs to an authorized signer, and if so its name
+func (a AuthorizedSigners) IsAuthorized(address string) (string, bool) {
+	name, err := a.ResolveNameByAddress(address)
+	return name, err == nil
+}
@@ -0,0 +1,96 @@
+package identity
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIsAuthorized(t *testing.T) {
+	harvard := string(MakeIdentity().Address())
+	signers := AuthorizedSigners{"harvard": harvard}
+
+	if name, ok := signers.IsAuthorized(harvard); !ok || name != "harvard" {
+		t.Fatalf("expected harvard to be authorized, got %q %v", name, ok)
+	}
+	if name, ok := signers.IsAuthorized(string(MakeIdentity().Address())); ok || name != "" {
+		t.Fatalf("expected an unknown address to be unauthorized, got %q %v", name, ok)
+	}
+	if _, ok := AuthorizedSigners(nil).IsAuthorized(harvard); ok {
+		t.Fatal("expected an empty registry to authorize no one")
+	}
+}
+
+func TestLoadAuthorizedSignersFromURL(t *testing.T) {
+	harvard := string(MakeIdentity().Address())
+	var fetches atomic.Int32
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write([]byte(`{"harvard": "` + harvard + `"}`))
+	}))
+	defer registry.Close()
+
+	for i := 0; i < 2; i++ {
+		signers, err := LoadAuthorizedSigners(registry.URL + "/signers.json")
+		if err != nil {
+			t.Fatalf("LoadAuthorizedSigners: %v", err)
+		}
+		if name, ok := signers.IsAuthorized(harvard); !ok || name != "harvard" {
+			t.Fatalf("expected harvard to be authorized, got %q %v", name, ok)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("expected the registry to be fetched once and then cached, got %d fetches", n)
+	}
+}
+
+func TestLoadAuthorizedSignersRejectsMalformedRegistry(t *testing.T) {
+	for name, body := range map[string]string{
+		"not JSON":        `{"harvard": `,
+		"wrong shape":     `["harvard"]`,
+		"non-string":      `{"harvard": {"address": "x"}}`,
+		"empty":           `{}`,
+		"invalid address": `{"harvard": "not-an-address"}`,
+	} {
+		registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}))
+		if _, err := LoadAuthorizedSigners(registry.URL); err == nil {
+			t.Errorf("%s: expected an error for registry %s", name, body)
+		}
+		registry.Close()
+	}
+}
+
+func TestSlowRegistryDoesNotBlockOtherLookups(t *testing.T) {
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"harvard": "` + string(MakeIdentity().Address()) + `"}`))
+	}))
+	defer fast.Close()
+	arrived, release := make(chan struct{}), make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(arrived)
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	go LoadAuthorizedSigners(slow.URL)
+	<-arrived
+	done := make(chan error, 1)
+	go func() {
+		_, err := LoadAuthorizedSigners(fast.URL)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("LoadAuthorizedSigners: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("a slow registry blocked the lookup of another")
+	}
+}
@@ -0,0 +1,79 @@
+package identity
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// rotationDomainTag separates key rotation signatures from every other signature
+var rotationDomainTag = []byte("veritas-key-rotation-v1")
+
+// KeyRotation is a statement, signed by a university's old key, that the
+// university now signs with the key behind NewAddress. Addresses are in their
+// Base58 form and public keys in EncodePublicKey form.
+type KeyRotation struct {
+	OldAddress   string `json:"old_address"`
+	NewAddress   string `json:"new_address"`
+	OldPublicKey []byte `json:"old_public_key"`
+	NewPublicKey []byte `json:"new_public_key"`
+	Timestamp    int64  `json:"timestamp"`
+	Signature    []byte `json:"signature"`
+}
+
+// NewKeyRotation returns a rotation from oldSigner's key to newSigner's, signed by oldSigner
+func NewKeyRotation(oldSigner, newSigner Signer, timestamp int64) (*KeyRotation, error) {
+	if bytes.Equal(oldSigner.Address(), newSigner.Address()) {
+		return nil, fmt.Errorf("old and new keys are the same")
+	}
+	rotation := &KeyRotation{
+		OldAddress:   string(oldSigner.Address()),
+		NewAddress:   string(newSigner.Address()),
+		OldPublicKey: EncodePublicKey(oldSigner.PublicKey()),
+		NewPublicKey: EncodePublicKey(newSigner.PublicKey()),
+		Timestamp:    timestamp,
+	}
+	sig, err := oldSigner.Sign(rotation.CalculateHashForSigning())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign rotation: %v", err)
+	}
+	rotation.Signature = sig
+	return rotation, nil
+}
+
+// CalculateHashForSigning returns the digest the old key signs
+func (r *KeyRotation) CalculateHashForSigning() []byte {
+	data := bytes.Join([][]byte{
+		rotationDomainTag,
+		[]byte(r.OldAddress),
+		[]byte(r.NewAddress),
+		r.NewPublicKey,
+		binary.BigEndian.AppendUint64(nil, uint64(r.Timestamp)),
+	}, []byte{})
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
+
+// Verify checks that both public keys belong to their addresses and that the
+// old key signed the rotation
+func (r *KeyRotation) Verify() error {
+	oldKey, err := DecodePublicKey(r.OldPublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid old public key: %v", err)
+	}
+	if derived := string(AddressFromPublicKey(oldKey)); derived != r.OldAddress {
+		return fmt.Errorf("old address %s does not match the old public key (address %s)", r.OldAddress, derived)
+	}
+	newKey, err := DecodePublicKey(r.NewPublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid new public key: %v", err)
+	}
+	if derived := string(AddressFromPublicKey(newKey)); derived != r.NewAddress {
+		return fmt.Errorf("new address %s does not match the new public key (address %s)", r.NewAddress, derived)
+	}
+	if !VerifySignature(oldKey, r.CalculateHashForSigning(), r.Signature) {
+		return fmt.Errorf("invalid rotation signature for %s", r.OldAddress)
+	}
+	return nil
+}
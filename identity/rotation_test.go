@@ -0,0 +1,38 @@
+package identity
+
+import "testing"
+
+func TestKeyRotationVerifiesWithOldKey(t *testing.T) {
+	oldSigner := NewIdentitySigner(MakeIdentity())
+	newSigner := NewIdentitySigner(MakeIdentity())
+
+	rotation, err := NewKeyRotation(oldSigner, newSigner, 1700000000)
+	if err != nil {
+		t.Fatalf("new rotation: %v", err)
+	}
+	if err := rotation.Verify(); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !VerifySignature(oldSigner.PublicKey(), rotation.CalculateHashForSigning(), rotation.Signature) {
+		t.Fatal("rotation is not signed by the old key")
+	}
+
+	redirected := *rotation
+	redirected.NewAddress = string(NewIdentitySigner(MakeIdentity()).Address())
+	if err := redirected.Verify(); err == nil {
+		t.Fatal("expected a changed new address to fail")
+	}
+
+	// A rotation signed by the new key instead of the old one is rejected
+	forged := *rotation
+	if forged.Signature, err = newSigner.Sign(rotation.CalculateHashForSigning()); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if err := forged.Verify(); err == nil {
+		t.Fatal("expected a rotation not signed by the old key to fail")
+	}
+
+	if _, err := NewKeyRotation(oldSigner, oldSigner, 1700000000); err == nil {
+		t.Fatal("expected rotating to the same key to fail")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"math/big"
 	"os"
 )
@@ -33,6 +35,11 @@ func (s *IdentitySigner) Address() []byte {
 	return s.identity.Address()
 }
 
+// Identity returns the identity the signer signs with, as stored in identities files
+func (s *IdentitySigner) Identity() *Identity {
+	return s.identity
+}
+
 // Sign returns a raw ECDSA signature as r||s bytes for the given message digest.
 func (s *IdentitySigner) Sign(message []byte) ([]byte, error) {
 	r, ecdsaS, err := ecdsa.Sign(rand.Reader, &s.identity.PrivateKey, message)
@@ -50,6 +57,9 @@ func (s *IdentitySigner) Sign(message []byte) ([]byte, error) {
 }
 
 // SplitSignatureRS splits a concatenated r||s signature back to big.Int components.
+// Malformed input yields meaningless components.
+//
+// Deprecated: use ParseSignatureRS, which rejects empty and odd-length signatures.
 func SplitSignatureRS(sig []byte) (r, s *big.Int) {
 	half := len(sig) / 2
 	r = new(big.Int).SetBytes(sig[:half])
@@ -57,6 +67,86 @@ func SplitSignatureRS(sig []byte) (r, s *big.Int) {
 	return r, s
 }
 
+// ParseSignatureRS splits a concatenated r||s signature into its components,
+// rejecting empty and odd-length input.
+func ParseSignatureRS(sig []byte) (r, s *big.Int, err error) {
+	if len(sig) == 0 {
+		return nil, nil, errors.New("invalid signature: empty")
+	}
+	if len(sig)%2 != 0 {
+		return nil, nil, fmt.Errorf("invalid signature: odd length %d", len(sig))
+	}
+	half := len(sig) / 2
+	r = new(big.Int).SetBytes(sig[:half])
+	s = new(big.Int).SetBytes(sig[half:])
+	return r, s, nil
+}
+
+// ParseCurveSignature splits an r||s signature made with a key on curve into
+// its components. Each component must be exactly the curve's byte size, as
+// written by IdentitySigner.Sign, so a signature sized for another curve is
+// rejected rather than split in the wrong place.
+func ParseCurveSignature(curve elliptic.Curve, sig []byte) (r, s *big.Int, err error) {
+	if curve == nil {
+		return nil, nil, errors.New("invalid signature: public key has no curve")
+	}
+	size := (curve.Params().BitSize + 7) / 8
+	if len(sig) != 2*size {
+		return nil, nil, fmt.Errorf("invalid signature: expected %d bytes for %s, got %d", 2*size, curve.Params().Name, len(sig))
+	}
+	r = new(big.Int).SetBytes(sig[:size])
+	s = new(big.Int).SetBytes(sig[size:])
+	return r, s, nil
+}
+
+// ParseLegacySignature splits a P-256 signature written before components were
+// padded, when r and s were concatenated with their leading zero bytes
+// dropped. Only signatures of even length below 64 bytes are legacy; each half
+// is at most 32 bytes and is read as if left-padded with zeros.
+func ParseLegacySignature(sig []byte) (r, s *big.Int, err error) {
+	const size = 32
+	if len(sig) >= 2*size {
+		return nil, nil, fmt.Errorf("invalid legacy signature: %d bytes is not shorter than %d", len(sig), 2*size)
+	}
+	return ParseSignatureRS(sig)
+}
+
+// VerifySignature verifies an r||s signature over digest with the given
+// public key, on whichever curve the key uses. Signatures are parsed with
+// ParseCurveSignature; for P-256 keys, which predate padded signatures, short
+// legacy signatures are also accepted through ParseLegacySignature.
+func VerifySignature(publicKey ecdsa.PublicKey, digest, signature []byte) bool {
+	r, s, err := ParseCurveSignature(publicKey.Curve, signature)
+	if err != nil && publicKey.Curve == elliptic.P256() {
+		r, s, err = ParseLegacySignature(signature)
+	}
+	if err != nil {
+		return false
+	}
+	return ecdsa.Verify(&publicKey, digest, r, s)
+}
+
+// EncodePublicKey encodes a public key as X||Y, each padded to the curve's byte size
+func EncodePublicKey(pub ecdsa.PublicKey) []byte {
+	return marshalPublicKey(pub)
+}
+
+// DecodePublicKey parses a P-256 public key encoded by EncodePublicKey. It is
+// P-256 only: keys on other curves, such as P-384, are rejected.
+func DecodePublicKey(data []byte) (ecdsa.PublicKey, error) {
+	curve := elliptic.P256()
+	size := (curve.Params().BitSize + 7) / 8
+	if len(data) != 2*size {
+		return ecdsa.PublicKey{}, fmt.Errorf("invalid public key: expected %d bytes, got %d", 2*size, len(data))
+	}
+	x := new(big.Int).SetBytes(data[:size])
+	y := new(big.Int).SetBytes(data[size:])
+	if !curve.IsOnCurve(x, y) {
+		return ecdsa.PublicKey{}, fmt.Errorf("invalid public key: point is not on P-256")
+	}
+	return ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
 // NewP256SignerFromHexD constructs an IdentitySigner from a hex-encoded private scalar D (P-256).
 func NewP256SignerFromHexD(hexD string) (*IdentitySigner, error) {
 	bytesD, err := hex.DecodeString(hexD)
@@ -67,11 +157,21 @@ func NewP256SignerFromHexD(hexD string) (*IdentitySigner, error) {
 	priv := new(ecdsa.PrivateKey)
 	priv.PublicKey.Curve = curve
 	priv.D = new(big.Int).SetBytes(bytesD)
+	if priv.D.Sign() == 0 || priv.D.Cmp(curve.Params().N) >= 0 {
+		return nil, fmt.Errorf("invalid private key: D must be between 1 and the P-256 order")
+	}
+	// The public key is derived from D, never read from storage, so it always matches
 	priv.PublicKey.X, priv.PublicKey.Y = curve.ScalarBaseMult(priv.D.Bytes())
 
-	pubBytes := append(priv.PublicKey.X.Bytes(), priv.PublicKey.Y.Bytes()...)
-	id := &Identity{PrivateKey: *priv, PublicKey: pubBytes}
-	return &IdentitySigner{identity: id}, nil
+	return &IdentitySigner{identity: newIdentity(*priv)}, nil
+}
+
+// PrivateKeyHex returns the signer's private scalar D as hex, padded to the
+// curve's byte size, in the form NewP256SignerFromHexD accepts
+func (s *IdentitySigner) PrivateKeyHex() string {
+	key := s.identity.PrivateKey
+	size := (key.Curve.Params().BitSize + 7) / 8
+	return hex.EncodeToString(key.D.FillBytes(make([]byte, size)))
 }
 
 // LoadSignerFromEnv tries to load a signer from environment variables.
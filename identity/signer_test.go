@@ -3,7 +3,12 @@ package identity
 // Regression test for the ECDSA r||s width bug: big.Int.Bytes() drops leading
 // zeros, so ~1/128 of signatures used to come out 63 bytes and fail Verify.
 
-import "testing"
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"testing"
+)
 
 func TestSignFixedWidth(t *testing.T) {
 	signer := NewIdentitySigner(MakeIdentity())
@@ -21,3 +26,66 @@ func TestSignFixedWidth(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSignatureRS(t *testing.T) {
+	for name, sig := range map[string][]byte{
+		"empty":      {},
+		"nil":        nil,
+		"odd length": make([]byte, 63),
+	} {
+		if _, _, err := ParseSignatureRS(sig); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	signer := NewIdentitySigner(MakeIdentity())
+	digest := make([]byte, 32)
+	sig, err := signer.Sign(digest)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	r, s, err := ParseSignatureRS(sig)
+	if err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	pub := signer.PublicKey()
+	if !ecdsa.Verify(&pub, digest, r, s) {
+		t.Fatal("parsed components do not verify")
+	}
+	if !VerifySignature(pub, digest, sig) || VerifySignature(pub, digest, sig[:63]) {
+		t.Fatal("VerifySignature does not respect signature parsing")
+	}
+}
+
+func TestParseCurveSignatureWidths(t *testing.T) {
+	curve := elliptic.P256()
+	for name, sig := range map[string][]byte{
+		"empty":    {},
+		"short":    make([]byte, 62),
+		"too long": make([]byte, 66),
+	} {
+		if _, _, err := ParseCurveSignature(curve, sig); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// Signatures written before components were padded to 32 bytes are split
+	// at half, but only through the explicit legacy path
+	legacy := append(bytes.Repeat([]byte{0x11}, 31), bytes.Repeat([]byte{0x22}, 31)...)
+	r, s, err := ParseLegacySignature(legacy)
+	if err != nil {
+		t.Fatalf("legacy signature rejected: %v", err)
+	}
+	if !bytes.Equal(r.Bytes(), legacy[:31]) || !bytes.Equal(s.Bytes(), legacy[31:]) {
+		t.Fatalf("legacy signature split in the wrong place: r=%x s=%x", r, s)
+	}
+	for name, sig := range map[string][]byte{
+		"odd length": make([]byte, 63),
+		"full width": make([]byte, 64),
+		"empty":      {},
+	} {
+		if _, _, err := ParseLegacySignature(sig); err == nil {
+			t.Errorf("legacy %s: expected an error", name)
+		}
+	}
+}
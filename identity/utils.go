@@ -4,6 +4,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"encoding/json"
+	"fmt"
 	"log"
 	"math/big"
 	"os"
@@ -49,7 +50,7 @@ func (i *Identity) ToSerializable() *SerializableIdentity {
 func (si *SerializableIdentity) FromSerializable() *Identity {
 	curve := elliptic.P256()
 
-	privateKey := &ecdsa.PrivateKey{
+	privateKey := ecdsa.PrivateKey{
 		PublicKey: ecdsa.PublicKey{
 			Curve: curve,
 			X:     si.PublicKeyX,
@@ -58,12 +59,35 @@ func (si *SerializableIdentity) FromSerializable() *Identity {
 		D: si.PrivateKeyD,
 	}
 
-	publicKey := append(si.PublicKeyX.Bytes(), si.PublicKeyY.Bytes()...)
+	return newIdentity(privateKey)
+}
 
-	return &Identity{
-		PrivateKey: *privateKey,
-		PublicKey:  publicKey,
+// ToSigner returns a signer for the identity, failing when the private key is
+// zero or out of range or the public key is not D*G
+func (si *SerializableIdentity) ToSigner() (*IdentitySigner, error) {
+	if err := si.validate(); err != nil {
+		return nil, err
 	}
+	return NewIdentitySigner(si.FromSerializable()), nil
+}
+
+// validate checks that the identity holds a usable P-256 key pair whose public
+// key is derived from its private key
+func (si *SerializableIdentity) validate() error {
+	curve := elliptic.P256()
+	if si.PrivateKeyD == nil || si.PrivateKeyD.Sign() <= 0 || si.PrivateKeyD.Cmp(curve.Params().N) >= 0 {
+		return fmt.Errorf("invalid private key: D must be between 1 and the P-256 order")
+	}
+	if si.PublicKeyX == nil || si.PublicKeyY == nil || !curve.IsOnCurve(si.PublicKeyX, si.PublicKeyY) {
+		return fmt.Errorf("invalid public key: point is not on P-256")
+	}
+	// A stored public key that does not belong to D would sign blocks that
+	// fail verification against the recorded key
+	x, y := curve.ScalarBaseMult(si.PrivateKeyD.Bytes())
+	if x.Cmp(si.PublicKeyX) != 0 || y.Cmp(si.PublicKeyY) != 0 {
+		return fmt.Errorf("invalid identity: public key does not match the private key")
+	}
+	return nil
 }
 
 // SaveIdentitiesToFile saves identities to a JSON file
@@ -92,7 +116,8 @@ func SaveIdentitiesToFile(identities map[string]*Identity, filename string) erro
 	return nil
 }
 
-// LoadIdentitiesFromFile loads identities from a JSON file
+// LoadIdentitiesFromFile loads identities from a JSON file, failing on any
+// identity whose stored public key does not match its private key
 func LoadIdentitiesFromFile(filename string) (map[string]*Identity, error) {
 	// Check if file exists
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
@@ -114,6 +139,9 @@ func LoadIdentitiesFromFile(filename string) (map[string]*Identity, error) {
 	// Convert back to Identity format
 	identities := make(map[string]*Identity)
 	for address, serializableIdentity := range serializable.Identities {
+		if err := serializableIdentity.validate(); err != nil {
+			return nil, fmt.Errorf("identity %s: %v", address, err)
+		}
 		identities[address] = serializableIdentity.FromSerializable()
 	}
 
@@ -0,0 +1,81 @@
+package identity
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestToSigner(t *testing.T) {
+	id := MakeIdentity()
+	signer, err := id.ToSerializable().ToSigner()
+	if err != nil {
+		t.Fatalf("to signer: %v", err)
+	}
+	if !bytes.Equal(signer.Address(), id.Address()) {
+		t.Fatalf("signer address %s does not match identity address %s", signer.Address(), id.Address())
+	}
+	digest := make([]byte, 32)
+	sig, err := signer.Sign(digest)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if !VerifySignature(signer.PublicKey(), digest, sig) {
+		t.Fatal("signature from the converted signer does not verify")
+	}
+
+	zeroD := id.ToSerializable()
+	zeroD.PrivateKeyD = big.NewInt(0)
+	if _, err := zeroD.ToSigner(); err == nil {
+		t.Fatal("expected a zero private key to be rejected")
+	}
+
+	offCurve := id.ToSerializable()
+	offCurve.PublicKeyY = new(big.Int).Add(offCurve.PublicKeyY, big.NewInt(1))
+	if _, err := offCurve.ToSigner(); err == nil {
+		t.Fatal("expected a public key off the curve to be rejected")
+	}
+}
+
+func TestLoadIdentitiesChecksPublicKeyMatchesPrivateKey(t *testing.T) {
+	id := MakeIdentity()
+	path := filepath.Join(t.TempDir(), "identities.json")
+	if err := SaveIdentitiesToFile(map[string]*Identity{string(id.Address()): id}, path); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded, err := LoadIdentitiesFromFile(path)
+	if err != nil {
+		t.Fatalf("load consistent identity: %v", err)
+	}
+	if got := loaded[string(id.Address())]; got == nil || !bytes.Equal(got.Address(), id.Address()) {
+		t.Fatalf("loaded identity does not match the saved one")
+	}
+
+	other := MakeIdentity().PrivateKey.PublicKey
+	for name, tamper := range map[string]func(*ecdsa.PublicKey){
+		// A changed X leaves the point off the curve
+		"tampered X": func(pub *ecdsa.PublicKey) { pub.X = new(big.Int).Add(pub.X, big.NewInt(1)) },
+		// Another key's point is on the curve, so only the D*G check catches it
+		"other key": func(pub *ecdsa.PublicKey) { pub.X, pub.Y = other.X, other.Y },
+	} {
+		tampered := &Identity{PrivateKey: id.PrivateKey}
+		tamper(&tampered.PrivateKey.PublicKey)
+		if err := SaveIdentitiesToFile(map[string]*Identity{string(id.Address()): tampered}, path); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+		if _, err := LoadIdentitiesFromFile(path); err == nil {
+			t.Fatalf("%s: expected the identity to be rejected", name)
+		} else if name == "other key" && !strings.Contains(err.Error(), "does not match") {
+			t.Fatalf("%s: expected a mismatch error, got %v", name, err)
+		}
+	}
+}
+
+func TestNewP256SignerFromHexDRejectsZero(t *testing.T) {
+	if _, err := NewP256SignerFromHexD("00"); err == nil {
+		t.Fatal("expected a zero private key to be rejected")
+	}
+}
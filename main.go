@@ -77,9 +77,9 @@ func main() {
 	// // Merkle proof: present certificate
 	// fmt.Println("--- Merkle Proof Tests ---")
 	// presentID := "CERT-001"
-	// proof, ok := block2.GenerateCertificateProof(presentID)
-	// if !ok {
-	// 	fmt.Printf("Failed to generate Merkle proof for %s\n", presentID)
+	// proof, err := block2.GenerateCertificateProof(presentID)
+	// if err != nil {
+	// 	fmt.Printf("Failed to generate Merkle proof for %s: %v\n", presentID, err)
 	// } else {
 	// 	verified := block2.VerifyCertificateWithProof(presentID, proof)
 	// 	fmt.Printf("Proof verify for %s: %v\n", presentID, verified)
@@ -87,8 +87,8 @@ func main() {
 
 	// // Merkle proof: absent certificate
 	// absentID := "CERT-999"
-	// proof2, ok2 := block2.GenerateCertificateProof(absentID)
-	// if !ok2 {
+	// proof2, err := block2.GenerateCertificateProof(absentID)
+	// if err != nil {
 	// 	fmt.Printf("No proof for absent ID %s (as expected)\n", absentID)
 	// } else {
 	// 	verified2 := block2.VerifyCertificateWithProof(absentID, proof2)
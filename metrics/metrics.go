@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are latency bucket upper bounds in seconds
+var DefaultBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Default is the registry exposed by Handler
+var Default = NewRegistry()
+
+// Registry holds named metrics and renders them in the Prometheus text exposition format
+type Registry struct {
+	mu         sync.Mutex
+	histograms map[string]*Histogram
+}
+
+func NewRegistry() *Registry {
+	return &Registry{histograms: make(map[string]*Histogram)}
+}
+
+// NewHistogram creates a histogram and registers it with the registry.
+// Registering the same name twice panics, as it indicates a programming error.
+func (r *Registry) NewHistogram(name, help string, buckets []float64) *Histogram {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.histograms[name]; exists {
+		panic(fmt.Sprintf("metrics: histogram %q already registered", name))
+	}
+	bounds := append([]float64{}, buckets...)
+	sort.Float64s(bounds)
+	h := &Histogram{
+		name:    name,
+		help:    help,
+		buckets: bounds,
+		counts:  make([]uint64, len(bounds)),
+	}
+	r.histograms[name] = h
+	return h
+}
+
+// WriteText writes all registered metrics, sorted by name
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.histograms))
+	for name := range r.histograms {
+		names = append(names, name)
+	}
+	r.mu.Unlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		r.mu.Lock()
+		h := r.histograms[name]
+		r.mu.Unlock()
+		if err := h.writeText(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewHistogram creates a histogram registered with the Default registry
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	return Default.NewHistogram(name, help, buckets)
+}
+
+// Handler serves the Default registry for Prometheus scraping
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_ = Default.WriteText(w)
+	})
+}
+
+// Histogram counts observations into cumulative buckets
+type Histogram struct {
+	name    string
+	help    string
+	buckets []float64
+
+	mu     sync.Mutex
+	counts []uint64 // observations per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// Observe records a single value
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, bound := range h.buckets {
+		if v <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+// ObserveDuration records the seconds elapsed since start, for use as
+// `defer h.ObserveDuration(time.Now())`
+func (h *Histogram) ObserveDuration(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// Count returns the total number of observations
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+func (h *Histogram) writeText(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name); err != nil {
+		return err
+	}
+	var cumulative uint64
+	for i, bound := range h.buckets {
+		cumulative += h.counts[i]
+		le := strconv.FormatFloat(bound, 'g', -1, 64)
+		if _, err := fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", h.name, le, cumulative); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n%s_sum %s\n%s_count %d\n",
+		h.name, h.count,
+		h.name, strconv.FormatFloat(h.sum, 'g', -1, 64),
+		h.name, h.count)
+	return err
+}
@@ -0,0 +1,746 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: proto/blockchain.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Block mirrors blockchain.Block.
+type Block struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Timestamp          int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Hash               []byte                 `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	PrevHash           []byte                 `protobuf:"bytes,3,opt,name=prev_hash,json=prevHash,proto3" json:"prev_hash,omitempty"`
+	Height             int64                  `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	CertificateHashes  []string               `protobuf:"bytes,5,rep,name=certificate_hashes,json=certificateHashes,proto3" json:"certificate_hashes,omitempty"`
+	Signature          []byte                 `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"`
+	MerkleRoot         []byte                 `protobuf:"bytes,7,opt,name=merkle_root,json=merkleRoot,proto3" json:"merkle_root,omitempty"`
+	UniversityAddress  string                 `protobuf:"bytes,8,opt,name=university_address,json=universityAddress,proto3" json:"university_address,omitempty"`
+	IssuedAt           []int64                `protobuf:"varint,9,rep,packed,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	Version            int32                  `protobuf:"varint,10,opt,name=version,proto3" json:"version,omitempty"`
+	PublicKey          []byte                 `protobuf:"bytes,11,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	HashAlgorithm      string                 `protobuf:"bytes,12,opt,name=hash_algorithm,json=hashAlgorithm,proto3" json:"hash_algorithm,omitempty"`
+	SortedCertificates bool                   `protobuf:"varint,13,opt,name=sorted_certificates,json=sortedCertificates,proto3" json:"sorted_certificates,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Block) Reset() {
+	*x = Block{}
+	mi := &file_proto_blockchain_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Block) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Block) ProtoMessage() {}
+
+func (x *Block) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_blockchain_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Block.ProtoReflect.Descriptor instead.
+func (*Block) Descriptor() ([]byte, []int) {
+	return file_proto_blockchain_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Block) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Block) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *Block) GetPrevHash() []byte {
+	if x != nil {
+		return x.PrevHash
+	}
+	return nil
+}
+
+func (x *Block) GetHeight() int64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Block) GetCertificateHashes() []string {
+	if x != nil {
+		return x.CertificateHashes
+	}
+	return nil
+}
+
+func (x *Block) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *Block) GetMerkleRoot() []byte {
+	if x != nil {
+		return x.MerkleRoot
+	}
+	return nil
+}
+
+func (x *Block) GetUniversityAddress() string {
+	if x != nil {
+		return x.UniversityAddress
+	}
+	return ""
+}
+
+func (x *Block) GetIssuedAt() []int64 {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return nil
+}
+
+func (x *Block) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Block) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+func (x *Block) GetHashAlgorithm() string {
+	if x != nil {
+		return x.HashAlgorithm
+	}
+	return ""
+}
+
+func (x *Block) GetSortedCertificates() bool {
+	if x != nil {
+		return x.SortedCertificates
+	}
+	return false
+}
+
+type AddBlockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Certificates  []string               `protobuf:"bytes,1,rep,name=certificates,proto3" json:"certificates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddBlockRequest) Reset() {
+	*x = AddBlockRequest{}
+	mi := &file_proto_blockchain_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddBlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddBlockRequest) ProtoMessage() {}
+
+func (x *AddBlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_blockchain_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddBlockRequest.ProtoReflect.Descriptor instead.
+func (*AddBlockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_blockchain_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AddBlockRequest) GetCertificates() []string {
+	if x != nil {
+		return x.Certificates
+	}
+	return nil
+}
+
+type GetBlockRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Selector:
+	//
+	//	*GetBlockRequest_Hash
+	//	*GetBlockRequest_Height
+	Selector      isGetBlockRequest_Selector `protobuf_oneof:"selector"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlockRequest) Reset() {
+	*x = GetBlockRequest{}
+	mi := &file_proto_blockchain_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlockRequest) ProtoMessage() {}
+
+func (x *GetBlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_blockchain_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlockRequest.ProtoReflect.Descriptor instead.
+func (*GetBlockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_blockchain_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetBlockRequest) GetSelector() isGetBlockRequest_Selector {
+	if x != nil {
+		return x.Selector
+	}
+	return nil
+}
+
+func (x *GetBlockRequest) GetHash() []byte {
+	if x != nil {
+		if x, ok := x.Selector.(*GetBlockRequest_Hash); ok {
+			return x.Hash
+		}
+	}
+	return nil
+}
+
+func (x *GetBlockRequest) GetHeight() int64 {
+	if x != nil {
+		if x, ok := x.Selector.(*GetBlockRequest_Height); ok {
+			return x.Height
+		}
+	}
+	return 0
+}
+
+type isGetBlockRequest_Selector interface {
+	isGetBlockRequest_Selector()
+}
+
+type GetBlockRequest_Hash struct {
+	Hash []byte `protobuf:"bytes,1,opt,name=hash,proto3,oneof"`
+}
+
+type GetBlockRequest_Height struct {
+	Height int64 `protobuf:"varint,2,opt,name=height,proto3,oneof"`
+}
+
+func (*GetBlockRequest_Hash) isGetBlockRequest_Selector() {}
+
+func (*GetBlockRequest_Height) isGetBlockRequest_Selector() {}
+
+type ListBlocksRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only blocks timestamped at or after since (Unix seconds) are streamed.
+	Since         int64 `protobuf:"varint,1,opt,name=since,proto3" json:"since,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBlocksRequest) Reset() {
+	*x = ListBlocksRequest{}
+	mi := &file_proto_blockchain_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBlocksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBlocksRequest) ProtoMessage() {}
+
+func (x *ListBlocksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_blockchain_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBlocksRequest.ProtoReflect.Descriptor instead.
+func (*ListBlocksRequest) Descriptor() ([]byte, []int) {
+	return file_proto_blockchain_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListBlocksRequest) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+type VerifyCertificateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CertificateId string                 `protobuf:"bytes,1,opt,name=certificate_id,json=certificateId,proto3" json:"certificate_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyCertificateRequest) Reset() {
+	*x = VerifyCertificateRequest{}
+	mi := &file_proto_blockchain_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyCertificateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyCertificateRequest) ProtoMessage() {}
+
+func (x *VerifyCertificateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_blockchain_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyCertificateRequest.ProtoReflect.Descriptor instead.
+func (*VerifyCertificateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_blockchain_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *VerifyCertificateRequest) GetCertificateId() string {
+	if x != nil {
+		return x.CertificateId
+	}
+	return ""
+}
+
+type VerifyCertificateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Found         bool                   `protobuf:"varint,1,opt,name=found,proto3" json:"found,omitempty"`
+	BlockHeight   int64                  `protobuf:"varint,2,opt,name=block_height,json=blockHeight,proto3" json:"block_height,omitempty"`
+	BlockHash     []byte                 `protobuf:"bytes,3,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	IssuedAt      int64                  `protobuf:"varint,4,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyCertificateResponse) Reset() {
+	*x = VerifyCertificateResponse{}
+	mi := &file_proto_blockchain_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyCertificateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyCertificateResponse) ProtoMessage() {}
+
+func (x *VerifyCertificateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_blockchain_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyCertificateResponse.ProtoReflect.Descriptor instead.
+func (*VerifyCertificateResponse) Descriptor() ([]byte, []int) {
+	return file_proto_blockchain_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *VerifyCertificateResponse) GetFound() bool {
+	if x != nil {
+		return x.Found
+	}
+	return false
+}
+
+func (x *VerifyCertificateResponse) GetBlockHeight() int64 {
+	if x != nil {
+		return x.BlockHeight
+	}
+	return 0
+}
+
+func (x *VerifyCertificateResponse) GetBlockHash() []byte {
+	if x != nil {
+		return x.BlockHash
+	}
+	return nil
+}
+
+func (x *VerifyCertificateResponse) GetIssuedAt() int64 {
+	if x != nil {
+		return x.IssuedAt
+	}
+	return 0
+}
+
+type GetProofRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CertificateId string                 `protobuf:"bytes,1,opt,name=certificate_id,json=certificateId,proto3" json:"certificate_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProofRequest) Reset() {
+	*x = GetProofRequest{}
+	mi := &file_proto_blockchain_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProofRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProofRequest) ProtoMessage() {}
+
+func (x *GetProofRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_blockchain_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProofRequest.ProtoReflect.Descriptor instead.
+func (*GetProofRequest) Descriptor() ([]byte, []int) {
+	return file_proto_blockchain_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetProofRequest) GetCertificateId() string {
+	if x != nil {
+		return x.CertificateId
+	}
+	return ""
+}
+
+// MerkleProof mirrors blockchain.MerkleProof. directions[i] is true when
+// siblings[i] is the right-hand node.
+type MerkleProof struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Siblings      [][]byte               `protobuf:"bytes,1,rep,name=siblings,proto3" json:"siblings,omitempty"`
+	Directions    []bool                 `protobuf:"varint,2,rep,packed,name=directions,proto3" json:"directions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MerkleProof) Reset() {
+	*x = MerkleProof{}
+	mi := &file_proto_blockchain_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MerkleProof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MerkleProof) ProtoMessage() {}
+
+func (x *MerkleProof) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_blockchain_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MerkleProof.ProtoReflect.Descriptor instead.
+func (*MerkleProof) Descriptor() ([]byte, []int) {
+	return file_proto_blockchain_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *MerkleProof) GetSiblings() [][]byte {
+	if x != nil {
+		return x.Siblings
+	}
+	return nil
+}
+
+func (x *MerkleProof) GetDirections() []bool {
+	if x != nil {
+		return x.Directions
+	}
+	return nil
+}
+
+type GetProofResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BlockHeight   int64                  `protobuf:"varint,1,opt,name=block_height,json=blockHeight,proto3" json:"block_height,omitempty"`
+	BlockHash     []byte                 `protobuf:"bytes,2,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	MerkleRoot    []byte                 `protobuf:"bytes,3,opt,name=merkle_root,json=merkleRoot,proto3" json:"merkle_root,omitempty"`
+	Proof         *MerkleProof           `protobuf:"bytes,4,opt,name=proof,proto3" json:"proof,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProofResponse) Reset() {
+	*x = GetProofResponse{}
+	mi := &file_proto_blockchain_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProofResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProofResponse) ProtoMessage() {}
+
+func (x *GetProofResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_blockchain_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProofResponse.ProtoReflect.Descriptor instead.
+func (*GetProofResponse) Descriptor() ([]byte, []int) {
+	return file_proto_blockchain_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetProofResponse) GetBlockHeight() int64 {
+	if x != nil {
+		return x.BlockHeight
+	}
+	return 0
+}
+
+func (x *GetProofResponse) GetBlockHash() []byte {
+	if x != nil {
+		return x.BlockHash
+	}
+	return nil
+}
+
+func (x *GetProofResponse) GetMerkleRoot() []byte {
+	if x != nil {
+		return x.MerkleRoot
+	}
+	return nil
+}
+
+func (x *GetProofResponse) GetProof() *MerkleProof {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+var File_proto_blockchain_proto protoreflect.FileDescriptor
+
+const file_proto_blockchain_proto_rawDesc = "" +
+	"\n" +
+	"\x16proto/blockchain.proto\x12\n" +
+	"veritas.v1\"\xb9\x03\n" +
+	"\x05Block\x12\x1c\n" +
+	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x12\n" +
+	"\x04hash\x18\x02 \x01(\fR\x04hash\x12\x1b\n" +
+	"\tprev_hash\x18\x03 \x01(\fR\bprevHash\x12\x16\n" +
+	"\x06height\x18\x04 \x01(\x03R\x06height\x12-\n" +
+	"\x12certificate_hashes\x18\x05 \x03(\tR\x11certificateHashes\x12\x1c\n" +
+	"\tsignature\x18\x06 \x01(\fR\tsignature\x12\x1f\n" +
+	"\vmerkle_root\x18\a \x01(\fR\n" +
+	"merkleRoot\x12-\n" +
+	"\x12university_address\x18\b \x01(\tR\x11universityAddress\x12\x1b\n" +
+	"\tissued_at\x18\t \x03(\x03R\bissuedAt\x12\x18\n" +
+	"\aversion\x18\n" +
+	" \x01(\x05R\aversion\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\v \x01(\fR\tpublicKey\x12%\n" +
+	"\x0ehash_algorithm\x18\f \x01(\tR\rhashAlgorithm\x12/\n" +
+	"\x13sorted_certificates\x18\r \x01(\bR\x12sortedCertificates\"5\n" +
+	"\x0fAddBlockRequest\x12\"\n" +
+	"\fcertificates\x18\x01 \x03(\tR\fcertificates\"M\n" +
+	"\x0fGetBlockRequest\x12\x14\n" +
+	"\x04hash\x18\x01 \x01(\fH\x00R\x04hash\x12\x18\n" +
+	"\x06height\x18\x02 \x01(\x03H\x00R\x06heightB\n" +
+	"\n" +
+	"\bselector\")\n" +
+	"\x11ListBlocksRequest\x12\x14\n" +
+	"\x05since\x18\x01 \x01(\x03R\x05since\"A\n" +
+	"\x18VerifyCertificateRequest\x12%\n" +
+	"\x0ecertificate_id\x18\x01 \x01(\tR\rcertificateId\"\x90\x01\n" +
+	"\x19VerifyCertificateResponse\x12\x14\n" +
+	"\x05found\x18\x01 \x01(\bR\x05found\x12!\n" +
+	"\fblock_height\x18\x02 \x01(\x03R\vblockHeight\x12\x1d\n" +
+	"\n" +
+	"block_hash\x18\x03 \x01(\fR\tblockHash\x12\x1b\n" +
+	"\tissued_at\x18\x04 \x01(\x03R\bissuedAt\"8\n" +
+	"\x0fGetProofRequest\x12%\n" +
+	"\x0ecertificate_id\x18\x01 \x01(\tR\rcertificateId\"I\n" +
+	"\vMerkleProof\x12\x1a\n" +
+	"\bsiblings\x18\x01 \x03(\fR\bsiblings\x12\x1e\n" +
+	"\n" +
+	"directions\x18\x02 \x03(\bR\n" +
+	"directions\"\xa4\x01\n" +
+	"\x10GetProofResponse\x12!\n" +
+	"\fblock_height\x18\x01 \x01(\x03R\vblockHeight\x12\x1d\n" +
+	"\n" +
+	"block_hash\x18\x02 \x01(\fR\tblockHash\x12\x1f\n" +
+	"\vmerkle_root\x18\x03 \x01(\fR\n" +
+	"merkleRoot\x12-\n" +
+	"\x05proof\x18\x04 \x01(\v2\x17.veritas.v1.MerkleProofR\x05proof2\xef\x02\n" +
+	"\n" +
+	"Blockchain\x12:\n" +
+	"\bAddBlock\x12\x1b.veritas.v1.AddBlockRequest\x1a\x11.veritas.v1.Block\x12:\n" +
+	"\bGetBlock\x12\x1b.veritas.v1.GetBlockRequest\x1a\x11.veritas.v1.Block\x12@\n" +
+	"\n" +
+	"ListBlocks\x12\x1d.veritas.v1.ListBlocksRequest\x1a\x11.veritas.v1.Block0\x01\x12`\n" +
+	"\x11VerifyCertificate\x12$.veritas.v1.VerifyCertificateRequest\x1a%.veritas.v1.VerifyCertificateResponse\x12E\n" +
+	"\bGetProof\x12\x1b.veritas.v1.GetProofRequest\x1a\x1c.veritas.v1.GetProofResponseB-Z+github.com/amanechibana/veritas-chain/protob\x06proto3"
+
+var (
+	file_proto_blockchain_proto_rawDescOnce sync.Once
+	file_proto_blockchain_proto_rawDescData []byte
+)
+
+func file_proto_blockchain_proto_rawDescGZIP() []byte {
+	file_proto_blockchain_proto_rawDescOnce.Do(func() {
+		file_proto_blockchain_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_blockchain_proto_rawDesc), len(file_proto_blockchain_proto_rawDesc)))
+	})
+	return file_proto_blockchain_proto_rawDescData
+}
+
+var file_proto_blockchain_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_proto_blockchain_proto_goTypes = []any{
+	(*Block)(nil),                     // 0: veritas.v1.Block
+	(*AddBlockRequest)(nil),           // 1: veritas.v1.AddBlockRequest
+	(*GetBlockRequest)(nil),           // 2: veritas.v1.GetBlockRequest
+	(*ListBlocksRequest)(nil),         // 3: veritas.v1.ListBlocksRequest
+	(*VerifyCertificateRequest)(nil),  // 4: veritas.v1.VerifyCertificateRequest
+	(*VerifyCertificateResponse)(nil), // 5: veritas.v1.VerifyCertificateResponse
+	(*GetProofRequest)(nil),           // 6: veritas.v1.GetProofRequest
+	(*MerkleProof)(nil),               // 7: veritas.v1.MerkleProof
+	(*GetProofResponse)(nil),          // 8: veritas.v1.GetProofResponse
+}
+var file_proto_blockchain_proto_depIdxs = []int32{
+	7, // 0: veritas.v1.GetProofResponse.proof:type_name -> veritas.v1.MerkleProof
+	1, // 1: veritas.v1.Blockchain.AddBlock:input_type -> veritas.v1.AddBlockRequest
+	2, // 2: veritas.v1.Blockchain.GetBlock:input_type -> veritas.v1.GetBlockRequest
+	3, // 3: veritas.v1.Blockchain.ListBlocks:input_type -> veritas.v1.ListBlocksRequest
+	4, // 4: veritas.v1.Blockchain.VerifyCertificate:input_type -> veritas.v1.VerifyCertificateRequest
+	6, // 5: veritas.v1.Blockchain.GetProof:input_type -> veritas.v1.GetProofRequest
+	0, // 6: veritas.v1.Blockchain.AddBlock:output_type -> veritas.v1.Block
+	0, // 7: veritas.v1.Blockchain.GetBlock:output_type -> veritas.v1.Block
+	0, // 8: veritas.v1.Blockchain.ListBlocks:output_type -> veritas.v1.Block
+	5, // 9: veritas.v1.Blockchain.VerifyCertificate:output_type -> veritas.v1.VerifyCertificateResponse
+	8, // 10: veritas.v1.Blockchain.GetProof:output_type -> veritas.v1.GetProofResponse
+	6, // [6:11] is the sub-list for method output_type
+	1, // [1:6] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_proto_blockchain_proto_init() }
+func file_proto_blockchain_proto_init() {
+	if File_proto_blockchain_proto != nil {
+		return
+	}
+	file_proto_blockchain_proto_msgTypes[2].OneofWrappers = []any{
+		(*GetBlockRequest_Hash)(nil),
+		(*GetBlockRequest_Height)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_blockchain_proto_rawDesc), len(file_proto_blockchain_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_blockchain_proto_goTypes,
+		DependencyIndexes: file_proto_blockchain_proto_depIdxs,
+		MessageInfos:      file_proto_blockchain_proto_msgTypes,
+	}.Build()
+	File_proto_blockchain_proto = out.File
+	file_proto_blockchain_proto_goTypes = nil
+	file_proto_blockchain_proto_depIdxs = nil
+}
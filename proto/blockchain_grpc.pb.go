@@ -0,0 +1,291 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: proto/blockchain.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Blockchain_AddBlock_FullMethodName          = "/veritas.v1.Blockchain/AddBlock"
+	Blockchain_GetBlock_FullMethodName          = "/veritas.v1.Blockchain/GetBlock"
+	Blockchain_ListBlocks_FullMethodName        = "/veritas.v1.Blockchain/ListBlocks"
+	Blockchain_VerifyCertificate_FullMethodName = "/veritas.v1.Blockchain/VerifyCertificate"
+	Blockchain_GetProof_FullMethodName          = "/veritas.v1.Blockchain/GetProof"
+)
+
+// BlockchainClient is the client API for Blockchain service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Blockchain exposes a node's chain over gRPC. It mirrors the HTTP API.
+type BlockchainClient interface {
+	// AddBlock records certificates in a new block signed by the node.
+	AddBlock(ctx context.Context, in *AddBlockRequest, opts ...grpc.CallOption) (*Block, error)
+	// GetBlock returns a block by hash or height.
+	GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*Block, error)
+	// ListBlocks streams blocks from newest to oldest.
+	ListBlocks(ctx context.Context, in *ListBlocksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Block], error)
+	// VerifyCertificate reports whether a certificate is on the chain.
+	VerifyCertificate(ctx context.Context, in *VerifyCertificateRequest, opts ...grpc.CallOption) (*VerifyCertificateResponse, error)
+	// GetProof returns a Merkle inclusion proof for a certificate.
+	GetProof(ctx context.Context, in *GetProofRequest, opts ...grpc.CallOption) (*GetProofResponse, error)
+}
+
+type blockchainClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBlockchainClient(cc grpc.ClientConnInterface) BlockchainClient {
+	return &blockchainClient{cc}
+}
+
+func (c *blockchainClient) AddBlock(ctx context.Context, in *AddBlockRequest, opts ...grpc.CallOption) (*Block, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Block)
+	err := c.cc.Invoke(ctx, Blockchain_AddBlock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockchainClient) GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*Block, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Block)
+	err := c.cc.Invoke(ctx, Blockchain_GetBlock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockchainClient) ListBlocks(ctx context.Context, in *ListBlocksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Block], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Blockchain_ServiceDesc.Streams[0], Blockchain_ListBlocks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListBlocksRequest, Block]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Blockchain_ListBlocksClient = grpc.ServerStreamingClient[Block]
+
+func (c *blockchainClient) VerifyCertificate(ctx context.Context, in *VerifyCertificateRequest, opts ...grpc.CallOption) (*VerifyCertificateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyCertificateResponse)
+	err := c.cc.Invoke(ctx, Blockchain_VerifyCertificate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blockchainClient) GetProof(ctx context.Context, in *GetProofRequest, opts ...grpc.CallOption) (*GetProofResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetProofResponse)
+	err := c.cc.Invoke(ctx, Blockchain_GetProof_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BlockchainServer is the server API for Blockchain service.
+// All implementations must embed UnimplementedBlockchainServer
+// for forward compatibility.
+//
+// Blockchain exposes a node's chain over gRPC. It mirrors the HTTP API.
+type BlockchainServer interface {
+	// AddBlock records certificates in a new block signed by the node.
+	AddBlock(context.Context, *AddBlockRequest) (*Block, error)
+	// GetBlock returns a block by hash or height.
+	GetBlock(context.Context, *GetBlockRequest) (*Block, error)
+	// ListBlocks streams blocks from newest to oldest.
+	ListBlocks(*ListBlocksRequest, grpc.ServerStreamingServer[Block]) error
+	// VerifyCertificate reports whether a certificate is on the chain.
+	VerifyCertificate(context.Context, *VerifyCertificateRequest) (*VerifyCertificateResponse, error)
+	// GetProof returns a Merkle inclusion proof for a certificate.
+	GetProof(context.Context, *GetProofRequest) (*GetProofResponse, error)
+	mustEmbedUnimplementedBlockchainServer()
+}
+
+// UnimplementedBlockchainServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBlockchainServer struct{}
+
+func (UnimplementedBlockchainServer) AddBlock(context.Context, *AddBlockRequest) (*Block, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddBlock not implemented")
+}
+func (UnimplementedBlockchainServer) GetBlock(context.Context, *GetBlockRequest) (*Block, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlock not implemented")
+}
+func (UnimplementedBlockchainServer) ListBlocks(*ListBlocksRequest, grpc.ServerStreamingServer[Block]) error {
+	return status.Errorf(codes.Unimplemented, "method ListBlocks not implemented")
+}
+func (UnimplementedBlockchainServer) VerifyCertificate(context.Context, *VerifyCertificateRequest) (*VerifyCertificateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyCertificate not implemented")
+}
+func (UnimplementedBlockchainServer) GetProof(context.Context, *GetProofRequest) (*GetProofResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProof not implemented")
+}
+func (UnimplementedBlockchainServer) mustEmbedUnimplementedBlockchainServer() {}
+func (UnimplementedBlockchainServer) testEmbeddedByValue()                    {}
+
+// UnsafeBlockchainServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BlockchainServer will
+// result in compilation errors.
+type UnsafeBlockchainServer interface {
+	mustEmbedUnimplementedBlockchainServer()
+}
+
+func RegisterBlockchainServer(s grpc.ServiceRegistrar, srv BlockchainServer) {
+	// If the following call pancis, it indicates UnimplementedBlockchainServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Blockchain_ServiceDesc, srv)
+}
+
+func _Blockchain_AddBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockchainServer).AddBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Blockchain_AddBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockchainServer).AddBlock(ctx, req.(*AddBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Blockchain_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockchainServer).GetBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Blockchain_GetBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockchainServer).GetBlock(ctx, req.(*GetBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Blockchain_ListBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListBlocksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BlockchainServer).ListBlocks(m, &grpc.GenericServerStream[ListBlocksRequest, Block]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Blockchain_ListBlocksServer = grpc.ServerStreamingServer[Block]
+
+func _Blockchain_VerifyCertificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyCertificateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockchainServer).VerifyCertificate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Blockchain_VerifyCertificate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockchainServer).VerifyCertificate(ctx, req.(*VerifyCertificateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Blockchain_GetProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProofRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlockchainServer).GetProof(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Blockchain_GetProof_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlockchainServer).GetProof(ctx, req.(*GetProofRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Blockchain_ServiceDesc is the grpc.ServiceDesc for Blockchain service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Blockchain_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "veritas.v1.Blockchain",
+	HandlerType: (*BlockchainServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddBlock",
+			Handler:    _Blockchain_AddBlock_Handler,
+		},
+		{
+			MethodName: "GetBlock",
+			Handler:    _Blockchain_GetBlock_Handler,
+		},
+		{
+			MethodName: "VerifyCertificate",
+			Handler:    _Blockchain_VerifyCertificate_Handler,
+		},
+		{
+			MethodName: "GetProof",
+			Handler:    _Blockchain_GetProof_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListBlocks",
+			Handler:       _Blockchain_ListBlocks_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/blockchain.proto",
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+// statusDomainTag separates status signatures from block and revocation signatures
+var statusDomainTag = []byte("veritas-status-v1")
+
+// StatusAttestation is the node's signature over its chain tip at a point in
+// time, letting a client confirm a /status response came from the node holding
+// the signing key and was not altered in transit. Binary fields are hex-encoded.
+type StatusAttestation struct {
+	TipHash   string `json:"tip_hash"`
+	Height    int    `json:"height"`
+	Timestamp int64  `json:"timestamp"`
+	Address   string `json:"address"`
+	PublicKey string `json:"public_key"`
+	Signature string `json:"signature"`
+}
+
+// attestStatus signs the node's current tip
+func (n *Node) attestStatus(tip *blockchain.Block) (*StatusAttestation, error) {
+	a := &StatusAttestation{
+		TipHash:   hex.EncodeToString(tip.Hash),
+		Height:    tip.Height,
+		Timestamp: time.Now().Unix(),
+		Address:   string(n.signer.Address()),
+		PublicKey: hex.EncodeToString(identity.EncodePublicKey(n.signer.PublicKey())),
+	}
+	digest, err := a.digest()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := n.signer.Sign(digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign status: %v", err)
+	}
+	a.Signature = hex.EncodeToString(sig)
+	return a, nil
+}
+
+// digest returns the hash signed by the node
+func (a *StatusAttestation) digest() ([]byte, error) {
+	tipHash, err := hex.DecodeString(a.TipHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tip hash: %v", err)
+	}
+	data := bytes.Join([][]byte{
+		statusDomainTag,
+		tipHash,
+		blockchain.ToHex(int64(a.Height)),
+		blockchain.ToHex(a.Timestamp),
+		[]byte(a.Address),
+	}, []byte{})
+	hash := sha256.Sum256(data)
+	return hash[:], nil
+}
+
+// Verify checks that the attestation was signed by trusted, the public key the
+// client expects the node to hold. The embedded PublicKey is informational and
+// is not trusted on its own.
+func (a *StatusAttestation) Verify(trusted ecdsa.PublicKey) error {
+	if address := string(identity.AddressFromPublicKey(trusted)); address != a.Address {
+		return fmt.Errorf("status attested by %s, expected %s", a.Address, address)
+	}
+	digest, err := a.digest()
+	if err != nil {
+		return err
+	}
+	sig, err := hex.DecodeString(a.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %v", err)
+	}
+	if !identity.VerifySignature(trusted, digest, sig) {
+		return fmt.Errorf("invalid status signature")
+	}
+	return nil
+}
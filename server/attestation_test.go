@@ -0,0 +1,62 @@
+package server
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+func TestSignedStatusVerifiesAgainstNodeKey(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	if _, err := chain.AddBlock([]string{"CERT-001"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+
+	if rec := doRequest(t, node, http.MethodGet, "/status", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	} else if resp := decodeStatus(t, rec.Body.Bytes()); resp.Attestation != nil {
+		t.Fatal("unsigned status carried an attestation")
+	}
+
+	rec := doRequest(t, node, http.MethodGet, "/status?signed=true", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	resp := decodeStatus(t, rec.Body.Bytes())
+	a := resp.Attestation
+	if a == nil {
+		t.Fatal("signed status has no attestation")
+	}
+	if a.TipHash != resp.LastHash || a.Height != 1 {
+		t.Fatalf("attestation covers tip %s at %d, expected %s at 1", a.TipHash, a.Height, resp.LastHash)
+	}
+	if err := a.Verify(signer.PublicKey()); err != nil {
+		t.Fatalf("attestation failed to verify: %v", err)
+	}
+	if a.PublicKey != hex.EncodeToString(identity.EncodePublicKey(signer.PublicKey())) {
+		t.Fatal("attestation carries the wrong public key")
+	}
+
+	// A proxy rewriting the tip, or a different key, fails verification
+	tampered := *a
+	tampered.Height = 7
+	if err := tampered.Verify(signer.PublicKey()); err == nil {
+		t.Fatal("tampered attestation verified")
+	}
+	other := identity.NewIdentitySigner(identity.MakeIdentity())
+	if err := a.Verify(other.PublicKey()); err == nil {
+		t.Fatal("attestation verified against the wrong key")
+	}
+}
+
+func decodeStatus(t *testing.T, body []byte) statusResponse {
+	t.Helper()
+	var resp statusResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	return resp
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+// follower keeps a read replica's chain in step with a leader node
+type follower struct {
+	chain  *blockchain.Blockchain
+	source *HTTPBlockSource
+
+	// signers are the authorized signers every synced block is checked against
+	signers identity.AuthorizedSigners
+
+	// adopted is set once the local chain is known to start with the leader's genesis block
+	adopted bool
+}
+
+// sync pulls every block the leader has beyond the local tip. SyncFrom works
+// in batches, so a leader far ahead is caught up with in one call.
+func (f *follower) sync(ctx context.Context) error {
+	if !f.adopted {
+		if err := f.adoptGenesis(ctx); err != nil {
+			return err
+		}
+		f.adopted = true
+	}
+	_, err := f.chain.SyncFrom(ctx, f.source, f.signers, blockchain.DefaultSyncBatchSize)
+	return err
+}
+
+// adoptGenesis replaces the genesis block a new node creates for itself with
+// the leader's chain. The leader's blocks are synced into a staging chain and
+// swapped in only once they all check out, so the node never serves an empty
+// chain in between. A chain holding anything beyond its own genesis block is
+// never replaced.
+func (f *follower) adoptGenesis(ctx context.Context) error {
+	genesis, err := f.source.BlocksInRange(ctx, 0, 0)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the leader's genesis block: %v", err)
+	}
+	if len(genesis) == 0 {
+		return fmt.Errorf("leader returned no genesis block")
+	}
+	if local, err := f.chain.GetBlockByHeight(0); err == nil && bytes.Equal(local.Hash, genesis[0].Hash) {
+		return nil
+	}
+	empty, err := f.chain.IsEmpty()
+	if err != nil {
+		return err
+	}
+	if !empty {
+		return fmt.Errorf("local chain does not start with the leader's genesis block; refusing to replace it")
+	}
+
+	staging, err := f.chain.NewStagingChain()
+	if err != nil {
+		return err
+	}
+	defer staging.Close()
+	if _, err := staging.SyncFrom(ctx, f.source, f.signers, blockchain.DefaultSyncBatchSize); err != nil {
+		return fmt.Errorf("failed to copy the leader's chain: %v", err)
+	}
+	return f.chain.ReplaceWith(staging)
+}
+
+// start syncs immediately and then every interval in the background, logging
+// failures. The returned function cancels an in-progress sync and waits for
+// it; it may be called more than once.
+func (f *follower) start(interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := f.sync(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("sync from leader %s failed: %v", f.source.BaseURL, err)
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(cancel)
+		<-exited
+	}
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+func TestFollowerTracksLeader(t *testing.T) {
+	leader, leaderChain, leaderSigner := newTestNode(t)
+	leaderServer := httptest.NewServer(leader.Handler())
+	defer leaderServer.Close()
+
+	// The leader starts well ahead of the follower's empty chain
+	for i := 0; i < 150; i++ {
+		if _, err := leaderChain.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, leaderSigner); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+
+	followerSigner := identity.NewIdentitySigner(identity.MakeIdentity())
+	followerChain := blockchain.InitBlockchain("", followerSigner, testChainOptions)
+	follower := NewNode(followerChain, followerSigner, Config{
+		ListenAddr:     "127.0.0.1:0",
+		Leader:         leaderServer.URL,
+		FollowInterval: 10 * time.Millisecond,
+		Signers:        identity.AuthorizedSigners{"uni": string(leaderSigner.Address())},
+	})
+	if err := follower.Listen(); err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go follower.Serve()
+	defer follower.Stop(context.Background())
+
+	waitForTip := func() {
+		t.Helper()
+		want, _ := leaderChain.Tip()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if got, err := followerChain.Tip(); err == nil && bytes.Equal(got.Hash, want.Hash) {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		got, _ := followerChain.Tip()
+		t.Fatalf("follower stuck at height %d, leader at %d", got.Height, want.Height)
+	}
+	waitForTip()
+
+	for i := 0; i < 3; i++ {
+		if _, err := leaderChain.AddBlock([]string{fmt.Sprintf("CERT-NEW-%d", i)}, leaderSigner); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+		waitForTip()
+	}
+
+	if err := followerChain.ValidateChain(); err != nil {
+		t.Fatalf("follower chain is invalid: %v", err)
+	}
+	rec := doRequest(t, follower, http.MethodGet, "/cert-status?id=CERT-NEW-2", "")
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(`"valid"`)) {
+		t.Fatalf("expected the follower to serve the new certificate as valid, got %d %s", rec.Code, rec.Body.String())
+	}
+	rec = doRequest(t, follower, http.MethodPost, "/add-block", `{"certificates":["CERT-LOCAL"]}`)
+	if rec.Code != http.StatusNotFound && rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected the follower to refuse writes, got %d", rec.Code)
+	}
+}
+
+func TestFollowerKeepsGenesisWhenLeaderIsUnauthorized(t *testing.T) {
+	leader, leaderChain, leaderSigner := newTestNode(t)
+	if _, err := leaderChain.AddBlock([]string{"CERT-LEADER"}, leaderSigner); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	leaderServer := httptest.NewServer(leader.Handler())
+	defer leaderServer.Close()
+
+	followerSigner := identity.NewIdentitySigner(identity.MakeIdentity())
+	followerChain := blockchain.InitBlockchain("", followerSigner, testChainOptions)
+	defer followerChain.Close()
+	ownGenesis, err := followerChain.Tip()
+	if err != nil {
+		t.Fatalf("tip: %v", err)
+	}
+
+	f := &follower{
+		chain:   followerChain,
+		source:  &HTTPBlockSource{BaseURL: leaderServer.URL, Client: leaderServer.Client()},
+		signers: identity.AuthorizedSigners{"follower": string(followerSigner.Address())},
+	}
+	if err := f.sync(context.Background()); err == nil || !strings.Contains(err.Error(), "not an authorized signer") {
+		t.Fatalf("expected the leader's chain to be refused, got %v", err)
+	}
+	tip, err := followerChain.Tip()
+	if err != nil || !bytes.Equal(tip.Hash, ownGenesis.Hash) {
+		t.Fatalf("expected the follower to keep its own genesis block, got %v %v", tip, err)
+	}
+}
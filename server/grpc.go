@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	pb "github.com/amanechibana/veritas-chain/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// grpcService implements the Blockchain gRPC service on top of a Node, sharing
+// its chain, signer, read-only setting and shutdown tracking with the HTTP API
+type grpcService struct {
+	pb.UnimplementedBlockchainServer
+	node *Node
+}
+
+// newGRPCServer creates a gRPC server exposing the node's chain, using TLS when
+// the node serves HTTPS
+func (n *Node) newGRPCServer() *grpc.Server {
+	var opts []grpc.ServerOption
+	if n.server.TLSConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(n.server.TLSConfig)))
+	}
+	s := grpc.NewServer(opts...)
+	pb.RegisterBlockchainServer(s, &grpcService{node: n})
+	return s
+}
+
+func (s *grpcService) AddBlock(ctx context.Context, req *pb.AddBlockRequest) (*pb.Block, error) {
+	if s.node.config.ReadOnly {
+		return nil, status.Error(codes.PermissionDenied, "node is read-only")
+	}
+	if len(req.Certificates) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no certificates provided")
+	}
+	if err := s.node.config.CertificateIDPolicy.ValidateAll(req.Certificates); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if !s.node.beginWrite() {
+		return nil, status.Error(codes.Unavailable, "node is shutting down")
+	}
+	defer s.node.writes.Done()
+
+	block, err := s.node.chain.AddBlock(req.Certificates, s.node.signer)
+	if errors.Is(err, blockchain.ErrBlockTooSoon) {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	} else if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to add block: %v", err)
+	}
+	return blockToProto(block), nil
+}
+
+func (s *grpcService) GetBlock(ctx context.Context, req *pb.GetBlockRequest) (*pb.Block, error) {
+	var (
+		block *blockchain.Block
+		err   error
+	)
+	switch selector := req.Selector.(type) {
+	case *pb.GetBlockRequest_Hash:
+		block, err = s.node.chain.GetBlockByHash(selector.Hash)
+	case *pb.GetBlockRequest_Height:
+		if selector.Height < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid height %d", selector.Height)
+		}
+		block, err = s.node.chain.GetBlockByHeight(int(selector.Height))
+	default:
+		return nil, status.Error(codes.InvalidArgument, "a block hash or height is required")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "block not found: %v", err)
+	}
+	return blockToProto(block), nil
+}
+
+// ListBlocks streams blocks newest first, stopping at the first block older than req.Since
+func (s *grpcService) ListBlocks(req *pb.ListBlocksRequest, stream grpc.ServerStreamingServer[pb.Block]) error {
+	block, err := s.node.chain.Tip()
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to load chain tip: %v", err)
+	}
+	for block.Timestamp >= req.Since {
+		if err := stream.Send(blockToProto(block)); err != nil {
+			return err
+		}
+		if len(block.PrevHash) == 0 {
+			break
+		}
+		if block, err = s.node.chain.GetBlockByHash(block.PrevHash); err != nil {
+			return status.Errorf(codes.Internal, "failed to load block: %v", err)
+		}
+	}
+	return nil
+}
+
+func (s *grpcService) VerifyCertificate(ctx context.Context, req *pb.VerifyCertificateRequest) (*pb.VerifyCertificateResponse, error) {
+	block, err := s.findCertificate(req.CertificateId)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return &pb.VerifyCertificateResponse{Found: false}, nil
+	}
+	issuedAt, _ := block.CertificateIssuedAt(req.CertificateId)
+	return &pb.VerifyCertificateResponse{
+		Found:       true,
+		BlockHeight: int64(block.Height),
+		BlockHash:   block.Hash,
+		IssuedAt:    issuedAt,
+	}, nil
+}
+
+func (s *grpcService) GetProof(ctx context.Context, req *pb.GetProofRequest) (*pb.GetProofResponse, error) {
+	block, err := s.findCertificate(req.CertificateId)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, status.Errorf(codes.NotFound, "certificate %q not found", req.CertificateId)
+	}
+	proof, err := block.GenerateCertificateProof(req.CertificateId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to build proof for certificate %q: %v", req.CertificateId, err)
+	}
+	return &pb.GetProofResponse{
+		BlockHeight: int64(block.Height),
+		BlockHash:   block.Hash,
+		MerkleRoot:  block.MerkleRoot,
+		Proof:       &pb.MerkleProof{Siblings: proof.Siblings, Directions: proof.Directions},
+	}, nil
+}
+
+// findCertificate looks up the block recording id, mapping failures to gRPC statuses
+func (s *grpcService) findCertificate(id string) (*blockchain.Block, error) {
+	if err := blockchain.ValidateCertificateID(id); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	block, err := s.node.chain.FindCertificate(id)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to search chain: %v", err)
+	}
+	return block, nil
+}
+
+// blockToProto converts a block to its protobuf representation
+func blockToProto(block *blockchain.Block) *pb.Block {
+	return &pb.Block{
+		Timestamp:          block.Timestamp,
+		Hash:               block.Hash,
+		PrevHash:           block.PrevHash,
+		Height:             int64(block.Height),
+		CertificateHashes:  block.CertificateHashes,
+		Signature:          block.Signature,
+		MerkleRoot:         block.MerkleRoot,
+		UniversityAddress:  string(block.UniversityAddress),
+		IssuedAt:           block.IssuedAt,
+		Version:            int32(block.Version),
+		PublicKey:          block.PublicKey,
+		HashAlgorithm:      block.HashAlgorithm,
+		SortedCertificates: block.SortedCertificates,
+	}
+}
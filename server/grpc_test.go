@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	pb "github.com/amanechibana/veritas-chain/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestGRPCClient serves the node's gRPC API in-process and returns a client for it
+func newTestGRPCClient(t *testing.T, node *Node) pb.BlockchainClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := node.newGRPCServer()
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewBlockchainClient(conn)
+}
+
+func TestGRPCAPI(t *testing.T) {
+	node, chain, _ := newTestNode(t)
+	client := newTestGRPCClient(t, node)
+	ctx := context.Background()
+
+	added, err := client.AddBlock(ctx, &pb.AddBlockRequest{Certificates: []string{"CERT-001", "CERT-002", "CERT-003"}})
+	if err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+	if added.Height != 1 || len(added.CertificateHashes) != 3 {
+		t.Fatalf("unexpected block: height %d with %d certificates", added.Height, len(added.CertificateHashes))
+	}
+	if _, err := client.AddBlock(ctx, &pb.AddBlockRequest{Certificates: []string{"CERT,BAD"}}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for a bad certificate ID, got %v", err)
+	}
+
+	byHeight, err := client.GetBlock(ctx, &pb.GetBlockRequest{Selector: &pb.GetBlockRequest_Height{Height: 1}})
+	if err != nil {
+		t.Fatalf("GetBlock by height: %v", err)
+	}
+	byHash, err := client.GetBlock(ctx, &pb.GetBlockRequest{Selector: &pb.GetBlockRequest_Hash{Hash: added.Hash}})
+	if err != nil {
+		t.Fatalf("GetBlock by hash: %v", err)
+	}
+	if string(byHeight.Hash) != string(added.Hash) || string(byHash.Hash) != string(added.Hash) {
+		t.Fatal("GetBlock returned a different block")
+	}
+	if _, err := client.GetBlock(ctx, &pb.GetBlockRequest{Selector: &pb.GetBlockRequest_Height{Height: 9}}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound for a missing height, got %v", err)
+	}
+
+	stream, err := client.ListBlocks(ctx, &pb.ListBlocksRequest{})
+	if err != nil {
+		t.Fatalf("ListBlocks: %v", err)
+	}
+	var heights []int64
+	for {
+		block, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ListBlocks recv: %v", err)
+		}
+		heights = append(heights, block.Height)
+	}
+	if len(heights) != 2 || heights[0] != 1 || heights[1] != 0 {
+		t.Fatalf("expected heights [1 0], got %v", heights)
+	}
+
+	verified, err := client.VerifyCertificate(ctx, &pb.VerifyCertificateRequest{CertificateId: "CERT-002"})
+	if err != nil {
+		t.Fatalf("VerifyCertificate: %v", err)
+	}
+	if !verified.Found || verified.BlockHeight != 1 {
+		t.Fatalf("expected CERT-002 at height 1, got %+v", verified)
+	}
+	missing, err := client.VerifyCertificate(ctx, &pb.VerifyCertificateRequest{CertificateId: "CERT-404"})
+	if err != nil || missing.Found {
+		t.Fatalf("expected CERT-404 not found, got %+v, %v", missing, err)
+	}
+
+	proofResp, err := client.GetProof(ctx, &pb.GetProofRequest{CertificateId: "CERT-003"})
+	if err != nil {
+		t.Fatalf("GetProof: %v", err)
+	}
+	proof := blockchain.MerkleProof{Siblings: proofResp.Proof.Siblings, Directions: proofResp.Proof.Directions}
+	if !blockchain.VerifyProof([]byte("CERT-003"), proof, proofResp.MerkleRoot) {
+		t.Fatal("returned proof does not verify")
+	}
+	if _, err := client.GetProof(ctx, &pb.GetProofRequest{CertificateId: "CERT-404"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound proof for a missing certificate, got %v", err)
+	}
+
+	if tip, _ := chain.Tip(); tip.Height != 1 {
+		t.Fatalf("expected tip at height 1, got %d", tip.Height)
+	}
+}
+
+func TestGRPCAddBlockRefusedWhenReadOnly(t *testing.T) {
+	_, chain, signer := newTestNode(t)
+	client := newTestGRPCClient(t, NewNode(chain, signer, Config{ReadOnly: true}))
+
+	_, err := client.AddBlock(context.Background(), &pb.AddBlockRequest{Certificates: []string{"CERT-001"}})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied, got %v", err)
+	}
+}
@@ -0,0 +1,1141 @@
+package server
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+type addBlockRequest struct {
+	Certificates []string `json:"certificates"`
+}
+
+// addBlockResponse is the block written by /add-block, with a signed receipt
+// per certificate when requested with ?receipt=true
+type addBlockResponse struct {
+	BlockSummaryDTO
+	Receipts     []IssuanceReceipt `json:"receipts,omitempty"`
+	ReceiptError string            `json:"receipt_error,omitempty"`
+}
+
+type pendingResponse struct {
+	Pending int `json:"pending"`
+}
+
+type statusResponse struct {
+	Valid            bool   `json:"valid"`
+	Error            string `json:"error,omitempty"`
+	BlockCount       int    `json:"block_count"`
+	CertificateCount int    `json:"certificate_count"`
+	LastHash         string `json:"last_hash"`
+	University       string `json:"university,omitempty"`
+
+	// Attestation is included with ?signed=true
+	Attestation *StatusAttestation `json:"attestation,omitempty"`
+}
+
+// publicKeyXY is a public key as hex-encoded coordinates, each padded to the curve's byte size
+type publicKeyXY struct {
+	X string `json:"x"`
+	Y string `json:"y"`
+}
+
+type nodeInfoResponse struct {
+	University string      `json:"university,omitempty"`
+	Address    string      `json:"address"`
+	PublicKey  publicKeyXY `json:"public_key"`
+}
+
+type universityStats struct {
+	Address          string `json:"address"`
+	Name             string `json:"name,omitempty"`
+	BlockCount       int    `json:"block_count"`
+	CertificateCount int    `json:"certificate_count"`
+}
+
+type revokeRequest struct {
+	CertificateID string `json:"certificate_id"`
+	Reason        string `json:"reason"`
+}
+
+type supersedeRequest struct {
+	CertificateID    string `json:"certificate_id"`
+	NewCertificateID string `json:"new_certificate_id"`
+}
+
+type importResponse struct {
+	Imported int      `json:"imported"`
+	Rejected int      `json:"rejected"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+type authorizedResponse struct {
+	Authorized bool   `json:"authorized"`
+	Name       string `json:"name,omitempty"`
+}
+
+type issuedResponse struct {
+	Address      string                         `json:"address"`
+	Certificates []blockchain.IssuedCertificate `json:"certificates"`
+}
+
+type certificatesResponse struct {
+	Certificates []string `json:"certificates"`
+
+	// Next is the after cursor for the following page, omitted on the last page
+	Next string `json:"next,omitempty"`
+}
+
+// blocksBySignerRequest carries a P-256 public key as hex-encoded affine coordinates
+type blocksBySignerRequest struct {
+	X string `json:"x"`
+	Y string `json:"y"`
+}
+
+type signedBlock struct {
+	Height int    `json:"height"`
+	Hash   string `json:"hash"`
+}
+
+type blocksBySignerResponse struct {
+	Address string        `json:"address"`
+	Blocks  []signedBlock `json:"blocks"`
+}
+
+// verifyCertResponse reports the newest block recording a certificate and,
+// with all=true, every block recording it
+type verifyCertResponse struct {
+	Found       bool                    `json:"found"`
+	BlockHeight int                     `json:"block_height,omitempty"`
+	BlockHash   string                  `json:"block_hash,omitempty"`
+	Occurrences []certificateOccurrence `json:"occurrences,omitempty"`
+}
+
+type certificateOccurrence struct {
+	BlockHeight int    `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+	IssuedAt    int64  `json:"issued_at,omitempty"`
+}
+
+// verifyProofRequest is a Merkle proof a client wants checked against the
+// block at BlockHeight, in the format returned by the getProof RPC
+type verifyProofRequest struct {
+	CertificateID string                 `json:"certificate_id"`
+	Proof         blockchain.MerkleProof `json:"proof"`
+	BlockHeight   int                    `json:"block_height"`
+}
+
+type verifyProofResponse struct {
+	Valid       bool   `json:"valid"`
+	BlockHeight int    `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+	MerkleRoot  string `json:"merkle_root"`
+}
+
+type countResponse struct {
+	BlockCount int `json:"block_count"`
+}
+
+type accumulatorResponse struct {
+	Root   string `json:"root"`
+	Height int    `json:"height"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (n *Node) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (n *Node) handleStatus(w http.ResponseWriter, r *http.Request) {
+	resp, tip, err := n.chainStatus()
+	if r.URL.Query().Get("signed") == "true" {
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, fmt.Sprintf("cannot attest status: %v", err))
+			return
+		}
+		if resp.Attestation, err = n.attestStatus(tip); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// chainStatus validates the chain and reports its size, returning the tip too;
+// the error is from loading the tip, which leaves LastHash empty
+func (n *Node) chainStatus() (statusResponse, *blockchain.Block, error) {
+	resp := statusResponse{Valid: true, University: n.config.University}
+	if err := n.chain.ValidateChain(); err != nil {
+		resp.Valid = false
+		resp.Error = err.Error()
+	}
+
+	stats := n.chain.GetStats()
+	resp.BlockCount = stats.BlockCount
+	resp.CertificateCount = stats.CertificateCount
+
+	tip, err := n.chain.Tip()
+	if err == nil {
+		resp.LastHash = hex.EncodeToString(tip.Hash)
+	}
+	return resp, tip, err
+}
+
+// handleStats serves the chain's height and the timestamps of its genesis
+// block and tip; unlike /status it neither validates nor walks the chain
+func (n *Node) handleStats(w http.ResponseWriter, r *http.Request) {
+	info, err := n.chain.Info()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, fmt.Sprintf("failed to read chain info: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// handleStatsByUniversity reports block and certificate counts per university,
+// sorted by address and labelled with names from the signer registry where known
+func (n *Node) handleStatsByUniversity(w http.ResponseWriter, r *http.Request) {
+	stats, err := n.chain.GetStatsByUniversity()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := make([]universityStats, 0, len(stats))
+	for address, s := range stats {
+		entry := universityStats{Address: address, BlockCount: s.BlockCount, CertificateCount: s.CertificateCount}
+		if name, err := n.config.Signers.ResolveNameByAddress(address); err == nil {
+			entry.Name = name
+		}
+		resp = append(resp, entry)
+	}
+	sort.Slice(resp, func(i, j int) bool { return resp[i].Address < resp[j].Address })
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (n *Node) handleChainID(w http.ResponseWriter, r *http.Request) {
+	chainID, err := n.chain.ChainID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to determine chain ID: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"chain_id": chainID})
+}
+
+// handleNodeInfo reports the identity the node signs blocks, receipts and
+// status attestations with, so clients can check those signatures
+func (n *Node) handleNodeInfo(w http.ResponseWriter, r *http.Request) {
+	encoded := identity.EncodePublicKey(n.signer.PublicKey())
+	half := len(encoded) / 2
+	writeJSON(w, http.StatusOK, nodeInfoResponse{
+		University: n.config.University,
+		Address:    string(n.signer.Address()),
+		PublicKey: publicKeyXY{
+			X: hex.EncodeToString(encoded[:half]),
+			Y: hex.EncodeToString(encoded[half:]),
+		},
+	})
+}
+
+// blocksFlushInterval is how many blocks /blocks writes between flushes
+const blocksFlushInterval = 100
+
+// handleBlocks streams the matching blocks as a JSON array, newest first, one
+// block at a time so memory stays flat however long the chain is. The status
+// is sent before the walk, so a failure or a cancelled request part way
+// through truncates the array.
+func (n *Node) handleBlocks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	matchesLabel := func(*blockchain.Block) bool { return true }
+	if query.Has("label") {
+		key, value, ok := strings.Cut(query.Get("label"), blockchain.LabelSeparator)
+		if !ok || key == "" {
+			writeError(w, http.StatusBadRequest, "label must be key:value")
+			return
+		}
+		matchesLabel = func(block *blockchain.Block) bool { return block.HasLabel(key, value) }
+	}
+
+	since, until := int64(0), int64(math.MaxInt64)
+	if query.Has("since") || query.Has("until") {
+		var err error
+		if since, err = parseTimestampParam(query.Get("since"), 0); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid since: %v", err))
+			return
+		}
+		if until, err = parseTimestampParam(query.Get("until"), math.MaxInt64); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid until: %v", err))
+			return
+		}
+		if since > until {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid range: until %d is before since %d", until, since))
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	aw := &jsonArrayWriter{w: w}
+	encoder := json.NewEncoder(aw)
+
+	written := 0
+	// Block timestamps never decrease along the chain, so the walk stops at the first block before since
+	for hash := n.chain.Iterator().CurrentHash; len(hash) > 0; {
+		if r.Context().Err() != nil {
+			return
+		}
+		block, err := n.chain.GetBlockByHash(hash)
+		if err != nil {
+			return
+		}
+		if block.Timestamp < since {
+			break
+		}
+		hash = block.PrevHash
+		if block.Timestamp > until || !matchesLabel(block) {
+			continue
+		}
+		if encoder.Encode(blockSummary(block)) != nil {
+			return
+		}
+		if written++; written%blocksFlushInterval == 0 && flusher != nil {
+			flusher.Flush()
+		}
+	}
+	aw.Close()
+}
+
+// blockLabelsRequest replaces the labels of the block with hex-encoded Hash
+type blockLabelsRequest struct {
+	Hash   string            `json:"hash"`
+	Labels map[string]string `json:"labels"`
+}
+
+// handleBlockLabels sets a block's labels. Labels are not hashed, so the
+// block's hash and signature are unchanged.
+func (n *Node) handleBlockLabels(w http.ResponseWriter, r *http.Request) {
+	var req blockLabelsRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	hash, err := hex.DecodeString(req.Hash)
+	if err != nil || len(hash) != sha256.Size {
+		writeError(w, http.StatusBadRequest, "hash must be a hex-encoded SHA-256 block hash")
+		return
+	}
+	if err := blockchain.ValidateLabels(req.Labels); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !n.beginWrite() {
+		writeError(w, http.StatusServiceUnavailable, "node is shutting down")
+		return
+	}
+	defer n.writes.Done()
+
+	block, err := n.chain.SetBlockLabels(hash, req.Labels)
+	if errors.Is(err, blockchain.ErrBlockNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, blockSummary(block))
+}
+
+// handleLatestBlock returns the header of the chain tip without validating the
+// chain, for clients polling for new blocks
+func (n *Node) handleLatestBlock(w http.ResponseWriter, r *http.Request) {
+	tip, err := n.chain.Tip()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, fmt.Sprintf("failed to load chain tip: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, tip.Header())
+}
+
+// handleRawBlock serves the serialized bytes of the block with the given hex
+// hash, as stored by the chain and read back by blockchain.Deserialize
+func (n *Node) handleRawBlock(w http.ResponseWriter, r *http.Request) {
+	hash, err := hex.DecodeString(r.URL.Query().Get("hash"))
+	if err != nil || len(hash) != sha256.Size {
+		writeError(w, http.StatusBadRequest, "hash must be a hex-encoded SHA-256 block hash")
+		return
+	}
+	block, err := n.chain.GetBlockByHash(hash)
+	// Other records share the keyspace; only a block stored under its own hash is served
+	if err != nil || !bytes.Equal(block.Hash, hash) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("block %x not found", hash))
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	w.Write(block.Serialize())
+}
+
+// handleCount serves the number of blocks, genesis included, from the tip's
+// height; unlike /status it neither validates nor walks the chain
+func (n *Node) handleCount(w http.ResponseWriter, r *http.Request) {
+	height, err := n.chain.Height()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, fmt.Sprintf("failed to load chain tip: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, countResponse{BlockCount: height + 1})
+}
+
+// handleAccumulatorRoot serves the root of the block-hash accumulator and the
+// tip height it covers, for light clients checking BlockInclusionProofs
+func (n *Node) handleAccumulatorRoot(w http.ResponseWriter, r *http.Request) {
+	accumulator, err := n.chain.AccumulatorRoot()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, accumulatorResponse{Root: hex.EncodeToString(accumulator.Root), Height: accumulator.Height})
+}
+
+// handleIsAuthorized reports whether an address is in the node's authorized signer registry
+func (n *Node) handleIsAuthorized(w http.ResponseWriter, r *http.Request) {
+	address := r.URL.Query().Get("address")
+	if address == "" {
+		writeError(w, http.StatusBadRequest, "address query parameter is required")
+		return
+	}
+	if !identity.ValidateAddress(address) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid address %q", address))
+		return
+	}
+	name, authorized := n.config.Signers.IsAuthorized(address)
+	writeJSON(w, http.StatusOK, authorizedResponse{Authorized: authorized, Name: name})
+}
+
+// maxHeadersPerRequest bounds a /headers/range response; clients page through longer ranges
+const maxHeadersPerRequest = 2000
+
+// handleHeadersRange serves block headers for heights from..to inclusive, so peers
+// can check signatures and linkage without downloading certificate lists
+func (n *Node) handleHeadersRange(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, err := parseHeightParam(query.Get("from"), 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid from: %v", err))
+		return
+	}
+	to, err := parseHeightParam(query.Get("to"), from+maxHeadersPerRequest-1)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid to: %v", err))
+		return
+	}
+	if to < from {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid range: to %d is below from %d", to, from))
+		return
+	}
+	if to-from >= maxHeadersPerRequest {
+		to = from + maxHeadersPerRequest - 1
+	}
+
+	headers, err := n.chain.HeadersInRange(from, to)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, headers)
+}
+
+// maxBlocksPerRequest bounds a /blocks/range response; clients page through longer ranges
+const maxBlocksPerRequest = 500
+
+// handleBlocksRange serves full blocks for heights from..to inclusive, in the
+// form /import accepts, for peers syncing the chain a batch at a time
+func (n *Node) handleBlocksRange(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, err := parseHeightParam(query.Get("from"), 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid from: %v", err))
+		return
+	}
+	to, err := parseHeightParam(query.Get("to"), from+maxBlocksPerRequest-1)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid to: %v", err))
+		return
+	}
+	if to < from {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid range: to %d is below from %d", to, from))
+		return
+	}
+	if to-from >= maxBlocksPerRequest {
+		to = from + maxBlocksPerRequest - 1
+	}
+
+	blocks, err := n.chain.BlocksInRange(from, to)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, blocks)
+}
+
+// maxCertificatesPerRequest bounds a /certificates page
+const maxCertificatesPerRequest = 1000
+
+// handleCertificates pages through the distinct certificate hashes on the
+// chain in the order they were first recorded, see
+// Blockchain.CertificateHashesPage. Pass the previous page's next cursor as after.
+func (n *Node) handleCertificates(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit := maxCertificatesPerRequest
+	if param := query.Get("limit"); param != "" {
+		parsed, err := strconv.Atoi(param)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid limit %q: must be a positive number", param))
+			return
+		}
+		limit = min(parsed, maxCertificatesPerRequest)
+	}
+
+	hashes, next, err := n.chain.CertificateHashesPage(query.Get("after"), limit)
+	if errors.Is(err, blockchain.ErrInvalidCertificateCursor) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, certificatesResponse{Certificates: hashes, Next: next})
+}
+
+// parseTimestampParam parses a Unix timestamp, returning def when the parameter is absent
+func parseTimestampParam(param string, def int64) (int64, error) {
+	if param == "" {
+		return def, nil
+	}
+	timestamp, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a Unix timestamp", param)
+	}
+	return timestamp, nil
+}
+
+// parseHeightParam parses a non-negative block height, returning def when the parameter is absent
+func parseHeightParam(param string, def int) (int, error) {
+	if param == "" {
+		return def, nil
+	}
+	height, err := strconv.Atoi(param)
+	if err != nil || height < 0 {
+		return 0, fmt.Errorf("%q is not a block height", param)
+	}
+	return height, nil
+}
+
+// handleBlocksBySigner lists the blocks created by the holder of a public key, oldest first
+func (n *Node) handleBlocksBySigner(w http.ResponseWriter, r *http.Request) {
+	var req blocksBySignerRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	publicKey, err := decodePublicKeyXY(req.X, req.Y)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	blocks, err := n.chain.BlocksSignedBy(publicKey)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := blocksBySignerResponse{
+		Address: string(identity.AddressFromPublicKey(publicKey)),
+		Blocks:  make([]signedBlock, 0, len(blocks)),
+	}
+	for _, block := range blocks {
+		resp.Blocks = append(resp.Blocks, signedBlock{Height: block.Height, Hash: hex.EncodeToString(block.Hash)})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// decodePublicKeyXY parses hex-encoded P-256 coordinates, rejecting points not on the curve
+func decodePublicKeyXY(xHex, yHex string) (ecdsa.PublicKey, error) {
+	const coordinateSize = 32
+	encoded := make([]byte, 0, 2*coordinateSize)
+	for _, c := range []struct{ name, value string }{{"x", xHex}, {"y", yHex}} {
+		// Coordinates written without their leading zero nibble, as big.Int.Text does, have odd length
+		if len(c.value)%2 == 1 {
+			c.value = "0" + c.value
+		}
+		coordinate, err := hex.DecodeString(c.value)
+		if err != nil || len(coordinate) == 0 {
+			return ecdsa.PublicKey{}, fmt.Errorf("invalid public key: %s must be a hex-encoded coordinate", c.name)
+		}
+		if len(coordinate) > coordinateSize {
+			return ecdsa.PublicKey{}, fmt.Errorf("invalid public key: %s is longer than %d bytes", c.name, coordinateSize)
+		}
+		encoded = append(encoded, make([]byte, coordinateSize-len(coordinate))...)
+		encoded = append(encoded, coordinate...)
+	}
+	return identity.DecodePublicKey(encoded)
+}
+
+// handleVerifyProof checks a client's Merkle proof against the Merkle root of
+// the block at the requested height on this node's chain, so thin clients need
+// not fetch the block. A proof that does not match is reported with valid
+// false rather than as an error.
+func (n *Node) handleVerifyProof(w http.ResponseWriter, r *http.Request) {
+	var req verifyProofRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if err := blockchain.ValidateCertificateID(req.CertificateID); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.BlockHeight < 0 {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid block height: %d", req.BlockHeight))
+		return
+	}
+
+	block, err := n.chain.GetBlockByHeight(req.BlockHeight)
+	if err != nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no block at height %d", req.BlockHeight))
+		return
+	}
+	writeJSON(w, http.StatusOK, verifyProofResponse{
+		Valid:       blockchain.VerifyProof([]byte(req.CertificateID), req.Proof, block.MerkleRoot),
+		BlockHeight: block.Height,
+		BlockHash:   hex.EncodeToString(block.Hash),
+		MerkleRoot:  hex.EncodeToString(block.MerkleRoot),
+	})
+}
+
+func (n *Node) handleIssued(w http.ResponseWriter, r *http.Request) {
+	address := r.URL.Query().Get("address")
+	if address == "" {
+		writeError(w, http.StatusBadRequest, "address query parameter is required")
+		return
+	}
+	if !identity.ValidateAddress(address) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid address %q", address))
+		return
+	}
+
+	issued, err := n.chain.IssuedBy(address)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, issuedResponse{Address: address, Certificates: issued})
+}
+
+func (n *Node) handleAddBlock(w http.ResponseWriter, r *http.Request) {
+	var req addBlockRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if len(req.Certificates) == 0 {
+		writeError(w, http.StatusBadRequest, "no certificates provided")
+		return
+	}
+	if err := n.config.CertificateIDPolicy.ValidateAll(req.Certificates); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !n.beginWrite() {
+		writeError(w, http.StatusServiceUnavailable, "node is shutting down")
+		return
+	}
+	defer n.writes.Done()
+
+	block, err := n.chain.AddBlock(req.Certificates, n.signer)
+	if errors.Is(err, blockchain.ErrBlockTooSoon) {
+		writeError(w, http.StatusTooManyRequests, err.Error())
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to add block: %v", err))
+		return
+	}
+
+	traceBlockHeight(w, block.Height)
+	resp := addBlockResponse{BlockSummaryDTO: blockSummary(block)}
+	if r.URL.Query().Get("receipt") == "true" {
+		// The block is already on the chain, so a signing failure is
+		// reported without a receipt rather than as a failed write
+		if resp.Receipts, err = n.issueReceipts(block); err != nil {
+			resp.ReceiptError = err.Error()
+		}
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (n *Node) handlePending(w http.ResponseWriter, r *http.Request) {
+	var req addBlockRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if len(req.Certificates) == 0 {
+		writeError(w, http.StatusBadRequest, "no certificates provided")
+		return
+	}
+	if err := n.config.CertificateIDPolicy.ValidateAll(req.Certificates); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pending, err := n.mempool.AddPending(req.Certificates...)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, pendingResponse{Pending: pending})
+}
+
+func (n *Node) handleFlush(w http.ResponseWriter, r *http.Request) {
+	if !n.beginWrite() {
+		writeError(w, http.StatusServiceUnavailable, "node is shutting down")
+		return
+	}
+	defer n.writes.Done()
+
+	block, err := n.mempool.Flush(n.signer)
+	if errors.Is(err, blockchain.ErrBlockTooSoon) {
+		writeError(w, http.StatusTooManyRequests, err.Error())
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to flush pending certificates: %v", err))
+		return
+	}
+	if block == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	traceBlockHeight(w, block.Height)
+	writeJSON(w, http.StatusCreated, blockSummary(block))
+}
+
+func (n *Node) handleCertStatus(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if err := blockchain.ValidateCertificateID(id); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	status, err := n.chain.CertificateStatus(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleVerifyCert reports whether a certificate is on the chain. With
+// all=true it lists every block recording it, newest first, so duplicate
+// issuance shows up as more than one occurrence.
+func (n *Node) handleVerifyCert(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if err := blockchain.ValidateCertificateID(id); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var blocks []*blockchain.Block
+	all := r.URL.Query().Get("all") == "true"
+	if all {
+		var err error
+		if blocks, err = n.chain.FindAllCertificateOccurrences(id); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	} else {
+		block, err := n.chain.FindCertificate(id)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if block != nil {
+			blocks = []*blockchain.Block{block}
+		}
+	}
+
+	if len(blocks) == 0 {
+		writeJSON(w, http.StatusOK, verifyCertResponse{Found: false})
+		return
+	}
+	resp := verifyCertResponse{
+		Found:       true,
+		BlockHeight: blocks[0].Height,
+		BlockHash:   hex.EncodeToString(blocks[0].Hash),
+	}
+	if all {
+		resp.Occurrences = make([]certificateOccurrence, len(blocks))
+		for i, block := range blocks {
+			issuedAt, _ := block.CertificateIssuedAt(id)
+			resp.Occurrences[i] = certificateOccurrence{
+				BlockHeight: block.Height,
+				BlockHash:   hex.EncodeToString(block.Hash),
+				IssuedAt:    issuedAt,
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (n *Node) handleRevoke(w http.ResponseWriter, r *http.Request) {
+	var req revokeRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if err := n.config.CertificateIDPolicy.Validate(req.CertificateID); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !n.beginWrite() {
+		writeError(w, http.StatusServiceUnavailable, "node is shutting down")
+		return
+	}
+	defer n.writes.Done()
+
+	revocation, err := n.chain.Revoke(req.CertificateID, req.Reason, n.signer)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("failed to revoke certificate: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, revocation)
+}
+
+// handleSupersede records that a certificate is replaced by a corrected one already on the chain
+func (n *Node) handleSupersede(w http.ResponseWriter, r *http.Request) {
+	var req supersedeRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if err := n.config.CertificateIDPolicy.ValidateAll([]string{req.CertificateID, req.NewCertificateID}); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !n.beginWrite() {
+		writeError(w, http.StatusServiceUnavailable, "node is shutting down")
+		return
+	}
+	defer n.writes.Done()
+
+	supersession, err := n.chain.Supersede(req.CertificateID, req.NewCertificateID, n.signer)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("failed to supersede certificate: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, supersession)
+}
+
+// maxImportErrors bounds how many rejection reasons an import response lists
+const maxImportErrors = 10
+
+// handleImport ingests an NDJSON stream of blocks, as produced by Export, into
+// an empty chain. With ?force=true a non-empty chain is replaced, which needs
+// Config.Signers: when it is set every block must come from an authorized
+// signer. The blocks
+// are imported into a staging chain first and only replace the node's chain
+// once the whole stream has been read and every block accepted, so a
+// malformed, rejected or interrupted import leaves the chain unchanged.
+func (n *Node) handleImport(w http.ResponseWriter, r *http.Request) {
+	force := r.URL.Query().Get("force") == "true"
+	if force && len(n.config.Signers) == 0 {
+		writeError(w, http.StatusForbidden, "a forced import needs an authorized signer registry to check the blocks against")
+		return
+	}
+
+	if !n.beginWrite() {
+		writeError(w, http.StatusServiceUnavailable, "node is shutting down")
+		return
+	}
+	defer n.writes.Done()
+
+	if !force && !n.chainIsEmpty(w) {
+		return
+	}
+
+	staging, err := n.chain.NewStagingChain()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer staging.Close()
+
+	var resp importResponse
+	reject := func(err error) {
+		resp.Rejected++
+		if len(resp.Errors) < maxImportErrors {
+			resp.Errors = append(resp.Errors, err.Error())
+		}
+	}
+	decoder := json.NewDecoder(r.Body)
+	for {
+		var block blockchain.Block
+		if err := decoder.Decode(&block); errors.Is(err, io.EOF) {
+			break
+		} else if tooLarge(err) {
+			writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+			return
+		} else if err != nil {
+			// A malformed line leaves the stream unreadable, so stop here
+			reject(fmt.Errorf("invalid block JSON: %v", err))
+			break
+		}
+		if len(n.config.Signers) > 0 {
+			if err := staging.CheckBlockSigner(&block, n.config.Signers); err != nil {
+				reject(err)
+				continue
+			}
+		}
+		if err := staging.ImportBlock(&block); err != nil {
+			reject(err)
+			continue
+		}
+		resp.Imported++
+	}
+	if resp.Imported == 0 && resp.Rejected == 0 {
+		reject(fmt.Errorf("import contains no blocks"))
+	}
+	if resp.Rejected > 0 {
+		// Nothing is applied unless every block was accepted
+		resp.Imported = 0
+		writeJSON(w, http.StatusUnprocessableEntity, resp)
+		return
+	}
+
+	// A block may have been added while the import was read
+	if !force && !n.chainIsEmpty(w) {
+		return
+	}
+	if err := n.chain.ReplaceWith(staging); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// chainIsEmpty reports whether the node's chain records nothing yet, writing
+// 409, or 500 when the chain cannot be read, when it does
+func (n *Node) chainIsEmpty(w http.ResponseWriter) bool {
+	empty, err := n.chain.IsEmpty()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return false
+	}
+	if !empty {
+		writeError(w, http.StatusConflict, "local chain is not empty; use ?force=true to replace it")
+		return false
+	}
+	return true
+}
+
+// maxConcurrentExports caps how many /export downloads are streamed at once
+const maxConcurrentExports = 2
+
+// handleExport streams the whole chain as a download, either as NDJSON (the
+// default, the format accepted by /import) or with ?format=json as a JSON array
+func (n *Node) handleExport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "ndjson"
+	}
+	var contentType string
+	switch format {
+	case "ndjson":
+		contentType = "application/x-ndjson"
+	case "json":
+		contentType = "application/json"
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unsupported format %q: expected ndjson or json", format))
+		return
+	}
+
+	select {
+	case n.exports <- struct{}{}:
+		defer func() { <-n.exports }()
+	default:
+		writeError(w, http.StatusTooManyRequests, "too many exports in progress")
+		return
+	}
+
+	chainID, err := n.chain.ChainID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(chainID) > 16 {
+		chainID = chainID[:16]
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="veritas-chain-%s.%s"`, chainID, format))
+	w.WriteHeader(http.StatusOK)
+
+	// The status is already sent, so a failure part way through can only
+	// truncate the download
+	if format == "json" {
+		aw := &jsonArrayWriter{w: w}
+		if n.chain.ExportContext(r.Context(), aw) == nil {
+			aw.Close()
+		}
+		return
+	}
+	_ = n.chain.ExportContext(r.Context(), w)
+}
+
+// jsonArrayWriter turns the NDJSON written to it into a single JSON array by
+// replacing the newlines between values with commas. Encoded JSON never
+// contains a raw newline, so every newline is a value separator.
+type jsonArrayWriter struct {
+	w       io.Writer
+	started bool
+	pending bool // a newline was seen and a comma is owed before the next value
+}
+
+func (a *jsonArrayWriter) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		chunk := p
+		if i >= 0 {
+			chunk = p[:i]
+		}
+		if len(chunk) > 0 {
+			prefix := ""
+			switch {
+			case !a.started:
+				prefix = "["
+			case a.pending:
+				prefix = ","
+			}
+			a.started, a.pending = true, false
+			if _, err := io.WriteString(a.w, prefix); err != nil {
+				return 0, err
+			}
+			if _, err := a.w.Write(chunk); err != nil {
+				return 0, err
+			}
+		}
+		if i < 0 {
+			break
+		}
+		a.pending = true
+		p = p[i+1:]
+	}
+	return written, nil
+}
+
+// Close terminates the array, writing an empty array if no values were written
+func (a *jsonArrayWriter) Close() error {
+	end := "]\n"
+	if !a.started {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(a.w, end)
+	return err
+}
+
+// BlockSummaryDTO is the JSON form of a block served by /blocks and returned by
+// block writes. Byte fields are hex-encoded; an unsigned block has an empty
+// signature and has_signature false.
+type BlockSummaryDTO struct {
+	Height            int      `json:"height"`
+	Hash              string   `json:"hash"`
+	ContentID         string   `json:"content_id"`
+	PrevHash          string   `json:"prev_hash"`
+	Timestamp         int64    `json:"timestamp"`
+	MerkleRoot        string   `json:"merkle_root"`
+	CertificateHashes []string `json:"certificate_hashes"`
+	Signature         string   `json:"signature"`
+	HasSignature      bool     `json:"has_signature"`
+	UniversityAddress string   `json:"university_address"`
+
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// blockSummary renders the externally visible fields of a block
+func blockSummary(block *blockchain.Block) BlockSummaryDTO {
+	certificateHashes := block.CertificateHashes
+	if certificateHashes == nil {
+		certificateHashes = []string{}
+	}
+	return BlockSummaryDTO{
+		Height:            block.Height,
+		Hash:              hex.EncodeToString(block.Hash),
+		ContentID:         hex.EncodeToString(block.ContentID()),
+		PrevHash:          hex.EncodeToString(block.PrevHash),
+		Timestamp:         block.Timestamp,
+		MerkleRoot:        hex.EncodeToString(block.MerkleRoot),
+		CertificateHashes: certificateHashes,
+		Signature:         hex.EncodeToString(block.Signature),
+		HasSignature:      len(block.Signature) > 0,
+		UniversityAddress: string(block.UniversityAddress),
+		Labels:            block.Labels,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+// limitRequestBody caps the request body at limit bytes. A request declaring a
+// larger Content-Length is refused with 413 before it reaches next; otherwise
+// reading past the limit fails with an *http.MaxBytesError.
+func limitRequestBody(limit int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body of %d bytes is over the %d byte limit", r.ContentLength, limit))
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// decodeJSONBody decodes the request body into v. On failure it writes 413 if
+// the body is over the size limit and 400 otherwise, and returns false.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return true
+	}
+	if tooLarge(err) {
+		writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+	} else {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+	}
+	return false
+}
+
+// tooLarge reports whether err came from reading past the request body limit
+func tooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
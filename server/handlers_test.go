@@ -0,0 +1,1262 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+// testChainOptions keeps test chains in memory and silences Badger
+var testChainOptions = blockchain.BlockchainOptions{InMemory: true, Logger: blockchain.NopLogger()}
+
+// newTestNode creates a node over a fresh chain in a temp dir
+func newTestNode(t *testing.T) (*Node, *blockchain.Blockchain, identity.Signer) {
+	t.Helper()
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	chain := blockchain.InitBlockchain("", signer, testChainOptions)
+	t.Cleanup(func() { chain.Close() })
+	return NewNode(chain, signer, Config{}), chain, signer
+}
+
+// doRequest serves a request against the node's handler and returns the recorder
+func doRequest(t *testing.T, node *Node, method, target string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	var req *http.Request
+	if body == "" {
+		req = httptest.NewRequest(method, target, nil)
+	} else {
+		req = httptest.NewRequest(method, target, strings.NewReader(body))
+	}
+	rec := httptest.NewRecorder()
+	node.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestChainIDEndpoint(t *testing.T) {
+	nodeA, chainA, _ := newTestNode(t)
+	nodeB, _, _ := newTestNode(t)
+
+	chainIDOf := func(node *Node) string {
+		rec := doRequest(t, node, http.MethodGet, "/chain-id", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rec.Code)
+		}
+		var resp map[string]string
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return resp["chain_id"]
+	}
+
+	idA := chainIDOf(nodeA)
+	want, _ := chainA.ChainID()
+	if idA != want {
+		t.Fatalf("expected chain ID %s, got %s", want, idA)
+	}
+	if idB := chainIDOf(nodeB); idB == idA {
+		t.Fatalf("nodes with different genesis blocks report the same chain ID %s", idA)
+	}
+}
+
+func TestNodeInfo(t *testing.T) {
+	node, _, signer := newTestNode(t)
+	node.config.University = "harvard"
+
+	rec := doRequest(t, node, http.MethodGet, "/node-info", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	var info nodeInfoResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if info.Address != string(signer.Address()) || info.University != "harvard" {
+		t.Fatalf("got %+v, want address %s for harvard", info, signer.Address())
+	}
+	publicKey, err := decodePublicKeyXY(info.PublicKey.X, info.PublicKey.Y)
+	if err != nil {
+		t.Fatalf("decode public key: %v", err)
+	}
+	if want := signer.PublicKey(); !publicKey.Equal(&want) {
+		t.Fatal("public key does not match the signer")
+	}
+	if strings.Contains(rec.Body.String(), "private") {
+		t.Fatalf("response mentions a private key: %s", rec.Body.String())
+	}
+}
+
+func TestAddBlockRejectsSeparatorInCertificateID(t *testing.T) {
+	node, chain, _ := newTestNode(t)
+
+	rec := doRequest(t, node, http.MethodPost, "/add-block", `{"certificates":["CERT-001","CERT,002"]}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `CERT,002`) {
+		t.Fatalf("expected error naming the offending ID, got %s", rec.Body.String())
+	}
+	if tip, _ := chain.Tip(); tip.Height != 0 {
+		t.Fatalf("rejected block was added at height %d", tip.Height)
+	}
+}
+
+func TestRequestBodyLimit(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	node.config.MaxRequestBodyBytes = 64
+	node.config.Signers = identity.AuthorizedSigners{"uni": string(signer.Address())}
+
+	oversized := `{"certificates":["` + strings.Repeat("A", 100) + `"]}`
+	for _, path := range []string{"/add-block", "/pending", "/revoke", "/verify-proof", "/rpc"} {
+		rec := doRequest(t, node, http.MethodPost, path, oversized)
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("POST %s: expected 413, got %d %s", path, rec.Code, rec.Body.String())
+		}
+	}
+	if tip, _ := chain.Tip(); tip.Height != 0 {
+		t.Fatalf("oversized request added a block at height %d", tip.Height)
+	}
+
+	rec := doRequest(t, node, http.MethodPost, "/add-block", `{"certificates":["CERT-001"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201 for a body under the limit, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	// /import has its own limit, checked before the chain is touched
+	export := doRequest(t, node, http.MethodGet, "/export", "").Body.String()
+	rec = doRequest(t, node, http.MethodPost, "/import?force=true", export)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /import under its own limit: expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	node.config.MaxImportBodyBytes = 64
+	rec = doRequest(t, node, http.MethodPost, "/import?force=true", export)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("POST /import: expected 413, got %d %s", rec.Code, rec.Body.String())
+	}
+	if tip, _ := chain.Tip(); tip == nil || tip.Height != 1 {
+		t.Fatalf("oversized import changed the chain, tip %v", tip)
+	}
+}
+
+func TestAddBlockAppliesCertificateIDPolicy(t *testing.T) {
+	node, chain, _ := newTestNode(t)
+	policy, err := blockchain.NewCertificateIDPolicy(16, blockchain.StrictCertificateIDCharset)
+	if err != nil {
+		t.Fatalf("NewCertificateIDPolicy: %v", err)
+	}
+	node.config.CertificateIDPolicy = policy
+
+	tests := []struct {
+		ids      string
+		wantCode int
+		wantErr  string
+	}{
+		{`["CERT-0123456789AB"]`, http.StatusBadRequest, "maximum is 16"},
+		{`["CERT-001","CERT 002"]`, http.StatusBadRequest, `character ' ' at byte 4`},
+		{`["CERT-001"]`, http.StatusCreated, ""},
+	}
+	for _, tt := range tests {
+		for _, path := range []string{"/add-block", "/pending"} {
+			rec := doRequest(t, node, http.MethodPost, path, `{"certificates":`+tt.ids+`}`)
+			wantCode := tt.wantCode
+			if wantCode == http.StatusCreated && path == "/pending" {
+				wantCode = http.StatusAccepted
+			}
+			if rec.Code != wantCode || !strings.Contains(rec.Body.String(), tt.wantErr) {
+				t.Errorf("POST %s %s: got %d %s, want %d containing %q", path, tt.ids, rec.Code, rec.Body.String(), wantCode, tt.wantErr)
+			}
+		}
+	}
+	if tip, _ := chain.Tip(); tip.Height != 1 {
+		t.Fatalf("expected only the valid block to be added, tip at height %d", tip.Height)
+	}
+
+	for path, body := range map[string]string{
+		"/revoke":    `{"certificate_id":"CERT 001","reason":"typo"}`,
+		"/supersede": `{"certificate_id":"CERT-001","new_certificate_id":"CERT_001"}`,
+	} {
+		rec := doRequest(t, node, http.MethodPost, path, body)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "not in the allowed set") {
+			t.Errorf("POST %s %s: got %d %s, want 400 naming the charset", path, body, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestMetricsEndpointExposesValidationHistogram(t *testing.T) {
+	node, _, _ := newTestNode(t)
+	doRequest(t, node, http.MethodGet, "/status", "")
+
+	rec := doRequest(t, node, http.MethodGet, "/metrics", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	for _, want := range []string{
+		"# TYPE veritas_validate_chain_duration_seconds histogram",
+		"veritas_validate_chain_duration_seconds_count",
+		"veritas_get_stats_duration_seconds_count",
+	} {
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Errorf("missing %q in /metrics output", want)
+		}
+	}
+}
+
+func TestBlocksSinceQuery(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	if _, err := chain.AddBlock([]string{"CERT-001"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	tip, _ := chain.Tip()
+
+	countBlocks := func(query string) int {
+		rec := doRequest(t, node, http.MethodGet, "/blocks"+query, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", query, rec.Code)
+		}
+		var blocks []map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &blocks); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return len(blocks)
+	}
+
+	if n := countBlocks(fmt.Sprintf("?since=%d", tip.Timestamp)); n < 1 {
+		t.Fatalf("expected the tip to be included, got %d blocks", n)
+	}
+	if n := countBlocks(fmt.Sprintf("?since=%d", tip.Timestamp+1)); n != 0 {
+		t.Fatalf("expected no blocks after the tip, got %d", n)
+	}
+	if rec := doRequest(t, node, http.MethodGet, "/blocks?since=yesterday", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid since, got %d", rec.Code)
+	}
+
+	if n := countBlocks(fmt.Sprintf("?since=%d&until=%d", tip.Timestamp, tip.Timestamp)); n < 1 {
+		t.Fatalf("expected the tip in a window ending at its timestamp, got %d blocks", n)
+	}
+	if n := countBlocks(fmt.Sprintf("?until=%d", tip.Timestamp-3600)); n != 0 {
+		t.Fatalf("expected no blocks until an hour before the tip, got %d", n)
+	}
+	if rec := doRequest(t, node, http.MethodGet, fmt.Sprintf("/blocks?since=%d&until=%d", tip.Timestamp, tip.Timestamp-1), ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for until before since, got %d", rec.Code)
+	}
+}
+
+// certHash returns the hex SHA-256 of a certificate ID as stored in blocks
+func certHash(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestIssuedReturnsOnlyQueriedSigner(t *testing.T) {
+	node, chain, signerA := newTestNode(t)
+	signerB := identity.NewIdentitySigner(identity.MakeIdentity())
+
+	for _, add := range []struct {
+		ids    []string
+		signer identity.Signer
+	}{
+		{[]string{"CERT-A1", "CERT-A2"}, signerA},
+		{[]string{"CERT-B1"}, signerB},
+		{[]string{"CERT-A3"}, signerA},
+	} {
+		if _, err := chain.AddBlock(add.ids, add.signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+
+	rec := doRequest(t, node, http.MethodGet, "/issued?address="+string(signerA.Address()), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp issuedResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	want := []blockchain.IssuedCertificate{
+		{CertificateHash: certHash("CERT-A1"), BlockHeight: 1},
+		{CertificateHash: certHash("CERT-A2"), BlockHeight: 1},
+		{CertificateHash: certHash("CERT-A3"), BlockHeight: 3},
+	}
+	if len(resp.Certificates) != len(want) {
+		t.Fatalf("expected %d certificates, got %+v", len(want), resp.Certificates)
+	}
+	for i := range want {
+		if resp.Certificates[i] != want[i] {
+			t.Errorf("certificate %d: expected %+v, got %+v", i, want[i], resp.Certificates[i])
+		}
+	}
+}
+
+func TestIssuedRejectsInvalidAddress(t *testing.T) {
+	node, _, _ := newTestNode(t)
+
+	for _, query := range []string{"", "?address=", "?address=0OIl", "?address=abc"} {
+		if rec := doRequest(t, node, http.MethodGet, "/issued"+query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestLatestBlock(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	var latest *blockchain.Block
+	for i := 0; i < 3; i++ {
+		block, err := chain.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, signer)
+		if err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+		latest = block
+	}
+
+	rec := doRequest(t, node, http.MethodGet, "/block/latest", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var header blockchain.BlockHeader
+	if err := json.Unmarshal(rec.Body.Bytes(), &header); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if header.Height != 3 || !bytes.Equal(header.Hash, latest.Hash) {
+		t.Fatalf("expected the block at height 3 (%x), got height %d (%x)", latest.Hash, header.Height, header.Hash)
+	}
+	if err := header.Validate(); err != nil {
+		t.Fatalf("served header invalid: %v", err)
+	}
+}
+
+func TestRawBlockRoundTrips(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	block, err := chain.AddBlock([]string{"CERT-001", "CERT-002"}, signer)
+	if err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+
+	rec := doRequest(t, node, http.MethodGet, "/block/raw?hash="+hex.EncodeToString(block.Hash), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/octet-stream" {
+		t.Fatalf("expected application/octet-stream, got %q", ct)
+	}
+	decoded := blockchain.Deserialize(rec.Body.Bytes())
+	if !reflect.DeepEqual(decoded, block) {
+		t.Fatalf("raw block decoded to %+v, want %+v", decoded, block)
+	}
+	if err := decoded.Validate(); err != nil {
+		t.Fatalf("decoded block invalid: %v", err)
+	}
+
+	missing := sha256.Sum256([]byte("no such block"))
+	for target, want := range map[string]int{
+		"/block/raw?hash=" + hex.EncodeToString(missing[:]): http.StatusNotFound,
+		"/block/raw?hash=zz": http.StatusBadRequest,
+		"/block/raw?hash=" + hex.EncodeToString([]byte("lh")): http.StatusBadRequest,
+	} {
+		if rec := doRequest(t, node, http.MethodGet, target, ""); rec.Code != want {
+			t.Errorf("GET %s: expected %d, got %d: %s", target, want, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+// flushRecorder records the most bytes written between two flushes
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes      int
+	unflushed    int
+	maxUnflushed int
+}
+
+func (f *flushRecorder) Write(p []byte) (int, error) {
+	f.unflushed += len(p)
+	f.maxUnflushed = max(f.maxUnflushed, f.unflushed)
+	return f.ResponseRecorder.Write(p)
+}
+
+func (f *flushRecorder) Flush() {
+	f.flushes++
+	f.unflushed = 0
+	f.ResponseRecorder.Flush()
+}
+
+func TestBlocksStreamsLargeChain(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	const count = 450
+	for i := 0; i < count; i++ {
+		if _, err := chain.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+
+	rec := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	node.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/blocks", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var blocks []BlockSummaryDTO
+	if err := json.Unmarshal(rec.Body.Bytes(), &blocks); err != nil {
+		t.Fatalf("streamed array is malformed: %v", err)
+	}
+	if len(blocks) != count+1 || blocks[0].Height != count || blocks[count].Height != 0 {
+		t.Fatalf("expected %d blocks from the tip down, got %d", count+1, len(blocks))
+	}
+
+	// The response goes out in chunks of a bounded size rather than all at once
+	if rec.flushes < count/blocksFlushInterval {
+		t.Fatalf("expected at least %d flushes, got %d", count/blocksFlushInterval, rec.flushes)
+	}
+	if limit := rec.Body.Len() / 2; rec.maxUnflushed > limit {
+		t.Fatalf("wrote %d bytes without flushing, more than half the %d byte response", rec.maxUnflushed, rec.Body.Len())
+	}
+
+	// A cancelled request stops the walk, leaving the array unterminated
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cancelled := httptest.NewRecorder()
+	node.Handler().ServeHTTP(cancelled, httptest.NewRequest(http.MethodGet, "/blocks", nil).WithContext(ctx))
+	if json.Valid(cancelled.Body.Bytes()) {
+		t.Fatalf("expected a truncated response for a cancelled request, got %s", cancelled.Body.String())
+	}
+}
+
+func TestBlocksFilteredByLabel(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	first, err := chain.AddBlock([]string{"CERT-001"}, signer)
+	if err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	if _, err := chain.AddBlock([]string{"CERT-002"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+
+	body := fmt.Sprintf(`{"hash":%q,"labels":{"batch":"spring:2024"}}`, hex.EncodeToString(first.Hash))
+	rec := doRequest(t, node, http.MethodPost, "/block/labels", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var labeled BlockSummaryDTO
+	if err := json.Unmarshal(rec.Body.Bytes(), &labeled); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if labeled.Hash != hex.EncodeToString(first.Hash) || labeled.Labels["batch"] != "spring:2024" {
+		t.Fatalf("unexpected labeled block %+v", labeled)
+	}
+
+	rec = doRequest(t, node, http.MethodGet, "/blocks?label=batch:spring:2024", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var blocks []BlockSummaryDTO
+	if err := json.Unmarshal(rec.Body.Bytes(), &blocks); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(blocks) != 1 || blocks[0].Height != first.Height {
+		t.Fatalf("expected only block %d, got %+v", first.Height, blocks)
+	}
+
+	rec = doRequest(t, node, http.MethodGet, "/blocks?label=batch:fall&since=0", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &blocks); err != nil || len(blocks) != 0 {
+		t.Fatalf("expected no blocks for another value, got %s", rec.Body.String())
+	}
+	if rec := doRequest(t, node, http.MethodGet, "/blocks?label=batch", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a label without a value, got %d", rec.Code)
+	}
+
+	missing := sha256.Sum256([]byte("no such block"))
+	body = fmt.Sprintf(`{"hash":%q,"labels":{"batch":"x"}}`, hex.EncodeToString(missing[:]))
+	if rec := doRequest(t, node, http.MethodPost, "/block/labels", body); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a missing block, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("chain invalid after labeling: %v", err)
+	}
+}
+
+func TestVerifyProof(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	block, err := chain.AddBlock([]string{"CERT-001", "CERT-002", "CERT-003"}, signer)
+	if err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	if _, err := chain.AddBlock([]string{"CERT-004", "CERT-005"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	proof, err := block.GenerateCertificateProof("CERT-002")
+	if err != nil {
+		t.Fatalf("generate proof: %v", err)
+	}
+
+	verify := func(certificateID string, height int) (int, verifyProofResponse) {
+		t.Helper()
+		body, err := json.Marshal(verifyProofRequest{CertificateID: certificateID, Proof: proof, BlockHeight: height})
+		if err != nil {
+			t.Fatalf("encode request: %v", err)
+		}
+		rec := doRequest(t, node, http.MethodPost, "/verify-proof", string(body))
+		var resp verifyProofResponse
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+		}
+		return rec.Code, resp
+	}
+
+	code, resp := verify("CERT-002", block.Height)
+	if code != http.StatusOK || !resp.Valid || resp.BlockHash != hex.EncodeToString(block.Hash) {
+		t.Fatalf("expected a valid proof, got %d %+v", code, resp)
+	}
+	if code, resp := verify("CERT-001", block.Height); code != http.StatusOK || resp.Valid {
+		t.Fatalf("expected a proof for another certificate to be invalid, got %d %+v", code, resp)
+	}
+	if code, resp := verify("CERT-002", block.Height+1); code != http.StatusOK || resp.Valid {
+		t.Fatalf("expected the proof to be invalid against another block, got %d %+v", code, resp)
+	}
+	if code, _ := verify("CERT-002", block.Height+5); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a height above the tip, got %d", code)
+	}
+	if code, _ := verify("CERT-002", -1); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a negative height, got %d", code)
+	}
+}
+
+func TestStats(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	if _, err := chain.AddBlock([]string{"CERT-001"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+
+	rec := doRequest(t, node, http.MethodGet, "/stats", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var info blockchain.ChainInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	genesis, _ := chain.GetBlockByHeight(0)
+	tip, _ := chain.Tip()
+	if info.Height != 1 || info.GenesisTimestamp != genesis.Timestamp || info.TipTimestamp != tip.Timestamp {
+		t.Fatalf("unexpected stats %+v", info)
+	}
+}
+
+func TestIsAuthorized(t *testing.T) {
+	_, chain, signer := newTestNode(t)
+	node := NewNode(chain, signer, Config{Signers: identity.AuthorizedSigners{"harvard": string(signer.Address())}})
+
+	check := func(address string, want authorizedResponse) {
+		t.Helper()
+		rec := doRequest(t, node, http.MethodGet, "/is-authorized?address="+address, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var got authorizedResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if got != want {
+			t.Fatalf("address %s: expected %+v, got %+v", address, want, got)
+		}
+	}
+	check(string(signer.Address()), authorizedResponse{Authorized: true, Name: "harvard"})
+	check(string(identity.MakeIdentity().Address()), authorizedResponse{Authorized: false})
+
+	for _, query := range []string{"", "?address=not-an-address", "?address=0OIl"} {
+		if rec := doRequest(t, node, http.MethodGet, "/is-authorized"+query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestHeadersRange(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	for i := 0; i < 3; i++ {
+		if _, err := chain.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+
+	rec := doRequest(t, node, http.MethodGet, "/headers/range?from=1&to=10", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var headers []*blockchain.BlockHeader
+	if err := json.Unmarshal(rec.Body.Bytes(), &headers); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(headers) != 3 || headers[0].Height != 1 || headers[2].Height != 3 {
+		t.Fatalf("expected headers 1-3, got %d headers", len(headers))
+	}
+	if err := blockchain.ValidateHeaders(headers); err != nil {
+		t.Fatalf("served headers invalid: %v", err)
+	}
+	for _, header := range headers {
+		if !header.Verify(signer.PublicKey()) {
+			t.Fatalf("header %d signature failed to verify", header.Height)
+		}
+	}
+
+	for _, query := range []string{"?from=-1", "?from=x", "?from=3&to=1"} {
+		if rec := doRequest(t, node, http.MethodGet, "/headers/range"+query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestReadOnlyNodeHasNoWriteRoutes(t *testing.T) {
+	_, chain, signer := newTestNode(t)
+	node := NewNode(chain, signer, Config{ReadOnly: true})
+
+	rec := doRequest(t, node, http.MethodPost, "/add-block", `{"certificates":["CERT-001"]}`)
+	if rec.Code != http.StatusNotFound && rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 404 or 405 for /add-block, got %d", rec.Code)
+	}
+	if tip, _ := chain.Tip(); tip.Height != 0 {
+		t.Fatalf("read-only node added a block at height %d", tip.Height)
+	}
+
+	if rec := doRequest(t, node, http.MethodPost, "/revoke", `{"certificate_id":"CERT-001"}`); rec.Code != http.StatusNotFound && rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 404 or 405 for /revoke, got %d", rec.Code)
+	}
+
+	for _, target := range []string{"/health", "/status", "/chain-id", "/blocks"} {
+		if rec := doRequest(t, node, http.MethodGet, target, ""); rec.Code != http.StatusOK {
+			t.Errorf("GET %s: expected 200 in read-only mode, got %d", target, rec.Code)
+		}
+	}
+}
+
+func TestVerifyCertAllOccurrences(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	first, err := chain.AddBlock([]string{"CERT-001"}, signer)
+	if err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	second, err := chain.AddBlock([]string{"CERT-001", "CERT-002"}, signer)
+	if err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+
+	verify := func(target string) verifyCertResponse {
+		t.Helper()
+		rec := doRequest(t, node, http.MethodGet, target, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d: %s", target, rec.Code, rec.Body.String())
+		}
+		var resp verifyCertResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp
+	}
+
+	resp := verify("/verify-cert?id=CERT-001&all=true")
+	if !resp.Found || len(resp.Occurrences) != 2 {
+		t.Fatalf("expected two occurrences, got %+v", resp)
+	}
+	if resp.Occurrences[0].BlockHash != hex.EncodeToString(second.Hash) || resp.Occurrences[1].BlockHash != hex.EncodeToString(first.Hash) {
+		t.Fatalf("expected blocks %d and %d, newest first, got %+v", second.Height, first.Height, resp.Occurrences)
+	}
+
+	resp = verify("/verify-cert?id=CERT-001")
+	if !resp.Found || resp.BlockHeight != second.Height || resp.Occurrences != nil {
+		t.Fatalf("expected only the newest block without all=true, got %+v", resp)
+	}
+	if resp := verify("/verify-cert?id=CERT-002&all=true"); len(resp.Occurrences) != 1 {
+		t.Fatalf("expected one occurrence, got %+v", resp)
+	}
+	if resp := verify("/verify-cert?id=CERT-404&all=true"); resp.Found || len(resp.Occurrences) != 0 {
+		t.Fatalf("expected a missing certificate not to be found, got %+v", resp)
+	}
+	if rec := doRequest(t, node, http.MethodGet, "/verify-cert", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without an id, got %d", rec.Code)
+	}
+}
+
+func TestCertStatus(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	if _, err := chain.AddBlock([]string{"CERT-001", "CERT-002"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	if rec := doRequest(t, node, http.MethodPost, "/revoke", `{"certificate_id":"CERT-002","reason":"issued in error"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("revoke: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	statusOf := func(id string) blockchain.CertificateStatus {
+		rec := doRequest(t, node, http.MethodGet, "/cert-status?id="+id, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", id, rec.Code)
+		}
+		var status blockchain.CertificateStatus
+		if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return status
+	}
+
+	if status := statusOf("CERT-001"); status.Status != blockchain.StatusValid || status.BlockHeight != 1 {
+		t.Errorf("CERT-001: expected valid at height 1, got %+v", status)
+	}
+	if status := statusOf("CERT-002"); status.Status != blockchain.StatusRevoked || status.BlockHeight != 1 || status.Revocation.Reason != "issued in error" {
+		t.Errorf("CERT-002: expected revoked at height 1, got %+v", status)
+	}
+	if status := statusOf("CERT-404"); status.Status != blockchain.StatusUnknown {
+		t.Errorf("CERT-404: expected unknown, got %+v", status)
+	}
+	if rec := doRequest(t, node, http.MethodGet, "/cert-status", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without an id, got %d", rec.Code)
+	}
+}
+
+func TestRevokeRequiresIssuer(t *testing.T) {
+	node, chain, _ := newTestNode(t)
+	other := identity.NewIdentitySigner(identity.MakeIdentity())
+	if _, err := chain.AddBlock([]string{"CERT-OTHER"}, other); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+
+	if rec := doRequest(t, node, http.MethodPost, "/revoke", `{"certificate_id":"CERT-OTHER"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 revoking another university's certificate, got %d", rec.Code)
+	}
+	if revoked, _ := chain.IsRevoked("CERT-OTHER"); revoked {
+		t.Fatal("certificate was revoked by a non-issuer")
+	}
+}
+
+func TestImportExportedChain(t *testing.T) {
+	_, source, signer := newTestNode(t)
+	for i := 0; i < 3; i++ {
+		if _, err := source.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+	var export strings.Builder
+	if err := source.Export(&export); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	node, chain, _ := newTestNode(t)
+	node.config.Signers = identity.AuthorizedSigners{"uni": string(signer.Address())}
+	rec := doRequest(t, node, http.MethodPost, "/import", export.String())
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp importResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Imported != 4 || resp.Rejected != 0 {
+		t.Fatalf("expected 4 imported and 0 rejected, got %+v", resp)
+	}
+
+	wantID, _ := source.ChainID()
+	if gotID, _ := chain.ChainID(); gotID != wantID {
+		t.Fatalf("imported chain ID %s, expected %s", gotID, wantID)
+	}
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("imported chain invalid: %v", err)
+	}
+	if block, _ := chain.FindCertificate("CERT-002"); block == nil || block.Height != 3 {
+		t.Fatal("imported chain is missing CERT-002 at height 3")
+	}
+
+	// The chain now holds certificates, so a second import needs force
+	if rec := doRequest(t, node, http.MethodPost, "/import", export.String()); rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 importing into a non-empty chain, got %d", rec.Code)
+	}
+	if rec := doRequest(t, node, http.MethodPost, "/import?force=true", export.String()); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for a forced import, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestImportRejectsTamperedBlock(t *testing.T) {
+	_, source, signer := newTestNode(t)
+	if _, err := source.AddBlock([]string{"CERT-001"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	var export strings.Builder
+	if err := source.Export(&export); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(export.String()), "\n")
+	var block blockchain.Block
+	if err := json.Unmarshal([]byte(lines[1]), &block); err != nil {
+		t.Fatalf("decode block: %v", err)
+	}
+	block.CertificateHashes = []string{certHash("CERT-FORGED")}
+	tampered, _ := json.Marshal(block)
+
+	node, _, _ := newTestNode(t)
+	rec := doRequest(t, node, http.MethodPost, "/import", lines[0]+"\n"+string(tampered)+"\n")
+	var resp importResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if rec.Code != http.StatusUnprocessableEntity || resp.Imported != 0 || resp.Rejected != 1 {
+		t.Fatalf("expected the tampered block rejected and nothing imported, got %d %+v", rec.Code, resp)
+	}
+}
+
+func TestFailedForcedImportKeepsChain(t *testing.T) {
+	_, source, sourceSigner := newTestNode(t)
+	if _, err := source.AddBlock([]string{"CERT-NEW"}, sourceSigner); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+	var export strings.Builder
+	if err := source.Export(&export); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	node, chain, signer := newTestNode(t)
+	for i := 0; i < 3; i++ {
+		if _, err := chain.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+	before, _ := chain.Tip()
+
+	// Without a signer registry nothing may replace the chain
+	if rec := doRequest(t, node, http.MethodPost, "/import?force=true", export.String()); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a forced import without signers, got %d %s", rec.Code, rec.Body.String())
+	}
+	// Nor may a chain signed by a key outside the registry
+	node.config.Signers = identity.AuthorizedSigners{"uni": string(signer.Address())}
+	rec := doRequest(t, node, http.MethodPost, "/import?force=true", export.String())
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "not an authorized signer") {
+		t.Fatalf("expected 422 for an unauthorized chain, got %d %s", rec.Code, rec.Body.String())
+	}
+	if tip, err := chain.Tip(); err != nil || !bytes.Equal(tip.Hash, before.Hash) {
+		t.Fatal("unauthorized import changed the chain")
+	}
+	node.config.Signers["source"] = string(sourceSigner.Address())
+
+	for name, body := range map[string]string{
+		"malformed": export.String() + "{not json\n",
+		"truncated": export.String()[:len(export.String())/2],
+		"empty":     "",
+	} {
+		rec := doRequest(t, node, http.MethodPost, "/import?force=true", body)
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected 422, got %d %s", name, rec.Code, rec.Body.String())
+		}
+		if tip, err := chain.Tip(); err != nil || !bytes.Equal(tip.Hash, before.Hash) {
+			t.Fatalf("%s: failed import changed the chain", name)
+		}
+	}
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("chain invalid after failed imports: %v", err)
+	}
+
+	rec = doRequest(t, node, http.MethodPost, "/import?force=true", export.String())
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rec.Code, rec.Body.String())
+	}
+	if block, _ := chain.FindCertificate("CERT-NEW"); block == nil {
+		t.Fatal("forced import did not replace the chain")
+	}
+	if block, _ := chain.FindCertificate("CERT-001"); block != nil {
+		t.Fatal("replaced chain still holds the old certificates")
+	}
+	if block, err := chain.GetBlockByHeight(3); err == nil {
+		t.Fatalf("stale height index entry points at block %x", block.Hash)
+	}
+	if err := chain.ValidateChain(); err != nil {
+		t.Fatalf("replaced chain invalid: %v", err)
+	}
+}
+
+func TestExportDownloadReimports(t *testing.T) {
+	source, chain, signer := newTestNode(t)
+	for i := 0; i < 3; i++ {
+		if _, err := chain.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+
+	rec := doRequest(t, source, http.MethodGet, "/export", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	chainID, _ := chain.ChainID()
+	wantDisposition := fmt.Sprintf(`attachment; filename="veritas-chain-%s.ndjson"`, chainID[:16])
+	if got := rec.Header().Get("Content-Disposition"); got != wantDisposition {
+		t.Fatalf("Content-Disposition %q, expected %q", got, wantDisposition)
+	}
+
+	node, imported, _ := newTestNode(t)
+	if rec := doRequest(t, node, http.MethodPost, "/import", rec.Body.String()); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 importing the download, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if gotID, _ := imported.ChainID(); gotID != chainID {
+		t.Fatalf("imported chain ID %s, expected %s", gotID, chainID)
+	}
+	if err := imported.ValidateChain(); err != nil {
+		t.Fatalf("imported chain invalid: %v", err)
+	}
+
+	rec = doRequest(t, source, http.MethodGet, "/export?format=json", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var blocks []blockchain.Block
+	if err := json.Unmarshal(rec.Body.Bytes(), &blocks); err != nil {
+		t.Fatalf("decode JSON export: %v", err)
+	}
+	if len(blocks) != 4 || blocks[3].Height != 3 {
+		t.Fatalf("expected 4 blocks ending at height 3, got %d", len(blocks))
+	}
+
+	if rec := doRequest(t, source, http.MethodGet, "/export?format=xml", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown format, got %d", rec.Code)
+	}
+}
+
+func TestExportConcurrencyLimit(t *testing.T) {
+	node, _, _ := newTestNode(t)
+	for i := 0; i < maxConcurrentExports; i++ {
+		node.exports <- struct{}{}
+	}
+	if rec := doRequest(t, node, http.MethodGet, "/export", ""); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 with all export slots taken, got %d", rec.Code)
+	}
+	<-node.exports
+	if rec := doRequest(t, node, http.MethodGet, "/export", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 once a slot is free, got %d", rec.Code)
+	}
+}
+
+func TestStatsByUniversity(t *testing.T) {
+	_, chain, signerA := newTestNode(t)
+	signerB := identity.NewIdentitySigner(identity.MakeIdentity())
+	node := NewNode(chain, signerA, Config{Signers: identity.AuthorizedSigners{"uni-b": string(signerB.Address())}})
+
+	for _, add := range []struct {
+		ids    []string
+		signer identity.Signer
+	}{
+		{[]string{"CERT-A1", "CERT-A2"}, signerA},
+		{[]string{"CERT-B1"}, signerB},
+		{[]string{"CERT-B2", "CERT-B3", "CERT-B4"}, signerB},
+	} {
+		if _, err := chain.AddBlock(add.ids, add.signer); err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+	}
+
+	rec := doRequest(t, node, http.MethodGet, "/stats/by-university", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var stats []universityStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	byAddress := make(map[string]universityStats)
+	for _, s := range stats {
+		byAddress[s.Address] = s
+	}
+	// Signer A also created the genesis block
+	want := map[string]universityStats{
+		string(signerA.Address()): {Address: string(signerA.Address()), BlockCount: 2, CertificateCount: 2},
+		string(signerB.Address()): {Address: string(signerB.Address()), Name: "uni-b", BlockCount: 2, CertificateCount: 4},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("expected %d universities, got %+v", len(want), stats)
+	}
+	for address, w := range want {
+		if byAddress[address] != w {
+			t.Errorf("expected %+v, got %+v", w, byAddress[address])
+		}
+	}
+}
+
+func TestPendingAndFlush(t *testing.T) {
+	node, chain, _ := newTestNode(t)
+
+	rec := doRequest(t, node, http.MethodPost, "/flush", "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 from an empty flush, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	for _, body := range []string{`{"certificates":["CERT-001"]}`, `{"certificates":["CERT-002","CERT-003"]}`} {
+		rec = doRequest(t, node, http.MethodPost, "/pending", body)
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	var pending pendingResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &pending); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if pending.Pending != 3 {
+		t.Fatalf("expected 3 pending certificates, got %d", pending.Pending)
+	}
+
+	rec = doRequest(t, node, http.MethodPost, "/flush", "")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	tip, err := chain.Tip()
+	if err != nil {
+		t.Fatalf("tip: %v", err)
+	}
+	if tip.Height != 1 || tip.GetCertificateCount() != 3 {
+		t.Fatalf("expected one block holding 3 certificates, got height %d with %d", tip.Height, tip.GetCertificateCount())
+	}
+}
+
+func TestCertificatesPagination(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	ids := []string{"CERT-001", "CERT-002", "CERT-003"}
+	if _, err := chain.AddBlock(ids, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+
+	var got []string
+	target := "/certificates?limit=2"
+	for pages := 0; ; pages++ {
+		if pages > len(ids) {
+			t.Fatal("pagination did not terminate")
+		}
+		rec := doRequest(t, node, http.MethodGet, target, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp certificatesResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		got = append(got, resp.Certificates...)
+		if resp.Next == "" {
+			break
+		}
+		target = "/certificates?limit=2&after=" + resp.Next
+	}
+
+	tip, err := chain.Tip()
+	if err != nil {
+		t.Fatalf("tip: %v", err)
+	}
+	if want := tip.CertificateHashes; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	if rec := doRequest(t, node, http.MethodGet, "/certificates?limit=0", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for limit=0, got %d", rec.Code)
+	}
+	if rec := doRequest(t, node, http.MethodGet, "/certificates?after=tip", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a malformed cursor, got %d", rec.Code)
+	}
+}
+
+func TestBlocksBySigner(t *testing.T) {
+	node, chain, signerA := newTestNode(t)
+	signerB := identity.NewIdentitySigner(identity.MakeIdentity())
+
+	var wantHeights []int
+	for i, signer := range []identity.Signer{signerB, signerA, signerB} {
+		block, err := chain.AddBlock([]string{fmt.Sprintf("CERT-%03d", i)}, signer)
+		if err != nil {
+			t.Fatalf("add block: %v", err)
+		}
+		if signer == signerB {
+			wantHeights = append(wantHeights, block.Height)
+		}
+	}
+
+	pub := signerB.PublicKey()
+	body := fmt.Sprintf(`{"x":%q,"y":%q}`, pub.X.Text(16), pub.Y.Text(16))
+	rec := doRequest(t, node, http.MethodPost, "/blocks/by-signer", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp blocksBySignerResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Address != string(signerB.Address()) {
+		t.Fatalf("expected address %s, got %s", signerB.Address(), resp.Address)
+	}
+	var gotHeights []int
+	for _, b := range resp.Blocks {
+		gotHeights = append(gotHeights, b.Height)
+	}
+	if fmt.Sprint(gotHeights) != fmt.Sprint(wantHeights) {
+		t.Fatalf("expected heights %v, got %v", wantHeights, gotHeights)
+	}
+
+	// A point off the curve is rejected
+	body = fmt.Sprintf(`{"x":%q,"y":%q}`, pub.X.Text(16), new(big.Int).Add(pub.Y, big.NewInt(1)).Text(16))
+	if rec := doRequest(t, node, http.MethodPost, "/blocks/by-signer", body); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a point off the curve, got %d", rec.Code)
+	}
+	if rec := doRequest(t, node, http.MethodPost, "/blocks/by-signer", `{"x":"zz","y":"01"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for malformed hex, got %d", rec.Code)
+	}
+
+	// A coordinate with a leading zero nibble is accepted without it
+	signerC := identity.NewIdentitySigner(identity.MakeIdentity())
+	for n := signerC.PublicKey().X.BitLen(); n > 252 || n <= 248; n = signerC.PublicKey().X.BitLen() {
+		signerC = identity.NewIdentitySigner(identity.MakeIdentity())
+	}
+	pubC := signerC.PublicKey()
+	if len(pubC.X.Text(16))%2 != 1 {
+		t.Fatalf("expected an odd-length x coordinate, got %s", pubC.X.Text(16))
+	}
+	decoded, err := decodePublicKeyXY(pubC.X.Text(16), hex.EncodeToString(pubC.Y.FillBytes(make([]byte, 32))))
+	if err != nil {
+		t.Fatalf("odd-length coordinate rejected: %v", err)
+	}
+	if !decoded.Equal(&pubC) {
+		t.Fatal("odd-length coordinate decoded to a different key")
+	}
+}
+
+func TestBlocksJSONShape(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	if _, err := chain.AddBlock([]string{"CERT-001"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+
+	rec := doRequest(t, node, http.MethodGet, "/blocks", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var blocks []map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &blocks); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %d", len(blocks))
+	}
+	wantKeys := []string{"certificate_hashes", "content_id", "has_signature", "hash", "height", "merkle_root", "prev_hash", "signature", "timestamp", "university_address"}
+	for _, block := range blocks {
+		var keys []string
+		for key := range block {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		if strings.Join(keys, ",") != strings.Join(wantKeys, ",") {
+			t.Fatalf("expected keys %v, got %v", wantKeys, keys)
+		}
+		if string(block["has_signature"]) != "true" {
+			t.Fatalf("expected signed blocks to report has_signature true, got %s", block["has_signature"])
+		}
+	}
+	// The genesis block records no certificates: an empty list, not null
+	if got := string(blocks[1]["certificate_hashes"]); got != "[]" {
+		t.Fatalf("expected genesis certificate_hashes [], got %s", got)
+	}
+}
+
+func TestBlockSummaryUnsignedBlock(t *testing.T) {
+	data, err := json.Marshal(blockSummary(&blockchain.Block{Height: 3}))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, want := range []string{`"signature":""`, `"has_signature":false`, `"certificate_hashes":[]`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %s in %s", want, data)
+		}
+	}
+}
+
+func TestSupersedeReportedByCertStatus(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	if _, err := chain.AddBlock([]string{"CERT-001", "CERT-001-R1"}, signer); err != nil {
+		t.Fatalf("add block: %v", err)
+	}
+
+	rec := doRequest(t, node, http.MethodPost, "/supersede", `{"certificate_id":"CERT-001","new_certificate_id":"CERT-001-R1"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doRequest(t, node, http.MethodGet, "/cert-status?id=CERT-001", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var status blockchain.CertificateStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if status.Status != blockchain.StatusSuperseded || status.SupersededBy != "CERT-001-R1" {
+		t.Fatalf("expected CERT-001 superseded by CERT-001-R1, got %+v", status)
+	}
+}
+
+func TestAccumulatorRootVerifiesInclusionProof(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	for i := 0; i < 3; i++ {
+		if _, err := chain.AddBlock([]string{fmt.Sprintf("CERT-ACC-%d", i)}, signer); err != nil {
+			t.Fatalf("AddBlock: %v", err)
+		}
+	}
+
+	rec := doRequest(t, node, http.MethodGet, "/accumulator-root", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp accumulatorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Height != 3 {
+		t.Fatalf("expected the root to cover height 3, got %d", resp.Height)
+	}
+	root, err := hex.DecodeString(resp.Root)
+	if err != nil {
+		t.Fatalf("decode root: %v", err)
+	}
+
+	proof, _, err := chain.BlockInclusionProof(2)
+	if err != nil {
+		t.Fatalf("BlockInclusionProof: %v", err)
+	}
+	block, err := chain.GetBlockByHeight(2)
+	if err != nil {
+		t.Fatalf("GetBlockByHeight: %v", err)
+	}
+	if !blockchain.VerifyBlockInclusion(block.Hash, proof, root) {
+		t.Fatal("served accumulator root does not verify a fresh inclusion proof")
+	}
+}
+
+func TestCountEndpoint(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	for i := 0; i < 4; i++ {
+		if _, err := chain.AddBlock([]string{fmt.Sprintf("CERT-COUNT-%d", i)}, signer); err != nil {
+			t.Fatalf("AddBlock: %v", err)
+		}
+	}
+
+	rec := doRequest(t, node, http.MethodGet, "/count", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp countResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	// genesis plus the four added blocks
+	if resp.BlockCount != 5 {
+		t.Fatalf("expected 5 blocks, got %d", resp.BlockCount)
+	}
+	if stats := chain.GetStats(); resp.BlockCount != stats.BlockCount {
+		t.Fatalf("count %d disagrees with GetStats %d", resp.BlockCount, stats.BlockCount)
+	}
+}
@@ -0,0 +1,386 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	"github.com/amanechibana/veritas-chain/identity"
+	"google.golang.org/grpc"
+)
+
+// Config holds the settings a Node is started with
+type Config struct {
+	Port int
+
+	// ListenAddr is the host:port to bind, e.g. 127.0.0.1:8080. When empty the
+	// node listens on Port on all interfaces.
+	ListenAddr string
+
+	// TLSCertFile and TLSKeyFile are PEM files; when both are set the node
+	// serves HTTPS. TLSSelfSigned serves HTTPS with a generated self-signed
+	// certificate instead, for local testing.
+	TLSCertFile   string
+	TLSKeyFile    string
+	TLSSelfSigned bool
+
+	// GRPCPort, when non-zero, also serves the gRPC API on this port, on the
+	// same host as the HTTP API.
+	GRPCPort int
+
+	// Signers maps university names to addresses, used to label
+	// per-university statistics and, with Leader set, to check the signer of
+	// every synced block. It may be nil on a node that does not follow.
+	Signers identity.AuthorizedSigners
+
+	// ReadOnly serves only read endpoints; write routes such as /add-block are
+	// not registered, for verifier-only deployments.
+	ReadOnly bool
+
+	// CertificateIDPolicy restricts the certificate IDs accepted by
+	// /add-block, /pending, /revoke, /supersede and their RPC and gRPC
+	// equivalents. A nil policy only rejects IDs that are empty or contain
+	// separators.
+	CertificateIDPolicy *blockchain.CertificateIDPolicy
+
+	// University is the name of the university this node signs for, reported
+	// by /status. It may be empty.
+	University string
+
+	// FlushInterval, when non-zero, writes certificates queued with
+	// POST /pending into a block this often. Pending certificates are held in
+	// memory and are dropped if the node stops before they are flushed.
+	FlushInterval time.Duration
+
+	// Peers are the base URLs of other nodes, e.g. http://10.0.0.2:8080.
+	// PeerCheckInterval, when non-zero, polls each peer's /health this often
+	// and reports the result at GET /peers.
+	Peers             []string
+	PeerCheckInterval time.Duration
+
+	// TraceLogger, when set, receives a log entry per HTTP request
+	TraceLogger *slog.Logger
+
+	// Leader, when set, makes the node a read replica of the node at this base
+	// URL: it pulls the leader's new blocks every FollowInterval and, as if
+	// ReadOnly, refuses writes.
+	Leader         string
+	FollowInterval time.Duration
+
+	// MaxRequestBodyBytes caps the body of every POST request but /import;
+	// larger bodies are refused with 413. Zero uses DefaultMaxRequestBodyBytes.
+	MaxRequestBodyBytes int64
+
+	// MaxImportBodyBytes caps the body of POST /import, which carries a whole
+	// chain. Zero uses DefaultMaxImportBodyBytes.
+	MaxImportBodyBytes int64
+}
+
+// Default POST body caps when MaxRequestBodyBytes and MaxImportBodyBytes are not set
+const (
+	DefaultMaxRequestBodyBytes = 10 << 20
+	DefaultMaxImportBodyBytes  = 4 << 30
+)
+
+// maxRequestBodyBytes returns MaxRequestBodyBytes, falling back to the default
+func (c Config) maxRequestBodyBytes() int64 {
+	if c.MaxRequestBodyBytes <= 0 {
+		return DefaultMaxRequestBodyBytes
+	}
+	return c.MaxRequestBodyBytes
+}
+
+// maxImportBodyBytes returns MaxImportBodyBytes, falling back to the default
+func (c Config) maxImportBodyBytes() int64 {
+	if c.MaxImportBodyBytes <= 0 {
+		return DefaultMaxImportBodyBytes
+	}
+	return c.MaxImportBodyBytes
+}
+
+// DefaultFollowInterval is how often a follower syncs when FollowInterval is not set
+const DefaultFollowInterval = 5 * time.Second
+
+// Node serves a blockchain over HTTP and, optionally, gRPC
+type Node struct {
+	config Config
+	chain  *blockchain.Blockchain
+	signer identity.Signer
+	server *http.Server
+
+	listener net.Listener
+
+	grpcServer   *grpc.Server
+	grpcListener net.Listener
+
+	// exports holds a slot per in-flight /export download
+	exports chan struct{}
+
+	mempool   *blockchain.Mempool
+	stopFlush func()
+
+	peers          *peerTracker
+	stopPeerChecks func()
+
+	stopFollowing func()
+
+	// mu guards stopping; writes tracks in-flight block writes so Stop can
+	// wait for them before closing the database.
+	mu       sync.Mutex
+	stopping bool
+	writes   sync.WaitGroup
+}
+
+// NewNode creates a node serving chain and signing new blocks with signer
+func NewNode(chain *blockchain.Blockchain, signer identity.Signer, config Config) *Node {
+	if config.Leader != "" {
+		config.ReadOnly = true
+	}
+	n := &Node{
+		config:  config,
+		chain:   chain,
+		signer:  signer,
+		exports: make(chan struct{}, maxConcurrentExports),
+		mempool: blockchain.NewMempool(chain),
+		peers:   newPeerTracker(config.Peers),
+	}
+	n.server = &http.Server{
+		Addr:    config.listenAddr(),
+		Handler: n.Handler(),
+	}
+	return n
+}
+
+// listenAddr returns ListenAddr, falling back to Port on all interfaces
+func (c Config) listenAddr() string {
+	if c.ListenAddr != "" {
+		return c.ListenAddr
+	}
+	return fmt.Sprintf(":%d", c.Port)
+}
+
+// ValidateListenAddr checks that addr is a host:port pair with a numeric port.
+// The host may be empty to listen on all interfaces.
+func ValidateListenAddr(addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid listen address %q: %v", addr, err)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("invalid listen address %q: port must be a number between 0 and 65535", addr)
+	}
+	if strings.ContainsAny(host, " /") {
+		return fmt.Errorf("invalid listen address %q: invalid host %q", addr, host)
+	}
+	return nil
+}
+
+// Handler returns the HTTP routes served by the node. Write routes are
+// omitted when the node is read-only, POST bodies are capped at
+// Config.MaxRequestBodyBytes, or Config.MaxImportBodyBytes for /import, and
+// requests are traced to Config.TraceLogger when one is set.
+func (n *Node) Handler() http.Handler {
+	mux := http.NewServeMux()
+	for _, rt := range n.routes() {
+		if rt.write && n.config.ReadOnly {
+			continue
+		}
+		handler := rt.handler
+		if rt.method == http.MethodPost {
+			limit := n.config.maxRequestBodyBytes()
+			if rt.path == "/import" {
+				limit = n.config.maxImportBodyBytes()
+			}
+			handler = limitRequestBody(limit, handler)
+		}
+		mux.Handle(rt.method+" "+rt.path, handler)
+	}
+	if n.config.TraceLogger != nil {
+		return traceRequests(n.config.TraceLogger, mux)
+	}
+	return mux
+}
+
+// Start listens and serves HTTP requests until the node is stopped
+func (n *Node) Start() error {
+	if err := n.Listen(); err != nil {
+		return err
+	}
+	return n.Serve()
+}
+
+// Listen binds the node's listen address without serving yet, so callers can
+// report the bound address (see Addr) before Serve blocks. TLS certificates
+// are loaded here so a bad certificate fails before the node starts serving.
+func (n *Node) Listen() error {
+	tlsConfig, err := n.config.tlsConfig()
+	if err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", n.server.Addr)
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		n.server.TLSConfig = tlsConfig
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+	n.listener = ln
+
+	if n.config.GRPCPort != 0 {
+		host, _, _ := net.SplitHostPort(n.server.Addr)
+		grpcLn, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(n.config.GRPCPort)))
+		if err != nil {
+			ln.Close()
+			return fmt.Errorf("failed to listen for gRPC: %v", err)
+		}
+		n.grpcListener = grpcLn
+		n.grpcServer = n.newGRPCServer()
+	}
+	return nil
+}
+
+// TLS reports whether the node serves HTTPS
+func (n *Node) TLS() bool {
+	return n.server.TLSConfig != nil
+}
+
+// Serve serves HTTP requests on the listener bound by Listen until the node is stopped
+func (n *Node) Serve() error {
+	if n.listener == nil {
+		return errors.New("node is not listening; call Listen first")
+	}
+	if n.config.FlushInterval > 0 && !n.config.ReadOnly {
+		n.mu.Lock()
+		n.stopFlush = n.mempool.StartAutoFlush(n.config.FlushInterval, n.signer, func(err error) {
+			log.Printf("auto-flush failed: %v", err)
+		})
+		n.mu.Unlock()
+	}
+	if n.config.PeerCheckInterval > 0 && len(n.config.Peers) > 0 {
+		n.mu.Lock()
+		n.stopPeerChecks = n.peers.start(n.config.PeerCheckInterval)
+		n.mu.Unlock()
+	}
+	if n.config.Leader != "" {
+		interval := n.config.FollowInterval
+		if interval <= 0 {
+			interval = DefaultFollowInterval
+		}
+		f := &follower{
+			chain:   n.chain,
+			source:  &HTTPBlockSource{BaseURL: n.config.Leader, Client: &http.Client{Timeout: 30 * time.Second}},
+			signers: n.config.Signers,
+		}
+		n.mu.Lock()
+		n.stopFollowing = f.start(interval)
+		n.mu.Unlock()
+	}
+
+	errs := make(chan error, 2)
+	if n.grpcServer != nil {
+		go func() { errs <- n.grpcServer.Serve(n.grpcListener) }()
+	}
+	go func() {
+		err := n.server.Serve(n.listener)
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		errs <- err
+	}()
+	return <-errs
+}
+
+// Addr returns the address the node is bound to, or nil before Listen
+func (n *Node) Addr() net.Addr {
+	if n.listener == nil {
+		return nil
+	}
+	return n.listener.Addr()
+}
+
+// GRPCAddr returns the address the gRPC API is bound to, or nil when it is not served
+func (n *Node) GRPCAddr() net.Addr {
+	if n.grpcListener == nil {
+		return nil
+	}
+	return n.grpcListener.Addr()
+}
+
+// Stop shuts down the HTTP server, waits for in-flight block writes to finish
+// and closes the database. If ctx expires first the database is left open
+// rather than closed underneath a writer.
+func (n *Node) Stop(ctx context.Context) error {
+	n.mu.Lock()
+	n.stopping = true
+	stopFlush := n.stopFlush
+	stopPeerChecks := n.stopPeerChecks
+	stopFollowing := n.stopFollowing
+	n.mu.Unlock()
+
+	if stopFlush != nil {
+		stopFlush()
+	}
+	if stopPeerChecks != nil {
+		stopPeerChecks()
+	}
+	if stopFollowing != nil {
+		stopFollowing()
+	}
+
+	if err := n.server.Shutdown(ctx); err != nil {
+		return err
+	}
+	if n.listener != nil {
+		// Shutdown only closes listeners passed to Serve; this covers Listen without Serve
+		_ = n.listener.Close()
+	}
+	if n.grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			n.grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			n.grpcServer.Stop()
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		n.writes.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for in-flight block writes: %v", ctx.Err())
+	}
+
+	return n.chain.Close()
+}
+
+// beginWrite registers an in-flight block write, refusing it once Stop has begun.
+// Callers must call n.writes.Done when the write completes.
+func (n *Node) beginWrite() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.stopping {
+		return false
+	}
+	n.writes.Add(1)
+	return true
+}
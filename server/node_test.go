@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+// slowSigner delays every signature so a block write stays in flight
+type slowSigner struct {
+	identity.Signer
+	delay   time.Duration
+	started chan struct{}
+	once    sync.Once
+}
+
+func (s *slowSigner) Sign(message []byte) ([]byte, error) {
+	s.once.Do(func() { close(s.started) })
+	time.Sleep(s.delay)
+	return s.Signer.Sign(message)
+}
+
+func TestStopWaitsForInFlightAddBlock(t *testing.T) {
+	dbPath := t.TempDir()
+	base := identity.NewIdentitySigner(identity.MakeIdentity())
+	chain := blockchain.InitBlockchain(dbPath, base, blockchain.DefaultBlockchainOptions())
+
+	signer := &slowSigner{Signer: base, delay: 300 * time.Millisecond, started: make(chan struct{})}
+	node := NewNode(chain, signer, Config{})
+	handler := node.Handler()
+
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		req := httptest.NewRequest(http.MethodPost, "/add-block", strings.NewReader(`{"certificates":["CERT-SLOW"]}`))
+		handler.ServeHTTP(rec, req)
+	}()
+
+	<-signer.started
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := node.Stop(ctx); err != nil {
+		t.Fatalf("stop failed: %v", err)
+	}
+	<-done
+
+	// Writes arriving after shutdown began are refused
+	late := httptest.NewRecorder()
+	handler.ServeHTTP(late, httptest.NewRequest(http.MethodPost, "/add-block", strings.NewReader(`{"certificates":["CERT-LATE"]}`)))
+	if late.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 for write after shutdown, got %d", late.Code)
+	}
+
+	reopened := blockchain.ContinueBlockchain(dbPath, blockchain.DefaultBlockchainOptions())
+	defer reopened.Close()
+
+	tip, err := reopened.Tip()
+	if err != nil {
+		t.Fatalf("load tip: %v", err)
+	}
+	switch rec.Code {
+	case http.StatusCreated:
+		if tip.Height != 1 || !tip.VerifyCertificate("CERT-SLOW") {
+			t.Fatalf("in-flight block reported committed but tip is height %d", tip.Height)
+		}
+	case http.StatusServiceUnavailable:
+		if tip.Height != 0 {
+			t.Fatalf("in-flight block reported aborted but tip is height %d", tip.Height)
+		}
+	default:
+		t.Fatalf("unexpected add-block status %d: %s", rec.Code, rec.Body.String())
+	}
+	if err := reopened.ValidateChain(); err != nil {
+		t.Fatalf("chain invalid after shutdown: %v", err)
+	}
+}
+
+func TestNodeListensOnConfiguredAddress(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	chain := blockchain.InitBlockchain("", signer, testChainOptions)
+	node := NewNode(chain, signer, Config{Port: 1, ListenAddr: "127.0.0.1:0"})
+
+	if err := node.Listen(); err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- node.Serve() }()
+
+	addr := node.Addr().(*net.TCPAddr)
+	if !addr.IP.IsLoopback() || addr.Port == 0 {
+		t.Fatalf("expected a loopback address with an assigned port, got %s", addr)
+	}
+
+	resp, err := http.Get("http://" + addr.String() + "/health")
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := node.Stop(ctx); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("serve: %v", err)
+	}
+}
+
+func TestValidateListenAddr(t *testing.T) {
+	for addr, valid := range map[string]bool{
+		"127.0.0.1:8080":  true,
+		":8080":           true,
+		"[::1]:0":         true,
+		"localhost:80":    true,
+		"127.0.0.1":       false,
+		"127.0.0.1:http":  false,
+		"127.0.0.1:70000": false,
+		"":                false,
+	} {
+		if err := ValidateListenAddr(addr); (err == nil) != valid {
+			t.Errorf("%q: expected valid=%v, got %v", addr, valid, err)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// openAPIVersion is the version of the API described by /openapi.json
+const openAPIVersion = "1"
+
+// handleOpenAPI serves an OpenAPI 3 description of the routes this node
+// serves; a read-only node leaves out the write routes
+func (n *Node) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, n.openAPISpec())
+}
+
+// openAPISpec builds the OpenAPI document from the route table, deriving the
+// request and response schemas from the Go types the handlers encode
+func (n *Node) openAPISpec() map[string]interface{} {
+	schemas := openAPISchemas{}
+	errorSchema := schemas.schemaFor(reflect.TypeOf(errorResponse{}))
+
+	paths := map[string]interface{}{}
+	for _, rt := range n.routes() {
+		if rt.write && n.config.ReadOnly {
+			continue
+		}
+		operation := map[string]interface{}{
+			"summary": rt.summary,
+			"responses": map[string]interface{}{
+				strconv.Itoa(rt.successStatus()): schemas.response(rt),
+				"default": map[string]interface{}{
+					"description": "Error",
+					"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": errorSchema}},
+				},
+			},
+		}
+		if len(rt.params) > 0 {
+			params := make([]map[string]interface{}, 0, len(rt.params))
+			for _, p := range rt.params {
+				params = append(params, map[string]interface{}{
+					"name":        p.name,
+					"in":          "query",
+					"required":    p.required,
+					"description": p.description,
+					"schema":      map[string]interface{}{"type": p.kind},
+				})
+			}
+			operation["parameters"] = params
+		}
+		if rt.request != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": schemas.schemaFor(reflect.TypeOf(rt.request))},
+				},
+			}
+		}
+
+		item, _ := paths[rt.path].(map[string]interface{})
+		if item == nil {
+			item = map[string]interface{}{}
+			paths[rt.path] = item
+		}
+		item[strings.ToLower(rt.method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Veritas Chain node API",
+			"version":     openAPIVersion,
+			"description": "Errors are returned as a JSON object with an error message.",
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": schemas},
+	}
+}
+
+func (rt route) successStatus() int {
+	if rt.status == 0 {
+		return http.StatusOK
+	}
+	return rt.status
+}
+
+// openAPISchemas collects the component schemas referenced by the document,
+// keyed by Go type name
+type openAPISchemas map[string]interface{}
+
+// response describes a route's success response
+func (s openAPISchemas) response(rt route) map[string]interface{} {
+	resp := map[string]interface{}{"description": http.StatusText(rt.successStatus())}
+	contentType := rt.contentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	media := map[string]interface{}{}
+	if rt.response != nil {
+		media["schema"] = s.schemaFor(reflect.TypeOf(rt.response))
+	}
+	resp["content"] = map[string]interface{}{contentType: media}
+	return resp
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// schemaFor returns the schema of t as encoding/json encodes it. Named structs
+// are added to the components and referenced.
+func (s openAPISchemas) schemaFor(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == rawMessageType, t.Kind() == reflect.Interface:
+		return map[string]interface{}{}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return map[string]interface{}{"type": "string", "format": "byte"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": s.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": s.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.structSchema(t)
+		}
+		if _, ok := s[t.Name()]; !ok {
+			s[t.Name()] = map[string]interface{}{} // placeholder in case the type refers to itself
+			s[t.Name()] = s.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema describes a struct's JSON object. Fields tagged omitempty are
+// optional, and the fields of embedded structs are promoted.
+func (s openAPISchemas) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, options, _ := strings.Cut(tag, ",")
+			if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+				addFields(field.Type)
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = s.schemaFor(field.Type)
+			if !strings.Contains(options, "omitempty") {
+				required = append(required, name)
+			}
+		}
+	}
+	addFields(t)
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
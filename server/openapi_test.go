@@ -0,0 +1,75 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// openAPIDocument is the part of the spec the tests inspect
+type openAPIDocument struct {
+	OpenAPI    string                                `json:"openapi"`
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]json.RawMessage `json:"schemas"`
+	} `json:"components"`
+}
+
+func fetchOpenAPI(t *testing.T, node *Node) openAPIDocument {
+	t.Helper()
+	rec := doRequest(t, node, http.MethodGet, "/openapi.json", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var doc openAPIDocument
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("spec is not valid JSON: %v", err)
+	}
+	return doc
+}
+
+func TestOpenAPIListsRoutes(t *testing.T) {
+	node, _, _ := newTestNode(t)
+	doc := fetchOpenAPI(t, node)
+	if doc.OpenAPI == "" {
+		t.Fatal("spec has no openapi version")
+	}
+
+	for path, method := range map[string]string{
+		"/status":       "get",
+		"/blocks":       "get",
+		"/cert-status":  "get",
+		"/add-block":    "post",
+		"/revoke":       "post",
+		"/rpc":          "post",
+		"/openapi.json": "get",
+	} {
+		if _, ok := doc.Paths[path][method]; !ok {
+			t.Errorf("spec has no %s %s", method, path)
+		}
+	}
+	for _, schema := range []string{"errorResponse", "statusResponse", "BlockSummaryDTO", "CertificateStatus"} {
+		if _, ok := doc.Components.Schemas[schema]; !ok {
+			t.Errorf("spec has no %s schema", schema)
+		}
+	}
+
+	// Every documented route is served
+	for _, rt := range node.routes() {
+		rec := doRequest(t, node, rt.method, rt.path, "")
+		if rec.Code == http.StatusNotFound || rec.Code == http.StatusMethodNotAllowed {
+			t.Errorf("%s %s is documented but not routed: %d", rt.method, rt.path, rec.Code)
+		}
+	}
+}
+
+func TestOpenAPIReadOnlyOmitsWriteRoutes(t *testing.T) {
+	_, chain, signer := newTestNode(t)
+	doc := fetchOpenAPI(t, NewNode(chain, signer, Config{ReadOnly: true}))
+	if _, ok := doc.Paths["/add-block"]; ok {
+		t.Fatal("read-only spec documents /add-block")
+	}
+	if _, ok := doc.Paths["/status"]; !ok {
+		t.Fatal("read-only spec is missing /status")
+	}
+}
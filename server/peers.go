@@ -0,0 +1,147 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// peerCheckTimeout bounds a single /health request to a peer
+const peerCheckTimeout = 5 * time.Second
+
+// PeerStatus is the outcome of the most recent health check of a peer
+type PeerStatus struct {
+	URL string `json:"url"`
+	Up  bool   `json:"up"`
+	// LastChecked is nil until the first check of the peer completes
+	LastChecked *time.Time `json:"last_checked,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// peerTracker polls the /health endpoint of each configured peer and records
+// whether it answered. Peers start out down until their first check succeeds.
+type peerTracker struct {
+	client *http.Client
+
+	mu     sync.RWMutex
+	status map[string]PeerStatus
+}
+
+func newPeerTracker(peers []string) *peerTracker {
+	t := &peerTracker{
+		client: &http.Client{Timeout: peerCheckTimeout},
+		status: make(map[string]PeerStatus, len(peers)),
+	}
+	for _, peer := range peers {
+		peer = strings.TrimSuffix(peer, "/")
+		t.status[peer] = PeerStatus{URL: peer}
+	}
+	return t
+}
+
+// checkAll checks every peer concurrently and waits for the results
+func (t *peerTracker) checkAll() {
+	var wg sync.WaitGroup
+	for _, peer := range t.peers() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checked := time.Now().UTC()
+			status := PeerStatus{URL: peer, LastChecked: &checked}
+			if err := t.check(peer); err != nil {
+				status.Error = err.Error()
+			} else {
+				status.Up = true
+			}
+			t.mu.Lock()
+			t.status[peer] = status
+			t.mu.Unlock()
+		}()
+	}
+	wg.Wait()
+}
+
+// check requests peer's /health, treating anything but a 200 as down
+func (t *peerTracker) check(peer string) error {
+	resp, err := t.client.Get(peer + "/health")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health check returned %s", resp.Status)
+	}
+	return nil
+}
+
+// start checks the peers immediately and then every interval in the
+// background. The returned function stops polling and waits for an
+// in-progress round; it may be called more than once.
+func (t *peerTracker) start(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		t.checkAll()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.checkAll()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-exited
+	}
+}
+
+// peers returns the configured peer URLs, sorted
+func (t *peerTracker) peers() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	peers := make([]string, 0, len(t.status))
+	for peer := range t.status {
+		peers = append(peers, peer)
+	}
+	sort.Strings(peers)
+	return peers
+}
+
+// statuses returns the last known status of every peer, sorted by URL
+func (t *peerTracker) statuses() []PeerStatus {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	statuses := make([]PeerStatus, 0, len(t.status))
+	for _, status := range t.status {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].URL < statuses[j].URL })
+	return statuses
+}
+
+// LivePeers returns the peers whose last health check succeeded, sorted.
+// Anything sending to peers, such as block gossip, should skip the others.
+func (n *Node) LivePeers() []string {
+	var live []string
+	for _, status := range n.peers.statuses() {
+		if status.Up {
+			live = append(live, status.URL)
+		}
+	}
+	return live
+}
+
+// handlePeers reports the up/down status of each configured peer
+func (n *Node) handlePeers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string][]PeerStatus{"peers": n.peers.statuses()})
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPeerHealthStatus(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	_, chain, signer := newTestNode(t)
+	node := NewNode(chain, signer, Config{Peers: []string{healthy.URL, dead.URL + "/"}})
+	if rec := doRequest(t, node, http.MethodGet, "/peers", ""); strings.Contains(rec.Body.String(), "last_checked") {
+		t.Fatalf("expected unchecked peers to omit last_checked, got %s", rec.Body.String())
+	}
+	node.peers.checkAll()
+
+	rec := doRequest(t, node, http.MethodGet, "/peers", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var resp map[string][]PeerStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	status := make(map[string]bool)
+	for _, peer := range resp["peers"] {
+		if peer.LastChecked == nil || peer.LastChecked.IsZero() {
+			t.Fatalf("expected %s to have been checked", peer.URL)
+		}
+		status[peer.URL] = peer.Up
+	}
+	want := map[string]bool{healthy.URL: true, dead.URL: false}
+	if len(status) != len(want) || status[healthy.URL] != true || status[dead.URL] != false {
+		t.Fatalf("expected peer status %v, got %v", want, status)
+	}
+
+	if live := node.LivePeers(); !slices.Equal(live, []string{healthy.URL}) {
+		t.Fatalf("expected only the healthy peer to be live, got %v", live)
+	}
+}
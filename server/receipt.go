@@ -0,0 +1,94 @@
+package server
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+// receiptDomainTag separates receipt signatures from block, revocation and status signatures
+var receiptDomainTag = []byte("veritas-receipt-v1")
+
+// IssuanceReceipt is the node's signature over a certificate's acceptance
+// into a block, which the submitter can keep as proof that the certificate
+// was recorded at BlockHeight. Timestamp is the block's timestamp. Binary
+// fields are hex-encoded.
+type IssuanceReceipt struct {
+	CertificateHash string `json:"certificate_hash"`
+	BlockHeight     int    `json:"block_height"`
+	BlockHash       string `json:"block_hash"`
+	Timestamp       int64  `json:"timestamp"`
+	Address         string `json:"address"`
+	PublicKey       string `json:"public_key"`
+	Signature       string `json:"signature"`
+}
+
+// issueReceipts signs a receipt for every certificate recorded in block
+func (n *Node) issueReceipts(block *blockchain.Block) ([]IssuanceReceipt, error) {
+	receipts := make([]IssuanceReceipt, 0, len(block.CertificateHashes))
+	for _, certHash := range block.CertificateHashes {
+		receipt := IssuanceReceipt{
+			CertificateHash: certHash,
+			BlockHeight:     block.Height,
+			BlockHash:       hex.EncodeToString(block.Hash),
+			Timestamp:       block.Timestamp,
+			Address:         string(n.signer.Address()),
+			PublicKey:       hex.EncodeToString(identity.EncodePublicKey(n.signer.PublicKey())),
+		}
+		digest, err := receipt.digest()
+		if err != nil {
+			return nil, err
+		}
+		sig, err := n.signer.Sign(digest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign receipt: %v", err)
+		}
+		receipt.Signature = hex.EncodeToString(sig)
+		receipts = append(receipts, receipt)
+	}
+	return receipts, nil
+}
+
+// digest returns the hash signed by the node
+func (r *IssuanceReceipt) digest() ([]byte, error) {
+	blockHash, err := hex.DecodeString(r.BlockHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block hash: %v", err)
+	}
+	data := bytes.Join([][]byte{
+		receiptDomainTag,
+		[]byte(r.CertificateHash),
+		blockchain.ToHex(int64(r.BlockHeight)),
+		blockHash,
+		blockchain.ToHex(r.Timestamp),
+		[]byte(r.Address),
+	}, []byte{})
+	hash := sha256.Sum256(data)
+	return hash[:], nil
+}
+
+// Verify checks that the receipt was signed by trusted, the public key the
+// client expects the node to hold. The embedded PublicKey is informational and
+// is not trusted on its own.
+func (r *IssuanceReceipt) Verify(trusted ecdsa.PublicKey) error {
+	if address := string(identity.AddressFromPublicKey(trusted)); address != r.Address {
+		return fmt.Errorf("receipt signed by %s, expected %s", r.Address, address)
+	}
+	digest, err := r.digest()
+	if err != nil {
+		return err
+	}
+	sig, err := hex.DecodeString(r.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %v", err)
+	}
+	if !identity.VerifySignature(trusted, digest, sig) {
+		return fmt.Errorf("invalid receipt signature")
+	}
+	return nil
+}
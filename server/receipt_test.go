@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+func TestAddBlockReceiptVerifiesAgainstNodeKey(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+
+	rec := doRequest(t, node, http.MethodPost, "/add-block", `{"certificates":["CERT-001"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if resp := decodeAddBlock(t, rec.Body.Bytes()); resp.Receipts != nil {
+		t.Fatal("receipts returned without ?receipt=true")
+	}
+
+	rec = doRequest(t, node, http.MethodPost, "/add-block?receipt=true", `{"certificates":["CERT-002","CERT-003"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	resp := decodeAddBlock(t, rec.Body.Bytes())
+	if len(resp.Receipts) != 2 {
+		t.Fatalf("expected a receipt per certificate, got %d", len(resp.Receipts))
+	}
+
+	block, err := chain.GetBlockByHeight(2)
+	if err != nil {
+		t.Fatalf("GetBlockByHeight: %v", err)
+	}
+	for i, receipt := range resp.Receipts {
+		if err := receipt.Verify(signer.PublicKey()); err != nil {
+			t.Fatalf("receipt %d failed to verify: %v", i, err)
+		}
+		if receipt.BlockHeight != 2 || receipt.BlockHash != hex.EncodeToString(block.Hash) || receipt.Timestamp != block.Timestamp {
+			t.Fatalf("receipt %d references block %s at %d, expected %x at 2", i, receipt.BlockHash, receipt.BlockHeight, block.Hash)
+		}
+		if receipt.CertificateHash != block.CertificateHashes[i] {
+			t.Fatalf("receipt %d is for %s, expected %s", i, receipt.CertificateHash, block.CertificateHashes[i])
+		}
+	}
+
+	tampered := resp.Receipts[0]
+	tampered.BlockHeight = 1
+	if err := tampered.Verify(signer.PublicKey()); err == nil {
+		t.Fatal("tampered receipt verified")
+	}
+	other := identity.NewIdentitySigner(identity.MakeIdentity())
+	if err := resp.Receipts[0].Verify(other.PublicKey()); err == nil {
+		t.Fatal("receipt verified against the wrong key")
+	}
+}
+
+func decodeAddBlock(t *testing.T, body []byte) addBlockResponse {
+	t.Helper()
+	var resp addBlockResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("decode add-block response: %v", err)
+	}
+	return resp
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	"github.com/amanechibana/veritas-chain/metrics"
+)
+
+// route is an HTTP endpoint of the node, with the description /openapi.json
+// publishes for it
+type route struct {
+	method  string
+	path    string
+	handler http.Handler
+	// write routes are not registered on a read-only node
+	write   bool
+	summary string
+	params  []routeParam
+	// request and response are zero values of the JSON body types, nil when
+	// there is no JSON body
+	request  interface{}
+	response interface{}
+	// status is the success status, 200 when zero
+	status int
+	// contentType is the success content type, application/json when empty
+	contentType string
+}
+
+// routeParam is a query parameter of a route
+type routeParam struct {
+	name        string
+	kind        string // OpenAPI type: string, integer or boolean
+	required    bool
+	description string
+}
+
+// routes lists every endpoint the node serves, in the order they are documented
+func (n *Node) routes() []route {
+	heightRange := []routeParam{
+		{name: "from", kind: "integer", description: "First height, 0 when omitted"},
+		{name: "to", kind: "integer", description: "Last height, inclusive; clamped to the per-request limit"},
+	}
+	address := []routeParam{{name: "address", kind: "string", required: true, description: "University address"}}
+
+	return []route{
+		{method: "GET", path: "/health", handler: http.HandlerFunc(n.handleHealth),
+			summary: "Report that the node is up", response: map[string]string{}},
+		{method: "GET", path: "/status", handler: http.HandlerFunc(n.handleStatus),
+			summary:  "Validate the chain and report its size",
+			params:   []routeParam{{name: "signed", kind: "boolean", description: "Include a status attestation signed by the node"}},
+			response: statusResponse{}},
+		{method: "GET", path: "/count", handler: http.HandlerFunc(n.handleCount),
+			summary: "Count blocks from the tip height", response: countResponse{}},
+		{method: "GET", path: "/stats", handler: http.HandlerFunc(n.handleStats),
+			summary: "Get the chain's height and the timestamps of its first and latest blocks", response: blockchain.ChainInfo{}},
+		{method: "GET", path: "/stats/by-university", handler: http.HandlerFunc(n.handleStatsByUniversity),
+			summary: "Count blocks and certificates per university", response: []universityStats{}},
+		{method: "GET", path: "/chain-id", handler: http.HandlerFunc(n.handleChainID),
+			summary: "Identify the chain by its genesis block", response: map[string]string{}},
+		{method: "GET", path: "/node-info", handler: http.HandlerFunc(n.handleNodeInfo),
+			summary: "Get the university, address and public key the node signs with", response: nodeInfoResponse{}},
+		{method: "GET", path: "/blocks", handler: http.HandlerFunc(n.handleBlocks),
+			summary: "List blocks, newest first",
+			params: []routeParam{
+				{name: "since", kind: "integer", description: "Only blocks at or after this Unix timestamp"},
+				{name: "until", kind: "integer", description: "Only blocks at or before this Unix timestamp"},
+				{name: "label", kind: "string", description: "Only blocks carrying this label, as key:value"},
+			},
+			response: []BlockSummaryDTO{}},
+		{method: "GET", path: "/block/latest", handler: http.HandlerFunc(n.handleLatestBlock),
+			summary: "Get the header of the chain tip", response: blockchain.BlockHeader{}},
+		{method: "GET", path: "/block/raw", handler: http.HandlerFunc(n.handleRawBlock),
+			summary:     "Get a block's serialized bytes, as read by blockchain.Deserialize",
+			params:      []routeParam{{name: "hash", kind: "string", required: true, description: "Hex-encoded block hash"}},
+			contentType: "application/octet-stream"},
+		{method: "GET", path: "/accumulator-root", handler: http.HandlerFunc(n.handleAccumulatorRoot),
+			summary: "Get the root of the block-hash accumulator", response: accumulatorResponse{}},
+		{method: "GET", path: "/headers/range", handler: http.HandlerFunc(n.handleHeadersRange),
+			summary: "Get block headers by height", params: heightRange, response: []blockchain.BlockHeader{}},
+		{method: "GET", path: "/blocks/range", handler: http.HandlerFunc(n.handleBlocksRange),
+			summary: "Get full blocks by height", params: heightRange, response: []blockchain.Block{}},
+		{method: "GET", path: "/issued", handler: http.HandlerFunc(n.handleIssued),
+			summary: "List the certificates issued by a university", params: address, response: issuedResponse{}},
+		{method: "POST", path: "/blocks/by-signer", handler: http.HandlerFunc(n.handleBlocksBySigner),
+			summary: "List the blocks signed by a public key", request: blocksBySignerRequest{}, response: blocksBySignerResponse{}},
+		{method: "POST", path: "/verify-proof", handler: http.HandlerFunc(n.handleVerifyProof),
+			summary: "Check a certificate's Merkle proof against the block at a height", request: verifyProofRequest{}, response: verifyProofResponse{}},
+		{method: "GET", path: "/is-authorized", handler: http.HandlerFunc(n.handleIsAuthorized),
+			summary: "Check an address against the authorized signer registry", params: address, response: authorizedResponse{}},
+		{method: "GET", path: "/cert-status", handler: http.HandlerFunc(n.handleCertStatus),
+			summary:  "Get the status of a certificate",
+			params:   []routeParam{{name: "id", kind: "string", required: true, description: "Certificate ID"}},
+			response: blockchain.CertificateStatus{}},
+		{method: "GET", path: "/verify-cert", handler: http.HandlerFunc(n.handleVerifyCert),
+			summary: "Find the block recording a certificate, or with all=true every block recording it",
+			params: []routeParam{
+				{name: "id", kind: "string", required: true, description: "Certificate ID"},
+				{name: "all", kind: "boolean", description: "List every block recording the certificate, to detect duplicate issuance"},
+			},
+			response: verifyCertResponse{}},
+		{method: "GET", path: "/certificates", handler: http.HandlerFunc(n.handleCertificates),
+			summary: "Page through the distinct certificate hashes on the chain, oldest first",
+			params: []routeParam{
+				{name: "after", kind: "string", description: "The next cursor of the previous page, as height:index"},
+				{name: "limit", kind: "integer", description: "Page size"},
+			},
+			response: certificatesResponse{}},
+		{method: "GET", path: "/export", handler: http.HandlerFunc(n.handleExport),
+			summary:     "Download the chain, as NDJSON or with format=json as a JSON array",
+			params:      []routeParam{{name: "format", kind: "string", description: "ndjson (default) or json"}},
+			response:    blockchain.Block{},
+			contentType: "application/x-ndjson"},
+		{method: "GET", path: "/peers", handler: http.HandlerFunc(n.handlePeers),
+			summary: "Report the status of each configured peer", response: map[string][]PeerStatus{}},
+		{method: "POST", path: "/rpc", handler: http.HandlerFunc(n.handleRPC),
+			summary: "Call the JSON-RPC 2.0 API", request: rpcRequest{}, response: rpcResponse{}},
+		{method: "GET", path: "/metrics", handler: metrics.Handler(),
+			summary: "Prometheus metrics", contentType: "text/plain"},
+		{method: "GET", path: "/openapi.json", handler: http.HandlerFunc(n.handleOpenAPI),
+			summary: "This document", response: map[string]interface{}{}},
+
+		{method: "POST", path: "/add-block", handler: http.HandlerFunc(n.handleAddBlock), write: true,
+			summary:  "Write a block of certificates",
+			params:   []routeParam{{name: "receipt", kind: "boolean", description: "Include a signed receipt per certificate"}},
+			request:  addBlockRequest{},
+			response: addBlockResponse{}, status: http.StatusCreated},
+		{method: "POST", path: "/revoke", handler: http.HandlerFunc(n.handleRevoke), write: true,
+			summary: "Revoke a certificate", request: revokeRequest{}, response: blockchain.Revocation{}, status: http.StatusCreated},
+		{method: "POST", path: "/supersede", handler: http.HandlerFunc(n.handleSupersede), write: true,
+			summary: "Replace a certificate with a corrected one", request: supersedeRequest{}, response: blockchain.Supersession{}, status: http.StatusCreated},
+		{method: "POST", path: "/block/labels", handler: http.HandlerFunc(n.handleBlockLabels), write: true,
+			summary: "Replace a block's labels, which are not hashed or signed", request: blockLabelsRequest{}, response: BlockSummaryDTO{}},
+		{method: "POST", path: "/import", handler: http.HandlerFunc(n.handleImport), write: true,
+			summary:  "Import an NDJSON stream of blocks into an empty chain",
+			params:   []routeParam{{name: "force", kind: "boolean", description: "Replace a non-empty chain; needs an authorized signer registry"}},
+			response: importResponse{}},
+		{method: "POST", path: "/pending", handler: http.HandlerFunc(n.handlePending), write: true,
+			summary: "Queue certificates for the next block", request: addBlockRequest{}, response: pendingResponse{}, status: http.StatusAccepted},
+		{method: "POST", path: "/flush", handler: http.HandlerFunc(n.handleFlush), write: true,
+			summary: "Write the pending certificates as a block", response: BlockSummaryDTO{}, status: http.StatusCreated},
+	}
+}
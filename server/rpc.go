@@ -0,0 +1,325 @@
+package server
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+)
+
+// JSON-RPC 2.0 error codes. Codes from -32000 to -32099 are reserved for
+// implementation-defined server errors.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+	rpcServerError    = -32000
+)
+
+// maxRPCBatchSize limits how many calls a single batch request may contain
+const maxRPCBatchSize = 100
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+type rpcBlockParams struct {
+	Height *int   `json:"height"`
+	Hash   string `json:"hash"`
+}
+
+type rpcCertificateParams struct {
+	ID string `json:"id"`
+}
+
+type rpcVerifyResult struct {
+	Found       bool   `json:"found"`
+	BlockHeight int    `json:"block_height,omitempty"`
+	BlockHash   string `json:"block_hash,omitempty"`
+	IssuedAt    int64  `json:"issued_at,omitempty"`
+}
+
+type rpcProofResult struct {
+	BlockHeight int    `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+	MerkleRoot  string `json:"merkle_root"`
+
+	// Proof is in the format accepted by cert verify-offline
+	Proof blockchain.MerkleProof `json:"proof"`
+}
+
+// rpcMethod handles a call's params and returns its result. Returning an
+// *rpcError selects the error code; any other error is an internal error.
+type rpcMethod func(n *Node, params json.RawMessage) (interface{}, error)
+
+var rpcMethods = map[string]rpcMethod{
+	"getBlock":          (*Node).rpcGetBlock,
+	"getStatus":         (*Node).rpcGetStatus,
+	"addBlock":          (*Node).rpcAddBlock,
+	"verifyCertificate": (*Node).rpcVerifyCertificate,
+	"getProof":          (*Node).rpcGetProof,
+}
+
+// handleRPC serves JSON-RPC 2.0 calls, single or batched, on top of the same
+// chain logic as the REST routes. Notifications, calls without an id, are run
+// but get no response; a request made only of notifications returns 204.
+func (n *Node) handleRPC(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if tooLarge(err) {
+		writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	} else if err != nil {
+		writeJSON(w, http.StatusOK, rpcErrorResponse(nil, rpcParseError, fmt.Sprintf("failed to read request: %v", err)))
+		return
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] != '[' {
+		resp, ok := n.callRPC(body)
+		if !ok {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil {
+		writeJSON(w, http.StatusOK, rpcErrorResponse(nil, rpcParseError, fmt.Sprintf("invalid JSON: %v", err)))
+		return
+	}
+	if len(batch) == 0 {
+		writeJSON(w, http.StatusOK, rpcErrorResponse(nil, rpcInvalidRequest, "empty batch"))
+		return
+	}
+	if len(batch) > maxRPCBatchSize {
+		writeJSON(w, http.StatusOK, rpcErrorResponse(nil, rpcInvalidRequest, fmt.Sprintf("batch exceeds %d calls", maxRPCBatchSize)))
+		return
+	}
+
+	responses := make([]rpcResponse, 0, len(batch))
+	for _, call := range batch {
+		if resp, ok := n.callRPC(call); ok {
+			responses = append(responses, resp)
+		}
+	}
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, responses)
+}
+
+// callRPC runs a single call, reporting false when it was a notification and
+// must not be answered
+func (n *Node) callRPC(data []byte) (rpcResponse, bool) {
+	var req rpcRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) || len(data) == 0 {
+			return rpcErrorResponse(nil, rpcParseError, fmt.Sprintf("invalid JSON: %v", err)), true
+		}
+		return rpcErrorResponse(nil, rpcInvalidRequest, fmt.Sprintf("invalid request: %v", err)), true
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return rpcErrorResponse(req.ID, rpcInvalidRequest, `jsonrpc must be "2.0" and method is required`), true
+	}
+
+	method, found := rpcMethods[req.Method]
+	var (
+		result interface{}
+		err    error
+	)
+	if !found {
+		err = &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+	} else {
+		result, err = method(n, req.Params)
+	}
+	if req.ID == nil {
+		return rpcResponse{}, false
+	}
+
+	if err != nil {
+		var rpcErr *rpcError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &rpcError{Code: rpcInternalError, Message: err.Error()}
+		}
+		return rpcResponse{JSONRPC: "2.0", Error: rpcErr, ID: req.ID}, true
+	}
+	return rpcResponse{JSONRPC: "2.0", Result: result, ID: req.ID}, true
+}
+
+// rpcErrorResponse builds an error response; a nil id is sent as null
+func rpcErrorResponse(id json.RawMessage, code int, message string) rpcResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return rpcResponse{JSONRPC: "2.0", Error: &rpcError{Code: code, Message: message}, ID: id}
+}
+
+// decodeRPCParams decodes a call's by-name params into v, rejecting unknown fields
+func decodeRPCParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf("invalid params: %v", err)}
+	}
+	return nil
+}
+
+func invalidParams(format string, args ...interface{}) error {
+	return &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+func (n *Node) rpcGetBlock(params json.RawMessage) (interface{}, error) {
+	var p rpcBlockParams
+	if err := decodeRPCParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	var (
+		block *blockchain.Block
+		err   error
+	)
+	switch {
+	case p.Height != nil && p.Hash != "":
+		return nil, invalidParams("only one of height and hash may be given")
+	case p.Height != nil:
+		if *p.Height < 0 {
+			return nil, invalidParams("invalid height %d", *p.Height)
+		}
+		block, err = n.chain.GetBlockByHeight(*p.Height)
+	case p.Hash != "":
+		hash, decodeErr := hex.DecodeString(p.Hash)
+		if decodeErr != nil {
+			return nil, invalidParams("invalid hash: %v", decodeErr)
+		}
+		block, err = n.chain.GetBlockByHash(hash)
+	default:
+		return nil, invalidParams("a block hash or height is required")
+	}
+	if err != nil {
+		return nil, &rpcError{Code: rpcServerError, Message: fmt.Sprintf("block not found: %v", err)}
+	}
+	return blockSummary(block), nil
+}
+
+func (n *Node) rpcGetStatus(params json.RawMessage) (interface{}, error) {
+	if err := decodeRPCParams(params, &struct{}{}); err != nil {
+		return nil, err
+	}
+	resp, _, _ := n.chainStatus()
+	return resp, nil
+}
+
+func (n *Node) rpcAddBlock(params json.RawMessage) (interface{}, error) {
+	if n.config.ReadOnly {
+		return nil, &rpcError{Code: rpcServerError, Message: "node is read-only"}
+	}
+	var p addBlockRequest
+	if err := decodeRPCParams(params, &p); err != nil {
+		return nil, err
+	}
+	if len(p.Certificates) == 0 {
+		return nil, invalidParams("no certificates provided")
+	}
+	if err := n.config.CertificateIDPolicy.ValidateAll(p.Certificates); err != nil {
+		return nil, invalidParams("%v", err)
+	}
+
+	if !n.beginWrite() {
+		return nil, &rpcError{Code: rpcServerError, Message: "node is shutting down"}
+	}
+	defer n.writes.Done()
+
+	block, err := n.chain.AddBlock(p.Certificates, n.signer)
+	if errors.Is(err, blockchain.ErrBlockTooSoon) {
+		return nil, &rpcError{Code: rpcServerError, Message: err.Error()}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to add block: %v", err)
+	}
+	return blockSummary(block), nil
+}
+
+func (n *Node) rpcVerifyCertificate(params json.RawMessage) (interface{}, error) {
+	block, id, err := n.rpcFindCertificate(params)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return rpcVerifyResult{Found: false}, nil
+	}
+	issuedAt, _ := block.CertificateIssuedAt(id)
+	return rpcVerifyResult{
+		Found:       true,
+		BlockHeight: block.Height,
+		BlockHash:   hex.EncodeToString(block.Hash),
+		IssuedAt:    issuedAt,
+	}, nil
+}
+
+func (n *Node) rpcGetProof(params json.RawMessage) (interface{}, error) {
+	block, id, err := n.rpcFindCertificate(params)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, &rpcError{Code: rpcServerError, Message: fmt.Sprintf("certificate %q not found", id)}
+	}
+	proof, err := block.GenerateCertificateProof(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build proof for certificate %q: %v", id, err)
+	}
+	return rpcProofResult{
+		BlockHeight: block.Height,
+		BlockHash:   hex.EncodeToString(block.Hash),
+		MerkleRoot:  hex.EncodeToString(block.MerkleRoot),
+		Proof:       proof,
+	}, nil
+}
+
+// rpcFindCertificate decodes {"id": ...} params and looks up the block recording the certificate
+func (n *Node) rpcFindCertificate(params json.RawMessage) (*blockchain.Block, string, error) {
+	var p rpcCertificateParams
+	if err := decodeRPCParams(params, &p); err != nil {
+		return nil, "", err
+	}
+	if err := blockchain.ValidateCertificateID(p.ID); err != nil {
+		return nil, "", invalidParams("%v", err)
+	}
+	block, err := n.chain.FindCertificate(p.ID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to search chain: %v", err)
+	}
+	return block, p.ID, nil
+}
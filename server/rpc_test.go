@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// rpcCall is a decoded JSON-RPC response with the result left raw
+type rpcCall struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result"`
+	Error   *rpcError       `json:"error"`
+	ID      json.RawMessage `json:"id"`
+}
+
+func TestRPCSingleCall(t *testing.T) {
+	node, chain, _ := newTestNode(t)
+	if _, err := chain.AddBlock([]string{"CERT-RPC-1"}, node.signer); err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+
+	rec := doRequest(t, node, http.MethodPost, "/rpc", `{"jsonrpc":"2.0","method":"getBlock","params":{"height":1},"id":7}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp rpcCall
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.JSONRPC != "2.0" || string(resp.ID) != "7" || resp.Error != nil {
+		t.Fatalf("unexpected response envelope: %s", rec.Body.String())
+	}
+	var block BlockSummaryDTO
+	if err := json.Unmarshal(resp.Result, &block); err != nil {
+		t.Fatalf("decode block: %v", err)
+	}
+	if block.Height != 1 || len(block.CertificateHashes) != 1 {
+		t.Fatalf("expected block 1 with one certificate, got %+v", block)
+	}
+}
+
+func TestRPCBatch(t *testing.T) {
+	node, _, _ := newTestNode(t)
+
+	body := `[
+		{"jsonrpc":"2.0","method":"addBlock","params":{"certificates":["CERT-RPC-2"]},"id":1},
+		{"jsonrpc":"2.0","method":"verifyCertificate","params":{"id":"CERT-RPC-2"},"id":2},
+		{"jsonrpc":"2.0","method":"getProof","params":{"id":"CERT-RPC-2"},"id":3},
+		{"jsonrpc":"2.0","method":"getStatus"}
+	]`
+	rec := doRequest(t, node, http.MethodPost, "/rpc", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp []rpcCall
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	// the getStatus notification is not answered
+	if len(resp) != 3 {
+		t.Fatalf("expected 3 responses, got %d: %s", len(resp), rec.Body.String())
+	}
+	for i, call := range resp {
+		if call.Error != nil {
+			t.Fatalf("call %s failed: %+v", call.ID, call.Error)
+		}
+		if want := string(rune('1' + i)); string(call.ID) != want {
+			t.Fatalf("expected response %d to have id %s, got %s", i, want, call.ID)
+		}
+	}
+
+	var verified rpcVerifyResult
+	if err := json.Unmarshal(resp[1].Result, &verified); err != nil {
+		t.Fatalf("decode verifyCertificate result: %v", err)
+	}
+	if !verified.Found || verified.BlockHeight != 1 {
+		t.Fatalf("expected certificate found at height 1, got %+v", verified)
+	}
+	var proof rpcProofResult
+	if err := json.Unmarshal(resp[2].Result, &proof); err != nil {
+		t.Fatalf("decode getProof result: %v", err)
+	}
+	if proof.BlockHash != verified.BlockHash || proof.MerkleRoot == "" {
+		t.Fatalf("unexpected proof result: %+v", proof)
+	}
+}
+
+func TestRPCUnknownMethod(t *testing.T) {
+	node, _, _ := newTestNode(t)
+
+	rec := doRequest(t, node, http.MethodPost, "/rpc", `{"jsonrpc":"2.0","method":"mineBlock","id":"a"}`)
+	var resp rpcCall
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != rpcMethodNotFound {
+		t.Fatalf("expected method not found error, got %s", rec.Body.String())
+	}
+	if string(resp.ID) != `"a"` {
+		t.Fatalf("expected id to be echoed, got %s", resp.ID)
+	}
+}
+
+func TestRPCInvalidParams(t *testing.T) {
+	node, _, _ := newTestNode(t)
+
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","method":"getBlock","params":{"height":-1},"id":1}`,
+		`{"jsonrpc":"2.0","method":"getBlock","params":{"height":"one"},"id":1}`,
+		`{"jsonrpc":"2.0","method":"verifyCertificate","params":{"certificate":"CERT-1"},"id":1}`,
+		`{"jsonrpc":"2.0","method":"addBlock","params":{"certificates":[]},"id":1}`,
+	} {
+		rec := doRequest(t, node, http.MethodPost, "/rpc", body)
+		var resp rpcCall
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if resp.Error == nil || resp.Error.Code != rpcInvalidParams {
+			t.Fatalf("expected invalid params error for %s, got %s", body, rec.Body.String())
+		}
+	}
+}
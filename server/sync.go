@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+)
+
+// HTTPBlockSource reads blocks from another node's HTTP API, for
+// Blockchain.SyncFrom
+type HTTPBlockSource struct {
+	// BaseURL is the node's base URL, e.g. http://10.0.0.2:8080
+	BaseURL string
+	// Client makes the requests; nil uses http.DefaultClient
+	Client *http.Client
+}
+
+// TipHeight returns the height of the node's newest block, from /block/latest
+func (s *HTTPBlockSource) TipHeight(ctx context.Context) (int, error) {
+	var tip blockchain.BlockHeader
+	if err := s.getJSON(ctx, "/block/latest", &tip); err != nil {
+		return 0, err
+	}
+	return tip.Height, nil
+}
+
+// BlocksInRange fetches blocks from..to from /blocks/range; the node may
+// return fewer than asked for
+func (s *HTTPBlockSource) BlocksInRange(ctx context.Context, from, to int) ([]*blockchain.Block, error) {
+	var blocks []*blockchain.Block
+	if err := s.getJSON(ctx, fmt.Sprintf("/blocks/range?from=%d&to=%d", from, to), &blocks); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// getJSON fetches path from the node and decodes the JSON response into v
+func (s *HTTPBlockSource) getJSON(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(s.BaseURL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid response from GET %s: %v", path, err)
+	}
+	return nil
+}
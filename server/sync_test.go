@@ -0,0 +1,42 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+func TestSyncFromHTTPBlockSource(t *testing.T) {
+	node, chain, signer := newTestNode(t)
+	for _, id := range []string{"CERT-SYNC-1", "CERT-SYNC-2", "CERT-SYNC-3"} {
+		if _, err := chain.AddBlock([]string{id}, signer); err != nil {
+			t.Fatalf("AddBlock: %v", err)
+		}
+	}
+	peer := httptest.NewServer(node.Handler())
+	defer peer.Close()
+
+	local := blockchain.InitBlockchain("", identity.NewIdentitySigner(identity.MakeIdentity()), testChainOptions)
+	defer local.Close()
+	if err := local.Reset(); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+
+	source := &HTTPBlockSource{BaseURL: peer.URL, Client: peer.Client()}
+	imported, err := local.SyncFrom(context.Background(), source, identity.AuthorizedSigners{"uni": string(signer.Address())}, 2)
+	if err != nil {
+		t.Fatalf("SyncFrom: %v", err)
+	}
+	if imported != 4 {
+		t.Fatalf("expected 4 blocks imported, got %d", imported)
+	}
+	localTip, _ := local.Tip()
+	peerTip, _ := chain.Tip()
+	if !bytes.Equal(localTip.Hash, peerTip.Hash) {
+		t.Fatalf("local tip %x does not match peer tip %x", localTip.Hash, peerTip.Hash)
+	}
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/amanechibana/veritas-chain/blockchain"
+	"github.com/amanechibana/veritas-chain/identity"
+)
+
+func TestNodeServesHTTPS(t *testing.T) {
+	certPEM, keyPEM, err := GenerateSelfSignedCert([]string{"127.0.0.1"})
+	if err != nil {
+		t.Fatalf("generate cert: %v", err)
+	}
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	chain := blockchain.InitBlockchain("", signer, testChainOptions)
+	node := NewNode(chain, signer, Config{ListenAddr: "127.0.0.1:0", TLSCertFile: certFile, TLSKeyFile: keyFile})
+	if err := node.Listen(); err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go node.Serve()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		node.Stop(ctx)
+	}()
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPEM)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+
+	resp, err := client.Get("https://" + node.Addr().String() + "/health")
+	if err != nil {
+		t.Fatalf("https request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	// Clients that don't trust the certificate are refused
+	if _, err := http.Get("https://" + node.Addr().String() + "/health"); err == nil {
+		t.Fatal("expected an untrusted client to fail the TLS handshake")
+	}
+}
+
+func TestNodeRejectsBadTLSFiles(t *testing.T) {
+	signer := identity.NewIdentitySigner(identity.MakeIdentity())
+	chain := blockchain.InitBlockchain("", signer, testChainOptions)
+	defer chain.Close()
+
+	garbage := filepath.Join(t.TempDir(), "garbage.pem")
+	if err := os.WriteFile(garbage, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, config := range map[string]Config{
+		"missing files": {ListenAddr: "127.0.0.1:0", TLSCertFile: "/nonexistent/cert.pem", TLSKeyFile: "/nonexistent/key.pem"},
+		"invalid files": {ListenAddr: "127.0.0.1:0", TLSCertFile: garbage, TLSKeyFile: garbage},
+		"key missing":   {ListenAddr: "127.0.0.1:0", TLSCertFile: garbage},
+	} {
+		err := NewNode(chain, signer, config).Listen()
+		if err == nil || !strings.Contains(err.Error(), "TLS") {
+			t.Errorf("%s: expected a TLS error, got %v", name, err)
+		}
+	}
+}
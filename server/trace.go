@@ -0,0 +1,75 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// tracedResponse records what a handler sent, for the request trace
+type tracedResponse struct {
+	http.ResponseWriter
+	status int
+	// blockHeight is the height of the block the request wrote, or -1
+	blockHeight int
+}
+
+func (t *tracedResponse) WriteHeader(status int) {
+	if t.status == 0 {
+		t.status = status
+	}
+	t.ResponseWriter.WriteHeader(status)
+}
+
+func (t *tracedResponse) Write(p []byte) (int, error) {
+	if t.status == 0 {
+		t.status = http.StatusOK
+	}
+	return t.ResponseWriter.Write(p)
+}
+
+// Flush lets streamed responses such as /export reach the client as they are written
+func (t *tracedResponse) Flush() {
+	if f, ok := t.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController, so handlers
+// can still reach its deadlines and other optional interfaces
+func (t *tracedResponse) Unwrap() http.ResponseWriter {
+	return t.ResponseWriter
+}
+
+// traceRequests logs the method, path, status and duration of every request,
+// and the height of any block it wrote. Query strings, headers and bodies are
+// not logged, as they may carry certificate IDs.
+func traceRequests(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		traced := &tracedResponse{ResponseWriter: w, blockHeight: -1}
+		next.ServeHTTP(traced, r)
+
+		status := traced.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if traced.blockHeight >= 0 {
+			attrs = append(attrs, slog.Int("block_height", traced.blockHeight))
+		}
+		logger.LogAttrs(r.Context(), slog.LevelInfo, "request", attrs...)
+	})
+}
+
+// traceBlockHeight records the height of the block a request wrote in its trace
+func traceBlockHeight(w http.ResponseWriter, height int) {
+	if traced, ok := w.(*tracedResponse); ok {
+		traced.blockHeight = height
+	}
+}
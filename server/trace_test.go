@@ -0,0 +1,68 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTraceRequests(t *testing.T) {
+	_, chain, signer := newTestNode(t)
+	var logs bytes.Buffer
+	node := NewNode(chain, signer, Config{TraceLogger: slog.New(slog.NewJSONHandler(&logs, nil))})
+
+	rec := doRequest(t, node, http.MethodPost, "/add-block", `{"certificates":["CERT-SECRET-001"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := doRequest(t, node, http.MethodGet, "/cert-status?id=CERT-SECRET-001", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	if strings.Contains(logs.String(), "CERT-SECRET-001") {
+		t.Fatalf("trace leaked a certificate ID: %s", logs.String())
+	}
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("decode log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 log entries, got %d", len(entries))
+	}
+
+	add := entries[0]
+	if add["method"] != "POST" || add["path"] != "/add-block" || add["status"] != float64(http.StatusCreated) {
+		t.Fatalf("unexpected add-block entry %v", add)
+	}
+	if add["block_height"] != float64(1) {
+		t.Fatalf("expected block_height 1, got %v", add["block_height"])
+	}
+	if _, ok := add["duration"]; !ok {
+		t.Fatal("add-block entry has no duration")
+	}
+
+	status := entries[1]
+	if status["path"] != "/cert-status" || status["status"] != float64(http.StatusOK) {
+		t.Fatalf("unexpected cert-status entry %v", status)
+	}
+	if _, ok := status["block_height"]; ok {
+		t.Fatal("a read request should not log a block height")
+	}
+}
+
+func TestTracedResponseUnwraps(t *testing.T) {
+	rec := httptest.NewRecorder()
+	traced := &tracedResponse{ResponseWriter: rec, blockHeight: -1}
+	if traced.Unwrap() != http.ResponseWriter(rec) {
+		t.Fatal("Unwrap did not return the underlying writer")
+	}
+}